@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"errors"
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/app"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/jotaen/klog/klog/parser/reconciling"
+	"strconv"
+)
+
+type Amend struct {
+	Entry        string            `name:"entry" short:"e" placeholder:"INDEX" help:"The entry to amend: its position in the record (starting at 1), or 'last' (default)"`
+	Start        klog.Time         `name:"start" help:"Change the start time of the entry"`
+	End          klog.Time         `name:"end" help:"Change the end time of the entry"`
+	Duration     klog.Duration     `name:"duration" help:"Change the time value of the entry to a duration"`
+	Summary      klog.EntrySummary `name:"summary" short:"s" placeholder:"TEXT" help:"Replace the summary text of the entry"`
+	ClearSummary bool              `name:"clear-summary" help:"Remove the summary text of the entry"`
+	Delete       bool              `name:"delete" help:"Delete the entry"`
+	DeleteRecord bool              `name:"delete-record" help:"Delete the entire record"`
+	lib.AtDateArgs
+	lib.NoStyleArgs
+	lib.OutputFileArgs
+	lib.WarnArgs
+	lib.DryRunArgs
+}
+
+func (opt *Amend) Help() string {
+	return `It changes an existing entry of a record. The record is addressed by its date (today by default), and the entry by its position within the record (the last one by default).
+The start and end time are written in the prevalent time format of the file, or in the one from the config, if specified.
+
+Examples:
+    klog amend --date 2020-01-01 --entry 2 --start 9:30     (Change the start time of the 2nd entry)
+    klog amend --end 17:00 --summary 'Meeting #work'        (Change the end time and the summary of the last entry)
+    klog amend --duration 2h --clear-summary                (Change the last entry to '2h' without summary)
+    klog amend --yesterday --entry 1 --delete               (Delete the 1st entry of yesterday’s record)
+    klog amend --date 2020-01-01 --delete-record            (Delete the entire record)
+
+All other lines of the file are left untouched.`
+}
+
+func (opt *Amend) Run(ctx app.Context) app.Error {
+	opt.NoStyleArgs.Apply(&ctx)
+	date := opt.AtDate(ctx.Now())
+	if opt.ClearSummary {
+		if opt.Summary != nil {
+			return app.NewErrorWithCode(
+				app.LOGICAL_ERROR,
+				"Conflicting flags",
+				"--summary and --clear-summary cannot be used at the same time",
+				nil,
+			)
+		}
+		opt.Summary = klog.EntrySummary{""}
+	}
+	hasChange := opt.Start != nil || opt.End != nil || opt.Duration != nil || opt.Summary != nil
+	if (opt.DeleteRecord && (opt.Delete || hasChange)) || (opt.Delete && hasChange) {
+		return app.NewErrorWithCode(
+			app.LOGICAL_ERROR,
+			"Conflicting flags",
+			"Deleting cannot be combined with other changes",
+			nil,
+		)
+	}
+	if opt.DeleteRecord && opt.Entry != "" {
+		return app.NewErrorWithCode(
+			app.LOGICAL_ERROR,
+			"Conflicting flags",
+			"--entry cannot be used with --delete-record",
+			nil,
+		)
+	}
+	if !opt.DeleteRecord && !opt.Delete && !hasChange {
+		return app.NewErrorWithCode(
+			app.LOGICAL_ERROR,
+			"Nothing to amend",
+			"Please specify a change, e.g. via --start, --end, --duration, --summary or --delete",
+			nil,
+		)
+	}
+	entryIndex, eErr := opt.entryIndex()
+	if eErr != nil {
+		return eErr
+	}
+	creators := []reconciling.Creator{
+		reconciling.NewReconcilerAtRecord(date),
+	}
+
+	if opt.DeleteRecord {
+		result, err := ctx.ReconcileFile(opt.File, creators, func(reconciler *reconciling.Reconciler) (*reconciling.Result, error) {
+			return reconciler.DeleteRecord()
+		}, !opt.DryRun, false)
+		if err != nil {
+			return err
+		}
+		if opt.DryRun {
+			lib.PrintDryRun(ctx, opt.File, result)
+			return nil
+		}
+		ctx.Print("Deleted the record of " + result.Record.Date().ToString() + "\n")
+		opt.WarnArgs.PrintWarnings(ctx, result.AllRecords, nil)
+		return nil
+	}
+
+	return lib.Reconcile(ctx, lib.ReconcileOpts{OutputFileArgs: opt.OutputFileArgs, WarnArgs: opt.WarnArgs, DryRunArgs: opt.DryRunArgs},
+		creators,
+		func(reconciler *reconciling.Reconciler) (*reconciling.Result, error) {
+			i := entryIndex
+			if i == -1 {
+				i = len(reconciler.Record.Entries()) - 1
+			}
+			if i == -1 {
+				return nil, errors.New("The record has no entries")
+			}
+			if i >= len(reconciler.Record.Entries()) {
+				return nil, errors.New("The record has no entry at position " + opt.Entry)
+			}
+			if opt.Delete {
+				return reconciler.DeleteEntry(i)
+			}
+			return reconciler.AmendEntry(i, reconciling.EntryChange{
+				Start:    opt.Start,
+				End:      opt.End,
+				Duration: opt.Duration,
+				Summary:  opt.Summary,
+			}, opt.timeFormat(ctx.Config()))
+		},
+	)
+}
+
+// entryIndex returns the 0-based index of the entry, or -1 for the last entry.
+func (opt *Amend) entryIndex() (int, app.Error) {
+	if opt.Entry == "" || opt.Entry == "last" {
+		return -1, nil
+	}
+	position, err := strconv.Atoi(opt.Entry)
+	if err != nil || position < 1 {
+		return 0, app.NewErrorWithCode(
+			app.LOGICAL_ERROR,
+			"Invalid entry",
+			"Please specify the position of the entry (starting at 1), or 'last'",
+			nil,
+		)
+	}
+	return position - 1, nil
+}
+
+// timeFormat returns how to format the new start or end time. These are always
+// specified explicitly, so, other than with `--time` of e.g. `klog start`, they
+// are aligned with the style of the file, unless the config prescribes one.
+func (opt *Amend) timeFormat(config app.Config) reconciling.ReformatDirective[klog.TimeFormat] {
+	fd := reconciling.ReformatAutoStyle[klog.TimeFormat]()
+	config.TimeUse24HourClock.Map(func(x bool) {
+		fd = reconciling.ReformatExplicitly(klog.TimeFormat{Use24HourClock: x})
+	})
+	return fd
+}
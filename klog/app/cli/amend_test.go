@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const amendRecords = `
+1920-02-01
+	1h
+
+1920-02-02
+	9:00-10:00 Foo
+	1h30m Bar
+`
+
+func TestAmendLastEntry(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(amendRecords)._SetNow(1920, 2, 2, 12, 0)._Run((&Amend{
+		Entry:    "last",
+		Duration: klog.NewDuration(2, 0),
+		Summary:  klog.Ɀ_EntrySummary_("Baz"),
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+1920-02-01
+	1h
+
+1920-02-02
+	9:00-10:00 Foo
+	2h Baz
+`, state.writtenFileContents)
+}
+
+func TestAmendEntryAtPosition(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(amendRecords)._Run((&Amend{
+		Entry:      "1",
+		Start:      klog.Ɀ_Time_(8, 45),
+		AtDateArgs: lib.AtDateArgs{Date: klog.Ɀ_Date_(1920, 2, 2)},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+1920-02-01
+	1h
+
+1920-02-02
+	8:45-10:00 Foo
+	1h30m Bar
+`, state.writtenFileContents)
+}
+
+func TestAmendUsesTimeFormatOfFile(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+1920-02-02
+	9:00am-10:00am Foo
+	1h30m Bar
+`)._Run((&Amend{
+		Entry:      "1",
+		End:        klog.Ɀ_Time_(13, 0),
+		AtDateArgs: lib.AtDateArgs{Date: klog.Ɀ_Date_(1920, 2, 2)},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+1920-02-02
+	9:00am-1:00pm Foo
+	1h30m Bar
+`, state.writtenFileContents)
+}
+
+func TestAmendDeletesEntry(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(amendRecords)._Run((&Amend{
+		Entry:      "1",
+		Delete:     true,
+		AtDateArgs: lib.AtDateArgs{Date: klog.Ɀ_Date_(1920, 2, 2)},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+1920-02-01
+	1h
+
+1920-02-02
+	1h30m Bar
+`, state.writtenFileContents)
+}
+
+func TestAmendDeletesRecord(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(amendRecords)._Run((&Amend{
+		DeleteRecord: true,
+		AtDateArgs:   lib.AtDateArgs{Date: klog.Ɀ_Date_(1920, 2, 1)},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+1920-02-02
+	9:00-10:00 Foo
+	1h30m Bar
+`, state.writtenFileContents)
+	assert.Equal(t, "\nDeleted the record of 1920-02-01\n", state.printBuffer)
+}
+
+func TestAmendFails(t *testing.T) {
+	for _, x := range []struct {
+		opt *Amend
+		msg string
+	}{
+		{&Amend{Entry: "last"}, "Nothing to amend"},
+		{&Amend{Entry: "last", Delete: true, Start: klog.Ɀ_Time_(8, 0)}, "Conflicting flags"},
+		{&Amend{Entry: "last", Delete: true, DeleteRecord: true}, "Conflicting flags"},
+		{&Amend{Entry: "1", DeleteRecord: true}, "Conflicting flags"},
+		{&Amend{Entry: "last", Summary: klog.Ɀ_EntrySummary_("Foo"), ClearSummary: true}, "Conflicting flags"},
+		{&Amend{Entry: "0", Delete: true}, "Invalid entry"},
+		{&Amend{Entry: "first", Delete: true}, "Invalid entry"},
+		{&Amend{Entry: "3", Delete: true}, "Manipulation failed"},
+		{&Amend{Entry: "1", Start: klog.Ɀ_Time_(11, 0)}, "Manipulation failed"},
+	} {
+		x.opt.AtDateArgs = lib.AtDateArgs{Date: klog.Ɀ_Date_(1920, 2, 2)}
+		state, err := NewTestingContext()._SetRecords(amendRecords)._Run(x.opt.Run)
+		require.Error(t, err)
+		assert.Equal(t, x.msg, err.Error())
+		assert.Equal(t, "", state.writtenFileContents)
+	}
+}
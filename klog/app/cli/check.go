@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/app"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/jotaen/klog/klog/app/cli/lib/terminalformat"
+	"github.com/jotaen/klog/klog/parser/json"
+	"github.com/jotaen/klog/klog/service"
+	"sort"
+	"strconv"
+)
+
+type Check struct {
+	Strict bool `name:"strict" help:"Treat warnings as errors"`
+	lib.OutputArgs
+	lib.NoStyleArgs
+	lib.InputFilesArgs
+}
+
+func (opt *Check) Help() string {
+	return `It checks the records for potential mistakes, and prints the findings along with the file and line of the respective record.
+
+The checks are:
+    unclosed-open-range     An open time range that cannot be closed anymore
+    future-entry            An entry that lies in the future
+    overlapping-ranges      Time ranges within a record that overlap
+    more-than-24h           A record whose total time exceeds 24 hours
+    unknown-tag             A tag that is not declared in the tags file (only if there is one)
+
+The following working-time compliance checks are only performed if their respective limits are configured (see 'klog config'):
+    max-daily-work          A record whose total time exceeds 'max_daily_work_time'
+    required-breaks         A record whose breaks are shorter than per 'required_breaks'
+    min-rest                A day after which the rest period is shorter than 'min_rest_period'
+    max-weekly-work         A week whose total time exceeds 'max_weekly_work_time'
+Other commands only warn about 'max-daily-work' and 'required-breaks', since they might only look at a subset of the records.
+
+The following checks look at all records across all files, under the assumption that the files are about the same person:
+    duplicate-record        A record at the same date as one in a preceding file
+    missing-workday         A workday without record, between the first and the last record (off by default)
+    overlap-at-midnight     Time ranges that overlap with the ones of the previous day
+    concurrent-open-ranges  Open ranges in more than one file
+
+Workdays are the days with a positive should-total as per 'should_total_schedule', or Monday to Friday if there is no schedule. Absences from 'calendar_files' are not considered workdays.
+
+By default, all findings are warnings. In the config file, you can turn individual checks off, or make them errors (see 'klog config'). With --strict, all warnings are treated as errors.
+
+If there are errors, the command exits with a non-zero exit code, so that it can be used in scripts or CI pipelines.
+
+With --output json, csv, or tsv, the findings are printed in a machine-readable format.`
+}
+
+type finding struct {
+	file string
+
+	// fileI is the index of the file, or -1 if the finding has no location,
+	// e.g. because it is about the absence of a record.
+	fileI    int
+	line     int
+	date     klog.Date
+	rule     string
+	severity service.Severity
+	message  string
+}
+
+func (opt *Check) Run(ctx app.Context) app.Error {
+	opt.NoStyleArgs.Apply(&ctx)
+	files, err := ctx.ReadInputFiles(false, opt.File...)
+	if err != nil {
+		return err
+	}
+	registry, rErr := ctx.ReadTagRegistry()
+	if rErr != nil {
+		return rErr
+	}
+	rules := lib.ConfiguredCheckRules(ctx)
+	isWorkday, wErr := opt.workdays(ctx)
+	if wErr != nil {
+		return wErr
+	}
+
+	fileIndices := make(map[string]int)
+	var allRecords []klog.Record
+	var recordsPerFile [][]klog.Record
+	for fileI, f := range files {
+		fileIndices[f.File.Path()] = fileI
+		allRecords = append(allRecords, f.Records...)
+		recordsPerFile = append(recordsPerFile, f.Records)
+	}
+
+	var findings []finding
+	errorCount, warningCount := 0, 0
+	onWarn := func(w service.Warning) {
+		severity := rules.Severity(w.Rule())
+		if severity == service.SEVERITY_OFF {
+			return
+		}
+		if opt.Strict {
+			severity = service.SEVERITY_ERROR
+		}
+		if severity == service.SEVERITY_ERROR {
+			errorCount++
+		} else {
+			warningCount++
+		}
+		f := finding{fileI: -1, date: w.Date(), rule: w.Rule(), severity: severity, message: w.Warning()}
+		if w.Record() != nil && w.Record().Source() != nil {
+			source := w.Record().Source()
+			f.file, f.fileI, f.line = source.Path, fileIndices[source.Path], source.FirstLine
+		}
+		findings = append(findings, f)
+	}
+	service.CheckForWarnings(onWarn, ctx.Now(), registry, lib.ConfiguredWorkTimeLimits(ctx), allRecords)
+	service.CheckAcrossFiles(onWarn, isWorkday, recordsPerFile)
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].fileI != findings[j].fileI {
+			// Findings without location go last.
+			return findings[j].fileI == -1 || (findings[i].fileI != -1 && findings[i].fileI < findings[j].fileI)
+		}
+		if findings[i].fileI == -1 {
+			return !findings[i].date.IsAfterOrEqual(findings[j].date)
+		}
+		return findings[i].line < findings[j].line
+	})
+
+	if opt.IsMachineReadable() {
+		opt.printMachineReadable(ctx, findings, errorCount, warningCount)
+	} else {
+		opt.printFindings(ctx, findings, errorCount, warningCount)
+	}
+	if errorCount > 0 {
+		return app.NewErrorWithCode(
+			app.LOGICAL_ERROR,
+			"Check failed",
+			"There "+pluralise(errorCount, "is 1 error", "are %d errors")+" in the records",
+			nil,
+		)
+	}
+	return nil
+}
+
+func (opt *Check) printFindings(ctx app.Context, findings []finding, errorCount int, warningCount int) {
+	styles := map[service.Severity]terminalformat.Style{
+		service.SEVERITY_WARNING: {Color: "227"},
+		service.SEVERITY_ERROR:   {Color: "160", IsBold: true},
+	}
+	for _, f := range findings {
+		if f.fileI != -1 {
+			ctx.Print(fmt.Sprintf("%s:%d: ", displayPath(f.file), f.line))
+		}
+		ctx.Print(fmt.Sprintf(
+			"%s: %s: %s %s\n",
+			ctx.Serialiser().Format(styles[f.severity], f.severity.ToString()),
+			f.date.ToString(),
+			f.message,
+			ctx.Serialiser().Format(terminalformat.Style{Color: "247"}, "("+f.rule+")"),
+		))
+	}
+	if len(findings) == 0 {
+		ctx.Print("No problems found\n")
+		return
+	}
+	ctx.Print(fmt.Sprintf(
+		"\n%s, %s\n",
+		pluralise(errorCount, "1 error", "%d errors"),
+		pluralise(warningCount, "1 warning", "%d warnings"),
+	))
+}
+
+func (opt *Check) printMachineReadable(ctx app.Context, findings []finding, errorCount int, warningCount int) {
+	if opt.Output == "json" {
+		envelop := json.CheckEnvelop{Errors: errorCount, Warnings: warningCount}
+		for _, f := range findings {
+			view := json.FindingView{
+				Date:     f.date.ToString(),
+				Rule:     f.rule,
+				Severity: f.severity.ToString(),
+				Message:  f.message,
+			}
+			if f.fileI != -1 {
+				file, line := f.file, f.line
+				view.File, view.Line = &file, &line
+			}
+			envelop.Findings = append(envelop.Findings, view)
+		}
+		ctx.Print(json.CheckToJson(envelop, opt.Pretty) + "\n")
+		return
+	}
+	rows := [][]string{{"file", "line", "date", "rule", "severity", "message"}}
+	for _, f := range findings {
+		file, line := "", ""
+		if f.fileI != -1 {
+			file, line = f.file, strconv.Itoa(f.line)
+		}
+		rows = append(rows, []string{
+			file, line, f.date.ToString(), f.rule, f.severity.ToString(), f.message,
+		})
+	}
+	opt.PrintRows(ctx, rows)
+}
+
+// workdays returns a function that tells whether a date is a workday, as per
+// the configured schedule and calendars.
+func (opt *Check) workdays(ctx app.Context) (func(klog.Date) bool, app.Error) {
+	isScheduled := func(d klog.Date) bool {
+		return d.Weekday() <= 5
+	}
+	ctx.Config().ShouldTotalSchedule.Map(func(s service.ShouldTotalSchedule) {
+		isScheduled = func(d klog.Date) bool {
+			should := s.ShouldTotalAt(d)
+			return should != nil && should.InMinutes() > 0
+		}
+	})
+	c, err := ctx.ReadCalendar()
+	if err != nil {
+		return nil, err
+	}
+	return func(d klog.Date) bool {
+		return isScheduled(d) && len(c.AbsencesAt(d)) == 0
+	}, nil
+}
+
+// displayPath returns the path of an input file for displaying it.
+func displayPath(path string) string {
+	if path == "" {
+		return "(stdin)"
+	}
+	return path
+}
+
+// pluralise returns the singular text if the count is 1, and otherwise the
+// plural text with the count filled in.
+func pluralise(count int, singular string, plural string) string {
+	if count == 1 {
+		return singular
+	}
+	return fmt.Sprintf(plural, count)
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"github.com/jotaen/klog/klog/app"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCheckWithoutFindings(t *testing.T) {
+	state, err := NewTestingContext()._SetNow(2020, 1, 10, 12, 0)._SetFile("/tmp/a.klg", `
+2020-01-01
+	8:00-9:00
+`)._Run((&Check{InputFilesArgs: lib.InputFilesArgs{File: []app.FileOrBookmarkName{"/tmp/a.klg"}}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\nNo problems found\n", state.printBuffer)
+}
+
+func TestCheckPrintsFindingsWithFileAndLine(t *testing.T) {
+	state, err := NewTestingContext()._SetNow(2020, 1, 10, 12, 0)._SetFile("/tmp/a.klg", `
+2020-01-02
+	8:00-9:00
+	8:30-10:00
+
+2020-01-01
+	8:00-?
+`)._SetFile("/tmp/b.klg", `2020-01-03
+	25h
+`)._Run((&Check{InputFilesArgs: lib.InputFilesArgs{File: []app.FileOrBookmarkName{"/tmp/a.klg", "/tmp/b.klg"}}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+/tmp/a.klg:2: warning: 2020-01-02: Overlapping time ranges (overlapping-ranges)
+/tmp/a.klg:6: warning: 2020-01-01: Unclosed open range (unclosed-open-range)
+/tmp/b.klg:1: warning: 2020-01-03: Total time exceeds 24 hours (more-than-24h)
+
+0 errors, 3 warnings
+`, state.printBuffer)
+}
+
+func TestCheckAppliesSeveritiesFromConfig(t *testing.T) {
+	state, err := NewTestingContext()._SetNow(2020, 1, 10, 12, 0)._SetFileConfig(`
+check_rules = overlapping-ranges: error, unclosed-open-range: off
+`)._SetFile("/tmp/a.klg", `
+2020-01-02
+	8:00-9:00
+	8:30-10:00
+
+2020-01-01
+	8:00-?
+`)._Run((&Check{InputFilesArgs: lib.InputFilesArgs{File: []app.FileOrBookmarkName{"/tmp/a.klg"}}}).Run)
+	require.Error(t, err)
+	assert.Equal(t, app.LOGICAL_ERROR, err.Code())
+	assert.Equal(t, "There is 1 error in the records", err.Details())
+	assert.Equal(t, `
+/tmp/a.klg:2: error: 2020-01-02: Overlapping time ranges (overlapping-ranges)
+
+1 error, 0 warnings
+`, state.printBuffer)
+}
+
+func TestCheckTreatsWarningsAsErrorsInStrictMode(t *testing.T) {
+	state, err := NewTestingContext()._SetNow(2020, 1, 10, 12, 0)._SetFile("/tmp/a.klg", `
+2020-01-01
+	25h
+`)._Run((&Check{Strict: true, InputFilesArgs: lib.InputFilesArgs{File: []app.FileOrBookmarkName{"/tmp/a.klg"}}}).Run)
+	require.Error(t, err)
+	assert.Equal(t, `
+/tmp/a.klg:2: error: 2020-01-01: Total time exceeds 24 hours (more-than-24h)
+
+1 error, 0 warnings
+`, state.printBuffer)
+}
+
+func TestCheckWithJsonOutput(t *testing.T) {
+	state, err := NewTestingContext()._SetNow(2020, 1, 10, 12, 0)._SetTags(`
+#work
+`)._SetFile("/tmp/a.klg", `
+2020-01-01
+	2h #work
+	1h #play
+`)._Run((&Check{OutputArgs: lib.OutputArgs{Output: "json"}, InputFilesArgs: lib.InputFilesArgs{File: []app.FileOrBookmarkName{"/tmp/a.klg"}}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\n"+`{"findings":[{"file":"/tmp/a.klg","line":2,"date":"2020-01-01","rule":"unknown-tag","severity":"warning","message":"Unknown tag: #play"}],"errors":0,"warnings":1}`+"\n", state.printBuffer)
+}
+
+func TestCheckWithCsvOutput(t *testing.T) {
+	state, err := NewTestingContext()._SetNow(2020, 1, 10, 12, 0)._SetFile("/tmp/a.klg", `
+2020-01-01
+	25h
+`)._Run((&Check{OutputArgs: lib.OutputArgs{Output: "csv"}, InputFilesArgs: lib.InputFilesArgs{File: []app.FileOrBookmarkName{"/tmp/a.klg"}}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+file,line,date,rule,severity,message
+/tmp/a.klg,2,2020-01-01,more-than-24h,warning,Total time exceeds 24 hours
+`, state.printBuffer)
+}
+
+func TestCheckPerformsComplianceChecksWithConfiguredLimits(t *testing.T) {
+	state, err := NewTestingContext()._SetNow(2020, 1, 10, 12, 0)._SetFileConfig(`
+max_daily_work_time = 10h
+required_breaks = 6h: 30m
+min_rest_period = 11h
+check_rules = min-rest: error
+`)._SetFile("/tmp/a.klg", `
+2020-01-01
+	8:00-19:00
+
+2020-01-02
+	5:00-12:00
+`)._Run((&Check{InputFilesArgs: lib.InputFilesArgs{File: []app.FileOrBookmarkName{"/tmp/a.klg"}}}).Run)
+	require.Error(t, err)
+	assert.Equal(t, `
+/tmp/a.klg:2: warning: 2020-01-01: Daily work time exceeds the maximum (max-daily-work)
+/tmp/a.klg:2: warning: 2020-01-01: Breaks are shorter than required (required-breaks)
+/tmp/a.klg:2: error: 2020-01-01: Rest period before the next day is too short (min-rest)
+/tmp/a.klg:5: warning: 2020-01-02: Breaks are shorter than required (required-breaks)
+
+1 error, 3 warnings
+`, state.printBuffer)
+}
+
+func TestCheckAcrossFiles(t *testing.T) {
+	state, err := NewTestingContext()._SetNow(2020, 1, 10, 12, 0)._SetFileConfig(`
+check_rules = missing-workday: warning
+`)._SetFile("/tmp/a.klg", `
+2020-01-01
+	1h
+`)._SetFile("/tmp/b.klg", `
+2020-01-03
+	1h
+
+2020-01-01
+	2h
+`)._Run((&Check{InputFilesArgs: lib.InputFilesArgs{File: []app.FileOrBookmarkName{"/tmp/a.klg", "/tmp/b.klg"}}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+/tmp/b.klg:5: warning: 2020-01-01: Record at the same date in another file (duplicate-record)
+warning: 2020-01-02: No record at a workday (missing-workday)
+
+0 errors, 2 warnings
+`, state.printBuffer)
+}
+
+func TestCheckAcrossFilesWithJsonOutput(t *testing.T) {
+	state, err := NewTestingContext()._SetNow(2020, 1, 10, 12, 0)._SetFileConfig(`
+check_rules = missing-workday: error
+`)._SetFile("/tmp/a.klg", `
+2020-01-01
+	1h
+
+2020-01-03
+	1h
+`)._Run((&Check{OutputArgs: lib.OutputArgs{Output: "json"}, InputFilesArgs: lib.InputFilesArgs{File: []app.FileOrBookmarkName{"/tmp/a.klg"}}}).Run)
+	require.Error(t, err)
+	assert.Equal(t, "\n"+`{"findings":[{"file":null,"line":null,"date":"2020-01-02","rule":"missing-workday","severity":"error","message":"No record at a workday"}],"errors":1,"warnings":0}`+"\n", state.printBuffer)
+}
@@ -15,11 +15,13 @@ type Create struct {
 	lib.NoStyleArgs
 	lib.OutputFileArgs
 	lib.WarnArgs
+	lib.DryRunArgs
 }
 
 func (opt *Create) Help() string {
 	return `The new record is inserted into the file at the chronologically correct position.
-(Assuming that the records are sorted from oldest to latest.)`
+(Respecting whether the records are sorted in ascending or descending order.)
+You can configure klog to always append new records at the end of the file instead, see 'klog config'.`
 }
 
 func (opt *Create) Run(ctx app.Context) app.Error {
@@ -27,11 +29,12 @@ func (opt *Create) Run(ctx app.Context) app.Error {
 	date := opt.AtDate(ctx.Now())
 	additionalData := reconciling.AdditionalData{ShouldTotal: opt.GetShouldTotal(), Summary: opt.Summary}
 	if additionalData.ShouldTotal == nil {
-		ctx.Config().DefaultShouldTotal.Map(func(s klog.ShouldTotal) {
-			additionalData.ShouldTotal = s
-		})
+		additionalData.ShouldTotal = lib.ConfiguredShouldTotal(ctx, date)
 	}
-	return lib.Reconcile(ctx, lib.ReconcileOpts{OutputFileArgs: opt.OutputFileArgs, WarnArgs: opt.WarnArgs},
+	ctx.Config().AppendNewRecords.Map(func(a bool) {
+		additionalData.AppendAtEnd = a
+	})
+	return lib.Reconcile(ctx, lib.ReconcileOpts{OutputFileArgs: opt.OutputFileArgs, WarnArgs: opt.WarnArgs, DryRunArgs: opt.DryRunArgs},
 		[]reconciling.Creator{
 			reconciling.NewReconcilerForNewRecord(date, opt.DateFormat(ctx.Config()), additionalData),
 		},
@@ -192,6 +192,67 @@ default_should_total = 30m!
 	9:00-12:00
 
 1920-02-03 (5h55m!)
+`, state.writtenFileContents)
+	}
+
+	// With should-total from schedule in config file
+	for _, x := range []struct {
+		day      int
+		config   string
+		expected string
+	}{
+		{5, `should_total_schedule = mon-thu: 8h, fri: 6h, sat-sun: 0h`, "2023-05-05 (6h!)\n"},
+		{6, `should_total_schedule = mon-thu: 8h, fri: 6h, sat-sun: 0h`, "2023-05-06\n"},
+		{5, "should_total_schedule = mon-fri: 8h\ndefault_should_total = 1h!", "2023-05-05 (1h!)\n"},
+	} {
+		state, err := NewTestingContext()._SetFileConfig(x.config)._SetNow(2023, 5, x.day, 15, 24)._Run((&Create{}).Run)
+		require.Nil(t, err)
+		assert.Equal(t, x.expected, state.writtenFileContents)
+	}
+}
+
+func TestCreateWithNewRecordPositionConfig(t *testing.T) {
+	// By default, insert at chronological position
+	{
+		state, err := NewTestingContext()._SetRecords(`
+1920-02-03
+	1h
+
+1920-02-01
+	2h
+`)._Run((&Create{AtDateArgs: lib.AtDateArgs{Date: klog.Ɀ_Date_(1920, 2, 2)}}).Run)
+		require.Nil(t, err)
+		assert.Equal(t, `
+1920-02-03
+	1h
+
+1920-02-02
+
+1920-02-01
+	2h
+`, state.writtenFileContents)
+	}
+
+	// Append at the end, if configured
+	{
+		state, err := NewTestingContext()._SetRecords(`
+1920-02-03
+	1h
+
+1920-02-01
+	2h
+`)._SetFileConfig(`
+new_record_position = end
+`)._Run((&Create{AtDateArgs: lib.AtDateArgs{Date: klog.Ɀ_Date_(1920, 2, 2)}}).Run)
+		require.Nil(t, err)
+		assert.Equal(t, `
+1920-02-03
+	1h
+
+1920-02-01
+	2h
+
+1920-02-02
 `, state.writtenFileContents)
 	}
 }
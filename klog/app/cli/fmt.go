@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/app"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/jotaen/klog/klog/parser/reconciling"
+)
+
+type Fmt struct {
+	Check bool `name:"check" help:"Don’t write the file(s), but print a diff and fail if not formatted"`
+	lib.NoStyleArgs
+	lib.InputFilesArgs
+}
+
+func (opt *Fmt) Help() string {
+	return `It rewrites the file(s) in a uniform manner: it normalises the indentation, the line endings, the blank lines between records, and the format of the dates (dashes or slashes) and times (12-hour or 24-hour clock). All summaries and the order of the records stay as they are.
+
+The format of dates and times is taken from the config file (date_format and time_convention). Otherwise, the style that prevails in the respective file is used.
+
+With --check, the files are not modified. Instead, it prints the differences for every file that is not formatted, and exits with a non-zero status. That is useful for pre-commit hooks, for example.`
+}
+
+func (opt *Fmt) Run(ctx app.Context) app.Error {
+	opt.NoStyleArgs.Apply(&ctx)
+	dateFormat := reconciling.ReformatAutoStyle[klog.DateFormat]()
+	ctx.Config().DateUseDashes.Map(func(x bool) {
+		dateFormat = reconciling.ReformatExplicitly(klog.DateFormat{UseDashes: x})
+	})
+	timeFormat := reconciling.ReformatAutoStyle[klog.TimeFormat]()
+	ctx.Config().TimeUse24HourClock.Map(func(x bool) {
+		timeFormat = reconciling.ReformatExplicitly(klog.TimeFormat{Use24HourClock: x})
+	})
+	format := reconciling.NewFormatter(dateFormat, timeFormat)
+
+	files := opt.File
+	if len(files) == 0 {
+		files = []app.FileOrBookmarkName{""} // I.e., the default bookmark
+	}
+	unformattedCount := 0
+	for _, f := range files {
+		result, err := ctx.FormatFile(f, format, !opt.Check)
+		if err != nil {
+			return err
+		}
+		if opt.Check && result.HasChanged() {
+			unformattedCount++
+			ctx.Print(lib.PrettifyDiff(ctx.Serialiser(), lib.DisplayName(f), result.Original, result.Formatted))
+		}
+	}
+	if unformattedCount > 0 {
+		return app.NewErrorWithCode(
+			app.LOGICAL_ERROR,
+			"Not formatted",
+			fmt.Sprintf("%d of %d file(s) are not formatted", unformattedCount, len(files)),
+			nil,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"github.com/jotaen/klog/klog/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const unformattedRecords = `
+2020/01/01
+Summary
+	8:00-9:00 Foo
+	1h
+
+2020/01/02
+	9:00-9:30
+`
+
+func TestFormatsFile(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(unformattedRecords)._Run((&Fmt{}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "", state.printBuffer)
+	assert.Equal(t, `2020/01/01
+Summary
+	8:00-9:00 Foo
+	1h
+
+2020/01/02
+	9:00-9:30
+`, state.writtenFileContents)
+}
+
+func TestFormatsFileAccordingToConfig(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(unformattedRecords)._SetFileConfig(`
+date_format = YYYY-MM-DD
+time_convention = 12h
+`)._Run((&Fmt{}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `2020-01-01
+Summary
+	8:00am-9:00am Foo
+	1h
+
+2020-01-02
+	9:00am-9:30am
+`, state.writtenFileContents)
+}
+
+func TestCheckFailsAndPrintsDiffIfNotFormatted(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(unformattedRecords)._SetFileConfig(`
+date_format = YYYY-MM-DD
+`)._Run((&Fmt{Check: true}).Run)
+	require.Error(t, err)
+	assert.Equal(t, app.LOGICAL_ERROR, err.Code())
+	assert.Equal(t, "", state.writtenFileContents)
+	assert.Equal(t, `
+--- (default bookmark)
++++ (default bookmark)
+@@ -1,8 +1,7 @@
+-
+-2020/01/01
++2020-01-01
+ Summary
+ 	8:00-9:00 Foo
+ 	1h
+ 
+-2020/01/02
++2020-01-02
+ 	9:00-9:30
+`, state.printBuffer)
+}
+
+func TestCheckSucceedsIfFormatted(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords("2020-01-01\n    1h\n")._Run((&Fmt{Check: true}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "", state.printBuffer)
+	assert.Equal(t, "", state.writtenFileContents)
+}
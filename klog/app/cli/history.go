@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/app"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+)
+
+type History struct {
+	Diff bool `name:"diff" help:"Print the differences of every change"`
+	lib.OutputFileArgs
+	lib.NoStyleArgs
+}
+
+func (opt *History) Help() string {
+	return `Lists the latest changes that klog made to a file, the latest one first.
+The number in the first column is how many changes 'klog undo --count' would need to revert in order to get to the state before that change.`
+}
+
+func (opt *History) Run(ctx app.Context) app.Error {
+	opt.NoStyleArgs.Apply(&ctx)
+	target, err := ctx.RetrieveTargetFile(opt.File)
+	if err != nil {
+		return err
+	}
+	journal, jErr := ctx.ReadJournal()
+	if jErr != nil {
+		return jErr
+	}
+	history := journal.History(target)
+	if len(history) == 0 {
+		ctx.Print("There are no recorded changes for this file.\n")
+		return nil
+	}
+	for i, e := range history {
+		added, removed := lib.CountChangedLines(e.Before, e.After)
+		ctx.Print(fmt.Sprintf(
+			"%2d  %s %s  %s %s\n",
+			i+1,
+			klog.NewDateFromGo(e.Time).ToString(),
+			fmt.Sprintf("%5s", klog.NewTimeFromGo(e.Time).ToString()),
+			ctx.Serialiser().Format(lib.Green, fmt.Sprintf("+%d", added)),
+			ctx.Serialiser().Format(lib.Red, fmt.Sprintf("-%d", removed)),
+		))
+		if opt.Diff {
+			ctx.Print(lib.PrettifyDiff(ctx.Serialiser(), e.Path, e.Before, e.After) + "\n")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"github.com/jotaen/klog/klog/app"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	gotime "time"
+)
+
+func TestPrintsHistory(t *testing.T) {
+	state, err := NewTestingContext()._SetJournal(
+		app.JournalEntry{Path: "/tmp/test.klg", Time: gotime.Date(2020, 1, 1, 9, 30, 0, 0, gotime.UTC), Before: "2020-01-01\n", After: "2020-01-01\n\t1h\n"},
+		app.JournalEntry{Path: "/tmp/other.klg", Time: gotime.Date(2020, 1, 1, 10, 0, 0, 0, gotime.UTC), Before: "", After: "2020-01-01\n"},
+		app.JournalEntry{Path: "/tmp/test.klg", Time: gotime.Date(2020, 1, 2, 17, 5, 0, 0, gotime.UTC), Before: "2020-01-01\n\t1h\n", After: "2020-01-01\n\t2h\n"},
+	)._Run((&History{OutputFileArgs: lib.OutputFileArgs{File: "/tmp/test.klg"}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+ 1  2020-01-02 17:05  +1 -1
+ 2  2020-01-01  9:30  +1 -0
+`, state.printBuffer)
+}
+
+func TestPrintsHistoryWithDiff(t *testing.T) {
+	state, err := NewTestingContext()._SetJournal(
+		app.JournalEntry{Path: "/tmp/test.klg", Time: gotime.Date(2020, 1, 1, 9, 30, 0, 0, gotime.UTC), Before: "2020-01-01\n", After: "2020-01-01\n\t1h\n"},
+	)._Run((&History{Diff: true, OutputFileArgs: lib.OutputFileArgs{File: "/tmp/test.klg"}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+ 1  2020-01-01  9:30  +1 -0
+--- /tmp/test.klg
++++ /tmp/test.klg
+@@ -1,1 +1,2 @@
+ 2020-01-01
++	1h
+
+`, state.printBuffer)
+}
+
+func TestPrintsEmptyHistory(t *testing.T) {
+	state, err := NewTestingContext()._Run((&History{OutputFileArgs: lib.OutputFileArgs{File: "/tmp/test.klg"}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\nThere are no recorded changes for this file.\n", state.printBuffer)
+}
@@ -12,24 +12,35 @@ type Cli struct {
 	Default Default `hidden:"" cmd:"" default:"withargs" help:""`
 
 	// Evaluate Files
-	Print  Print  `cmd:"" name:"print" group:"Evaluate Files" help:"Pretty-prints records"`
-	Total  Total  `cmd:"" name:"total" group:"Evaluate Files" help:"Evaluates the total time"`
-	Report Report `cmd:"" name:"report" group:"Evaluate Files" help:"Prints an aggregated calendar report"`
-	Tags   Tags   `cmd:"" name:"tags" group:"Evaluate Files" help:"Prints total times aggregated by tags"`
-	Today  Today  `cmd:"" name:"today" group:"Evaluate Files" help:"Evaluates the current day"`
+	Print   Print   `cmd:"" name:"print" group:"Evaluate Files" help:"Pretty-prints records"`
+	Total   Total   `cmd:"" name:"total" group:"Evaluate Files" help:"Evaluates the total time"`
+	Report  Report  `cmd:"" name:"report" group:"Evaluate Files" help:"Prints an aggregated calendar report"`
+	Tags    Tags    `cmd:"" name:"tags" group:"Evaluate Files" help:"Prints total times aggregated by tags"`
+	Today   Today   `cmd:"" name:"today" group:"Evaluate Files" help:"Evaluates the current day"`
+	Invoice Invoice `cmd:"" name:"invoice" group:"Evaluate Files" help:"Bills time entries by hourly rates per tag"`
+	Check   Check   `cmd:"" name:"check" group:"Evaluate Files" help:"Checks the records for potential mistakes"`
 
 	// Manipulate Files
 	Track  Track  `cmd:"" name:"track" group:"Manipulate Files" help:"Adds a new entry to a record"`
 	Start  Start  `cmd:"" name:"start" group:"Manipulate Files" aliases:"in" help:"Starts a new open time range"`
 	Stop   Stop   `cmd:"" name:"stop" group:"Manipulate Files" aliases:"out" help:"Closes the open time range"`
+	Switch Switch `cmd:"" name:"switch" group:"Manipulate Files" help:"Closes the open time range and starts a new one"`
 	Pause  Pause  `cmd:"" name:"pause" group:"Manipulate Files" help:"Pauses the open time range"`
 	Create Create `cmd:"" name:"create" group:"Manipulate Files" help:"Creates a new, empty record"`
+	Amend  Amend  `cmd:"" name:"amend" group:"Manipulate Files" help:"Changes or deletes an existing entry or record"`
+	Retag  Retag  `cmd:"" name:"retag" group:"Manipulate Files" help:"Replaces a tag in all summaries of a file"`
 
 	// Manage Files
 	Bookmarks Bookmarks `cmd:"" name:"bookmarks" group:"Manage Files" aliases:"bk" help:"Named aliases for often-used files"`
 	Bookmark  Bookmarks `cmd:"" name:"bookmark" hidden:"" help:"(Alias)"` // Hidden alias for convenience / typo
 	Edit      Edit      `cmd:"" name:"edit" group:"Manage Files" help:"Opens a file or bookmark in your editor"`
 	Goto      Goto      `cmd:"" name:"goto" group:"Manage Files" help:"Opens the file explorer at a file or bookmark"`
+	Fmt       Fmt       `cmd:"" name:"fmt" group:"Manage Files" help:"Formats files in a uniform manner"`
+	Sort      Sort      `cmd:"" name:"sort" group:"Manage Files" help:"Sorts the records of a file by date"`
+	Split     Split     `cmd:"" name:"split" group:"Manage Files" help:"Splits a file into one file per month or year"`
+	Merge     Merge     `cmd:"" name:"merge" group:"Manage Files" help:"Combines the records of multiple files"`
+	Undo      Undo      `cmd:"" name:"undo" group:"Manage Files" help:"Reverts the latest change(s) to a file"`
+	History   History   `cmd:"" name:"history" group:"Manage Files" help:"Lists the latest changes to a file"`
 
 	// Misc
 	Version    Version       `cmd:"" name:"version" group:"Misc" help:"Prints version info and check for updates"`
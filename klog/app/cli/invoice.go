@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"github.com/jotaen/klog/klog/app"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/jotaen/klog/klog/app/cli/lib/terminalformat"
+	"github.com/jotaen/klog/klog/parser/json"
+	"github.com/jotaen/klog/klog/service"
+	"github.com/jotaen/klog/klog/service/billing"
+	"strconv"
+	"strings"
+)
+
+type Invoice struct {
+	Round  service.Rounding `name:"round" short:"r" help:"Round up the duration of each entry to multiple of 5m, 10m, 15m, 30m, or 60m / 1h"`
+	Output string           `name:"output" short:"o" help:"Output format: table, json, csv, tsv, or markdown" enum:"table,json,csv,tsv,markdown" default:"table"`
+	Pretty bool             `name:"pretty" help:"Pretty-print JSON output"`
+	lib.FilterArgs
+	lib.NowArgs
+	lib.WarnArgs
+	lib.LenientArgs
+	lib.NoStyleArgs
+	lib.InputFilesArgs
+}
+
+func (opt *Invoice) Help() string {
+	return `Bills the time entries according to hourly rates per tag.
+
+The rates are specified in the file ` + app.RATES_FILE_NAME + ` in the klog config folder (see 'klog info config-folder'). Every line contains a tag and the respective hourly rate (with up to two decimal places), optionally followed by a currency. Lines starting with ; are comments. Example:
+
+    #acme = 120 EUR
+    #acme=internal = 0 EUR
+    #globex/consulting = 150.50 USD
+
+Every entry is billed with the rate of its tags (including the tags of the record summary). If multiple rates apply, the most specific tag wins, i.e. a tag with value (#acme=internal) over a tag without (#acme), and a nested tag (#acme/backend) over its parent (#acme). Entries without any matching rate are not billed. Tag aliases from the file ` + app.TAGS_FILE_NAME + ` are folded into their tag, both in the entries and in the rates.
+
+With --round, the duration of every entry is rounded up before billing. The default for that can be specified in the config file.`
+}
+
+func (opt *Invoice) Run(ctx app.Context) app.Error {
+	opt.NoStyleArgs.Apply(&ctx)
+	rates, rErr := ctx.ReadRates()
+	if rErr != nil {
+		return rErr
+	}
+	if rates.IsEmpty() {
+		return app.NewErrorWithCode(
+			app.LOGICAL_ERROR,
+			"No rates defined",
+			"Please specify hourly rates in the file "+app.Join(ctx.KlogConfigFolder(), app.RATES_FILE_NAME).Path(),
+			nil,
+		)
+	}
+	records, err := opt.LenientArgs.ReadInputs(ctx, opt.File...)
+	if err != nil {
+		return err
+	}
+	now := ctx.Now()
+	registry, rErr := ctx.ReadTagRegistry()
+	if rErr != nil {
+		return rErr
+	}
+	records = opt.ApplyFilter(now, registry, records)
+	nErr := opt.ApplyNow(now, records...)
+	if nErr != nil {
+		return nErr
+	}
+	rounding := opt.Round
+	if rounding == nil {
+		ctx.Config().BillingRounding.Map(func(r service.Rounding) {
+			rounding = r
+		})
+	}
+	invoice := billing.NewInvoice(registry, rates, rounding, records...)
+	switch opt.Output {
+	case "json":
+		ctx.Print(json.InvoiceToJson(invoice, opt.Pretty) + "\n")
+	case "csv":
+		ctx.Print(lib.ToDelimited(invoiceToRows(invoice), ','))
+	case "tsv":
+		ctx.Print(lib.ToDelimited(invoiceToRows(invoice), '\t'))
+	case "markdown":
+		ctx.Print(invoiceToMarkdown(invoice))
+	default:
+		if len(invoice.LineItems) == 0 {
+			break
+		}
+		printInvoiceTable(ctx, invoice)
+		opt.WarnArgs.PrintWarnings(ctx, records, opt.GetNowWarnings())
+	}
+	return nil
+}
+
+func printInvoiceTable(ctx app.Context, invoice billing.Invoice) {
+	table := terminalformat.NewTable(5, "  ")
+	table.CellL("Tag").CellR("Time").CellR("Hours").CellR("Rate").CellR("Amount")
+	for _, item := range invoice.LineItems {
+		table.
+			CellL(item.Tag.ToString()).
+			CellR(ctx.Serialiser().Duration(item.Total)).
+			CellR(formatDecimal(item.Hours())).
+			CellR(formatMoney(item.Rate.Amount, item.Rate.Currency)).
+			CellR(formatMoney(item.Amount, item.Rate.Currency))
+	}
+	table.Skip(4).Fill("=")
+	for _, sum := range invoice.Sums {
+		table.Skip(4).CellR(formatMoney(sum.Amount, sum.Currency))
+	}
+	table.Collect(ctx.Print)
+}
+
+func invoiceToRows(invoice billing.Invoice) [][]string {
+	rows := [][]string{{"tag", "time", "hours", "rate", "currency", "amount"}}
+	for _, item := range invoice.LineItems {
+		rows = append(rows, []string{
+			item.Tag.ToString(),
+			item.Total.ToString(),
+			formatDecimal(item.Hours()),
+			billing.FormatCents(item.Rate.Amount),
+			item.Rate.Currency,
+			billing.FormatCents(item.Amount),
+		})
+	}
+	return rows
+}
+
+func invoiceToMarkdown(invoice billing.Invoice) string {
+	var lines []string
+	row := func(cells ...string) {
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+	}
+	row("Tag", "Time", "Hours", "Rate", "Amount")
+	row(":--", "--:", "--:", "--:", "--:")
+	for _, item := range invoice.LineItems {
+		row(
+			"`"+item.Tag.ToString()+"`",
+			item.Total.ToString(),
+			formatDecimal(item.Hours()),
+			formatMoney(item.Rate.Amount, item.Rate.Currency),
+			formatMoney(item.Amount, item.Rate.Currency),
+		)
+	}
+	for _, sum := range invoice.Sums {
+		row("**Total**", "", "", "", "**"+formatMoney(sum.Amount, sum.Currency)+"**")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func formatDecimal(x float64) string {
+	return strconv.FormatFloat(x, 'f', 2, 64)
+}
+
+func formatMoney(cents int, currency string) string {
+	return strings.TrimSpace(billing.FormatCents(cents) + " " + currency)
+}
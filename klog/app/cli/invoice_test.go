@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/jotaen/klog/klog/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const invoiceRecords = `
+2020-01-01
+#acme
+	1h30m Meeting
+	2h10m #acme/backend
+
+2020-01-02
+	20m #globex
+	5h not billable
+`
+
+const invoiceRates = `
+#acme = 100 EUR
+#acme/backend = 120 EUR
+#globex = 90 USD
+`
+
+func TestPrintsInvoiceTable(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(invoiceRecords)._SetRates(invoiceRates)._Run((&Invoice{}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+Tag             Time  Hours        Rate      Amount
+#acme          1h30m   1.50  100.00 EUR  150.00 EUR
+#acme/backend  2h10m   2.17  120.00 EUR  260.00 EUR
+#globex          20m   0.33   90.00 USD   30.00 USD
+                                         ==========
+                                         410.00 EUR
+                                          30.00 USD
+`, state.printBuffer)
+}
+
+func TestPrintsInvoiceWithRoundingFromConfig(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(invoiceRecords)._SetRates(invoiceRates)._SetFileConfig(`billing_rounding = 30m`)._Run((&Invoice{Output: "csv"}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+tag,time,hours,rate,currency,amount
+#acme,1h30m,1.50,100.00,EUR,150.00
+#acme/backend,2h30m,2.50,120.00,EUR,300.00
+#globex,30m,0.50,90.00,USD,45.00
+`, state.printBuffer)
+}
+
+func TestPrintsInvoiceAsMarkdown(t *testing.T) {
+	rounding, _ := service.NewRounding(60)
+	state, err := NewTestingContext()._SetRecords(invoiceRecords)._SetRates(invoiceRates)._SetFileConfig(`billing_rounding = 30m`)._Run((&Invoice{
+		Output: "markdown",
+		Round:  rounding,
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\n"+
+		"| Tag | Time | Hours | Rate | Amount |\n"+
+		"| :-- | --: | --: | --: | --: |\n"+
+		"| `#acme` | 2h | 2.00 | 100.00 EUR | 200.00 EUR |\n"+
+		"| `#acme/backend` | 3h | 3.00 | 120.00 EUR | 360.00 EUR |\n"+
+		"| `#globex` | 1h | 1.00 | 90.00 USD | 90.00 USD |\n"+
+		"| **Total** |  |  |  | **560.00 EUR** |\n"+
+		"| **Total** |  |  |  | **90.00 USD** |\n",
+		state.printBuffer)
+}
+
+func TestInvoiceFailsWithoutRates(t *testing.T) {
+	_, err := NewTestingContext()._SetRecords(invoiceRecords)._Run((&Invoice{}).Run)
+	require.Error(t, err)
+	assert.Equal(t, "No rates defined", err.Error())
+}
+
+func TestPrintsInvoiceAsJson(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(invoiceRecords)._SetRates(`#globex = 90 USD`)._Run((&Invoice{Output: "json"}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\n"+`{"line_items":[`+
+		`{"tag":"#globex","total":"20m","total_mins":20,"hours":0.3333333333333333,"rate":90,"currency":"USD","amount":30}`+
+		`],"sums":[{"currency":"USD","amount":30}]}`+"\n", state.printBuffer)
+}
+
+func TestPrintsInvoiceWithTagAliases(t *testing.T) {
+	query, _ := service.NewTagQueryFromString("#meeting")
+	state, err := NewTestingContext()._SetRecords(`
+2020-01-01
+	1h #mtg
+	2h #meeting
+	4h #other
+`)._SetTags(`
+#meeting = #mtg
+#other
+`)._SetRates(`
+#meeting = 50 EUR
+#other = 10 EUR
+`)._Run((&Invoice{Output: "csv", FilterArgs: lib.FilterArgs{Tags: []service.TagQuery{query}}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+tag,time,hours,rate,currency,amount
+#meeting,3h,3.00,50.00,EUR,150.00
+`, state.printBuffer)
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"github.com/jotaen/klog/klog"
 	"github.com/jotaen/klog/klog/app"
 	"github.com/jotaen/klog/klog/app/cli/lib"
 	"github.com/jotaen/klog/klog/parser/json"
@@ -11,26 +12,29 @@ type Json struct {
 	lib.NowArgs
 	lib.FilterArgs
 	lib.SortArgs
+	lib.LenientArgs
 	lib.InputFilesArgs
 }
 
 func (opt *Json) Help() string {
 	return `The output structure contains two properties at the top level: "records" and "errors".
 
-If the file is valid, "records" is an array containing a JSON object for each record; "errors" is null.
+If the file is valid, "records" is an array containing a JSON object for each record; "errors" is null. Each record contains its "source", i.e. the file and lines it was read from.
 
-If the file has syntax errors, "records" is null and "errors" contains an array of error objects.
+If the file has syntax errors, "records" is null and "errors" contains an array of error objects, along with the file they occurred in.
+
+With --lenient (or 'parsing_mode = lenient' in the config file), "records" contains the valid records nevertheless, and "errors" contains the errors of the invalid ones (or null, if there are none).
 
 The structure of the "record" and "error" objects is always uniform. You can best explore it by running the command with the --pretty flag.
 `
 }
 
 func (opt *Json) Run(ctx app.Context) app.Error {
-	records, err := ctx.ReadInputs(opt.File...)
+	records, errs, err := opt.readInputs(ctx)
 	if err != nil {
 		parserErrs, isParserErr := err.(app.ParserErrors)
 		if isParserErr {
-			ctx.Print(json.ToJson(nil, parserErrs.All(), opt.Pretty) + "\n")
+			ctx.Print(json.ToJson(nil, []json.FileErrors{{Path: parserErrs.Path(), Errors: parserErrs.All()}}, opt.Pretty) + "\n")
 			return nil
 		}
 		return err
@@ -40,8 +44,38 @@ func (opt *Json) Run(ctx app.Context) app.Error {
 	if nErr != nil {
 		return nErr
 	}
-	records = opt.ApplyFilter(now, records)
+	registry, rErr := ctx.ReadTagRegistry()
+	if rErr != nil {
+		return rErr
+	}
+	records = opt.ApplyFilter(now, registry, records)
 	records = opt.ApplySort(records)
-	ctx.Print(json.ToJson(records, nil, opt.Pretty) + "\n")
+	if records == nil {
+		// Otherwise, the records would be omitted in case of errors.
+		records = []klog.Record{}
+	}
+	ctx.Print(json.ToJson(records, errs, opt.Pretty) + "\n")
 	return nil
 }
+
+// readInputs returns the records, along with the errors per file. The
+// errors are only populated when parsing leniently.
+func (opt *Json) readInputs(ctx app.Context) ([]klog.Record, []json.FileErrors, app.Error) {
+	if !opt.IsLenient(ctx) {
+		records, err := ctx.ReadInputs(opt.File...)
+		return records, nil, err
+	}
+	files, err := ctx.ReadInputFiles(true, opt.File...)
+	if err != nil {
+		return nil, nil, err
+	}
+	var records []klog.Record
+	var errs []json.FileErrors
+	for _, f := range files {
+		records = append(records, f.Records...)
+		if len(f.Errors) > 0 {
+			errs = append(errs, json.FileErrors{Path: f.File.Path(), Errors: f.Errors})
+		}
+	}
+	return records, errs, nil
+}
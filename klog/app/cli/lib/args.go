@@ -6,6 +6,7 @@ import (
 	"github.com/jotaen/klog/klog/parser/reconciling"
 	"github.com/jotaen/klog/klog/service"
 	"github.com/jotaen/klog/klog/service/period"
+	"regexp"
 	"strings"
 	gotime "time"
 )
@@ -18,6 +19,14 @@ type OutputFileArgs struct {
 	File app.FileOrBookmarkName `arg:"" optional:"" type:"string" predictor:"file_or_bookmark" name:"file or bookmark" help:".klg source file (if empty the bookmark is used)"`
 }
 
+// DisplayName returns the name of a file or bookmark argument for displaying it.
+func DisplayName(fileArg app.FileOrBookmarkName) string {
+	if fileArg == "" {
+		return "(default bookmark)"
+	}
+	return string(fileArg)
+}
+
 type AtDateArgs struct {
 	Date      klog.Date `name:"date" short:"d" help:"The date of the record"`
 	Today     bool      `name:"today" help:"Use today’s date (default)"`
@@ -105,6 +114,25 @@ type DiffArgs struct {
 	Diff bool `name:"diff" short:"d" help:"Show difference between actual and should-total time"`
 }
 
+// ApplyShouldTotals determines the effective should-totals of the records,
+// if the --diff flag is set. These are the should-totals from the configured
+// schedule (for records that don’t have one of their own), reduced by the
+// absences from the configured calendars.
+func (args *DiffArgs) ApplyShouldTotals(ctx app.Context, rs ...klog.Record) app.Error {
+	if !args.Diff {
+		return nil
+	}
+	ctx.Config().ShouldTotalSchedule.Map(func(s service.ShouldTotalSchedule) {
+		s.Apply(rs...)
+	})
+	c, err := ctx.ReadCalendar()
+	if err != nil {
+		return err
+	}
+	c.Apply(rs...)
+	return nil
+}
+
 type NowArgs struct {
 	Now          bool `name:"now" short:"n" help:"Assume open ranges to be closed at this moment"`
 	hadOpenRange bool // Field only for internal use
@@ -140,13 +168,15 @@ func (args *NowArgs) GetNowWarnings() []string {
 
 type FilterArgs struct {
 	// General filters
-	Tags   []klog.Tag    `name:"tag" group:"Filter" help:"Records (or entries) that match these tags"`
-	Date   klog.Date     `name:"date" group:"Filter" help:"Records at this date"`
-	Since  klog.Date     `name:"since" group:"Filter" help:"Records since this date (inclusive)"`
-	Until  klog.Date     `name:"until" group:"Filter" help:"Records until this date (inclusive)"`
-	After  klog.Date     `name:"after" group:"Filter" help:"Records after this date (exclusive)"`
-	Before klog.Date     `name:"before" group:"Filter" help:"Records before this date (exclusive)"`
-	Period period.Period `name:"period" group:"Filter" help:"Records in period: YYYY, YYYY-MM, YYYY-Www, or YYYY-Qq"`
+	Tags   []service.TagQuery `name:"tag" group:"Filter" help:"Records (or entries) that match these tags, or tag expressions like '#a or (#b and not #c)'. Values can contain * as wildcard, e.g. '#ticket=PRJ-*' ('#ticket=*' requires a value)"`
+	Date   klog.Date          `name:"date" group:"Filter" help:"Records at this date"`
+	Since  klog.Date          `name:"since" group:"Filter" help:"Records since this date (inclusive)"`
+	Until  klog.Date          `name:"until" group:"Filter" help:"Records until this date (inclusive)"`
+	After  klog.Date          `name:"after" group:"Filter" help:"Records after this date (exclusive)"`
+	Before klog.Date          `name:"before" group:"Filter" help:"Records before this date (exclusive)"`
+	Period period.Period      `name:"period" group:"Filter" help:"Records in period: YYYY, YYYY-MM, YYYY-Www, or YYYY-Qq"`
+	Grep   string             `name:"grep" group:"Filter" help:"Records (or entries) whose summary contains this text (case-insensitive)"`
+	Match  *regexp.Regexp     `name:"match" group:"Filter" help:"Records (or entries) whose summary matches this regular expression"`
 
 	// Shortcut filters
 	// The `XXX` ones are dummy entries just for the help output
@@ -188,13 +218,17 @@ var FilterArgsCompletionOverrides = map[string]bool{
 	"last-year":    true,
 }
 
-func (args *FilterArgs) ApplyFilter(now gotime.Time, rs []klog.Record) []klog.Record {
+// ApplyFilter returns the records that match the filter flags. Tag aliases
+// are folded as per the registry.
+func (args *FilterArgs) ApplyFilter(now gotime.Time, registry service.TagRegistry, rs []klog.Record) []klog.Record {
 	today := klog.NewDateFromGo(now)
 	qry := service.FilterQry{
-		BeforeOrEqual: args.Until,
-		AfterOrEqual:  args.Since,
-		Tags:          args.Tags,
-		AtDate:        args.Date,
+		BeforeOrEqual:   args.Until,
+		AfterOrEqual:    args.Since,
+		TagQuery:        service.AllOfTagQueries(args.Tags...),
+		SummaryPatterns: args.SummaryPatterns(),
+		AtDate:          args.Date,
+		TagRegistry:     registry,
 	}
 	if args.Period != nil {
 		qry.BeforeOrEqual = args.Period.Until()
@@ -249,10 +283,42 @@ func (args *FilterArgs) ApplyFilter(now gotime.Time, rs []klog.Record) []klog.Re
 	return service.Filter(rs, qry)
 }
 
+// SummaryPatterns returns the patterns from the summary text filters.
+func (args *FilterArgs) SummaryPatterns() []*regexp.Regexp {
+	var patterns []*regexp.Regexp
+	if args.Grep != "" {
+		patterns = append(patterns, regexp.MustCompile("(?i)"+regexp.QuoteMeta(args.Grep)))
+	}
+	if args.Match != nil {
+		patterns = append(patterns, args.Match)
+	}
+	return patterns
+}
+
+// ApplyHighlight makes the serialiser highlight the text that matched the
+// summary text filters.
+func (args *FilterArgs) ApplyHighlight(ctx *app.Context) {
+	patterns := args.SummaryPatterns()
+	if len(patterns) == 0 {
+		return
+	}
+	if s, ok := (*ctx).Serialiser().(CliSerialiser); ok {
+		s.Highlight = patterns
+		(*ctx).SetSerialiser(s)
+	}
+}
+
+type DryRunArgs struct {
+	DryRun bool `name:"dry-run" help:"Don’t write the file, but print the changes that would be made"`
+}
+
 type WarnArgs struct {
 	NoWarn bool `name:"no-warn" help:"Suppress warnings about potential mistakes"`
 }
 
+// PrintWarnings prints the warnings for the records. Since the records might
+// have been filtered, it leaves out the checks that span multiple records;
+// these are only performed by `klog check`.
 func (args *WarnArgs) PrintWarnings(ctx app.Context, records []klog.Record, additionalWarnings []string) {
 	if args.NoWarn {
 		return
@@ -260,9 +326,59 @@ func (args *WarnArgs) PrintWarnings(ctx app.Context, records []klog.Record, addi
 	for _, msg := range additionalWarnings {
 		ctx.Print(PrettifyGeneralWarning(msg))
 	}
+	registry, err := ctx.ReadTagRegistry()
+	if err != nil {
+		ctx.Print(PrettifyGeneralWarning(err.Error() + ": " + err.Details()))
+		registry = service.NewEmptyTagRegistry()
+	}
+	rules := ConfiguredCheckRules(ctx)
 	service.CheckForWarnings(func(w service.Warning) {
+		if rules.Severity(w.Rule()) == service.SEVERITY_OFF {
+			return
+		}
 		ctx.Print(PrettifyWarning(w))
-	}, ctx.Now(), records)
+	}, ctx.Now(), registry, ConfiguredWorkTimeLimits(ctx).PerRecord(), records)
+}
+
+type LenientArgs struct {
+	Lenient bool `name:"lenient" help:"Skip records with syntax errors, and print the errors as warnings"`
+	Strict  bool `name:"strict" help:"Fail on syntax errors (overrides 'parsing_mode = lenient' from the config)"`
+}
+
+// IsLenient returns whether syntax errors should be tolerated, as per
+// the flags or the config. --strict always takes precedence.
+func (args *LenientArgs) IsLenient(ctx app.Context) bool {
+	if args.Strict {
+		return false
+	}
+	isLenient := args.Lenient
+	ctx.Config().LenientParsing.Map(func(l bool) {
+		isLenient = isLenient || l
+	})
+	return isLenient
+}
+
+// ReadInputs reads the records from the input files. In lenient mode, it
+// skips all records with syntax errors instead of failing. The errors are
+// printed as warnings to stderr then, so that they are neither suppressed
+// by --no-warn, nor interfere with machine-readable output.
+func (args *LenientArgs) ReadInputs(ctx app.Context, fileArgs ...app.FileOrBookmarkName) ([]klog.Record, app.Error) {
+	if !args.IsLenient(ctx) {
+		return ctx.ReadInputs(fileArgs...)
+	}
+	files, err := ctx.ReadInputFiles(true, fileArgs...)
+	if err != nil {
+		return nil, err
+	}
+	var records []klog.Record
+	for _, f := range files {
+		records = append(records, f.Records...)
+		for _, e := range f.Errors {
+			location := PrettifySource(klog.Source{Path: f.File.Path(), FirstLine: e.LineNumber()})
+			ctx.PrintToStderr(PrettifyGeneralWarning("Skipped invalid record: " + e.Title() + " (" + location + ")"))
+		}
+	}
+	return records, nil
 }
 
 type NoStyleArgs struct {
@@ -297,6 +413,27 @@ func (args *SortArgs) ApplySort(rs []klog.Record) []klog.Record {
 	return service.Sort(rs, startWithOldest)
 }
 
+type OutputArgs struct {
+	Output string `name:"output" short:"o" help:"Output format: table, json, csv, or tsv" enum:"table,json,csv,tsv" default:"table"`
+	Pretty bool   `name:"pretty" help:"Pretty-print JSON output"`
+}
+
+// IsMachineReadable checks whether the output is meant to be processed by
+// other programs, as opposed to being displayed as table in the terminal.
+func (args *OutputArgs) IsMachineReadable() bool {
+	return args.Output != "" && args.Output != "table"
+}
+
+// PrintRows prints the rows as comma-separated or tab-separated values,
+// depending on the output format. The first row is the header.
+func (args *OutputArgs) PrintRows(ctx app.Context, rows [][]string) {
+	separator := ','
+	if args.Output == "tsv" {
+		separator = '\t'
+	}
+	ctx.Print(ToDelimited(rows, separator))
+}
+
 type DecimalArgs struct {
 	Decimal bool `name:"decimal" help:"Display totals as decimal values (in minutes)"`
 }
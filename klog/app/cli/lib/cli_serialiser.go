@@ -4,14 +4,18 @@ import (
 	"github.com/jotaen/klog/klog"
 	tf "github.com/jotaen/klog/klog/app/cli/lib/terminalformat"
 	"github.com/jotaen/klog/klog/parser"
+	"github.com/jotaen/klog/klog/service"
+	"regexp"
 	"strconv"
 	"strings"
 )
 
 // CliSerialiser is a specialised parser.Serialiser implementation for the terminal.
 type CliSerialiser struct {
-	Unstyled bool // -> No colouring/styling
-	Decimal  bool // -> Decimal values rather than the canonical totals
+	Unstyled  bool                // -> No colouring/styling
+	Decimal   bool                // -> Decimal values rather than the canonical totals
+	Highlight []*regexp.Regexp    // -> Emphasise matching text in summaries
+	Tags      service.TagRegistry // -> Colour tags as specified in the registry
 }
 
 var (
@@ -21,6 +25,7 @@ var (
 	BlueLight = tf.Style{Color: "027"}
 	Subdued   = tf.Style{Color: "249"}
 	Purple    = tf.Style{Color: "213"}
+	Highlight = tf.Style{Color: "000", Background: "227"}
 )
 
 func (cs CliSerialiser) Format(s parser.Styler, t string) string {
@@ -59,12 +64,76 @@ func (cs CliSerialiser) Summary(s parser.SummaryText) string {
 	txt := s.ToString()
 	style := Subdued
 	hashStyle := style.ChangedBold(true).ChangedColor("251")
+	if len(cs.Highlight) > 0 {
+		return cs.highlightedSummary(txt, style, hashStyle)
+	}
 	txt = klog.HashTagPattern.ReplaceAllStringFunc(txt, func(h string) string {
-		return cs.formatAndRestore(hashStyle, style, h)
+		return cs.formatAndRestore(cs.tagStyle(hashStyle, h), style, h)
 	})
 	return cs.Format(style, txt)
 }
 
+// tagStyle returns the style for a tag in the summary text, which might have
+// a custom colour as per the tag registry.
+func (cs CliSerialiser) tagStyle(hashStyle tf.Style, tagText string) tf.Style {
+	t, err := klog.NewTagFromString(tagText)
+	if err != nil {
+		return hashStyle
+	}
+	info, isDeclared := cs.Tags.NearestInfo(t)
+	if !isDeclared || info.Colour == "" {
+		return hashStyle
+	}
+	return hashStyle.ChangedColor(info.Colour)
+}
+
+// highlightedSummary styles the summary text like `Summary` does, but it also
+// emphasises all text portions that match one of the highlight patterns. As
+// matches and tags might overlap, the text is styled character-wise.
+func (cs CliSerialiser) highlightedSummary(txt string, style tf.Style, hashStyle tf.Style) string {
+	if cs.Unstyled {
+		return txt
+	}
+	isTag := make([]bool, len(txt))
+	tagStyles := make([]tf.Style, len(txt))
+	for _, m := range klog.HashTagPattern.FindAllStringIndex(txt, -1) {
+		s := cs.tagStyle(hashStyle, txt[m[0]:m[1]])
+		for i := m[0]; i < m[1]; i++ {
+			isTag[i] = true
+			tagStyles[i] = s
+		}
+	}
+	isHighlighted := make([]bool, len(txt))
+	for _, p := range cs.Highlight {
+		for _, m := range p.FindAllStringIndex(txt, -1) {
+			for i := m[0]; i < m[1]; i++ {
+				isHighlighted[i] = true
+			}
+		}
+	}
+	styleAt := func(i int) tf.Style {
+		if isHighlighted[i] {
+			return Highlight.ChangedBold(isTag[i])
+		}
+		if isTag[i] {
+			return tagStyles[i]
+		}
+		return style
+	}
+	result := ""
+	segmentStart := 0
+	for i := range txt {
+		if i > 0 && styleAt(i) != styleAt(segmentStart) {
+			result += styleAt(segmentStart).Format(txt[segmentStart:i])
+			segmentStart = i
+		}
+	}
+	if segmentStart < len(txt) {
+		result += styleAt(segmentStart).Format(txt[segmentStart:])
+	}
+	return result
+}
+
 func (cs CliSerialiser) Range(r klog.Range) string {
 	return cs.Format(BlueDark, r.ToString())
 }
@@ -0,0 +1,42 @@
+package lib
+
+import (
+	"github.com/jotaen/klog/klog/app/cli/lib/terminalformat"
+	"github.com/jotaen/klog/klog/parser"
+	"github.com/jotaen/klog/klog/service"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func TestSerialiseSummaryWithHighlighting(t *testing.T) {
+	s := CliSerialiser{Highlight: []*regexp.Regexp{regexp.MustCompile("(?i)migrat")}}
+	summary := parser.SummaryText{"Database Migration for #migrations"}
+	result := s.Summary(summary)
+	assert.Equal(t, "Database Migration for #migrations", terminalformat.StripAllAnsiSequences(result))
+	assert.Equal(t, ""+
+		Subdued.Format("Database ")+
+		Highlight.Format("Migrat")+
+		Subdued.Format("ion for ")+
+		Subdued.ChangedBold(true).ChangedColor("251").Format("#")+
+		Highlight.ChangedBold(true).Format("migrat")+
+		Subdued.ChangedBold(true).ChangedColor("251").Format("ions"),
+		result)
+}
+
+func TestSerialiseSummaryWithHighlightingUnstyled(t *testing.T) {
+	s := CliSerialiser{Unstyled: true, Highlight: []*regexp.Regexp{regexp.MustCompile("foo")}}
+	assert.Equal(t, "foo #bar", s.Summary(parser.SummaryText{"foo #bar"}))
+}
+
+func TestSerialiseSummaryWithTagColours(t *testing.T) {
+	registry, _ := service.NewTagRegistryFromString("#acme\n  colour = 214\n#other\n")
+	s := CliSerialiser{Tags: registry}
+	hashStyle := Subdued.ChangedBold(true).ChangedColor("251")
+	assert.Equal(t, ""+
+		Subdued.Format("Work for "+
+			hashStyle.ChangedColor("214").FormatAndRestore("#acme/backend", Subdued)+
+			" and "+
+			hashStyle.FormatAndRestore("#other", Subdued)),
+		s.Summary(parser.SummaryText{"Work for #acme/backend and #other"}))
+}
@@ -0,0 +1,257 @@
+package lib
+
+import (
+	"fmt"
+	"github.com/jotaen/klog/klog/parser"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines that are displayed
+// around every change.
+const diffContextLines = 2
+
+type diffOp struct {
+	kind byte // ' ' = unchanged; '-' = removed; '+' = added
+	text string
+}
+
+// PrettifyDiff compares two texts line-wise and returns the differences in
+// the unified diff format. It returns an empty string if there are none.
+// Differences in the line endings are not displayed line by line.
+func PrettifyDiff(s parser.Serialiser, name string, before string, after string) string {
+	ops := diffLines(splitIntoLines(before), splitIntoLines(after))
+	hunks := groupIntoHunks(ops)
+	if before == after {
+		return ""
+	}
+	result := s.Format(Subdued, "--- "+name) + "\n"
+	result += s.Format(Subdued, "+++ "+name) + "\n"
+	if len(hunks) == 0 {
+		result += s.Format(BlueDark, "(The line endings differ)") + "\n"
+	}
+	for _, h := range hunks {
+		result += s.Format(BlueDark, h.header) + "\n"
+		for _, op := range h.ops {
+			line := string(op.kind) + op.text
+			switch op.kind {
+			case '-':
+				line = s.Format(Red, line)
+			case '+':
+				line = s.Format(Green, line)
+			}
+			result += line + "\n"
+		}
+	}
+	return result
+}
+
+// CountChangedLines returns the number of added and removed lines.
+func CountChangedLines(before string, after string) (int, int) {
+	added, removed := 0, 0
+	for _, op := range diffLines(splitIntoLines(before), splitIntoLines(after)) {
+		switch op.kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return added, removed
+}
+
+func splitIntoLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes the edit operations that turn `a` into `b`, as per
+// Myers’ difference algorithm. It only needs memory that is linear in the
+// number of lines, and its runtime mainly depends on the number of changes,
+// so large files with few changes are cheap to diff.
+func diffLines(a []string, b []string) []diffOp {
+	return appendDiff(make([]diffOp, 0, len(a)+len(b)), a, b)
+}
+
+// appendDiff appends the edit operations to `ops`. It strips the lines that
+// both texts share at the beginning and at the end, and then splits the
+// remaining window into two halves recursively.
+func appendDiff(ops []diffOp, a []string, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	for _, l := range a[:prefix] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	windowA, windowB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if x, y, ok := findMiddle(windowA, windowB); ok && x+y > 0 && x+y < len(windowA)+len(windowB) {
+		ops = appendDiff(ops, windowA[:x], windowB[:y])
+		ops = appendDiff(ops, windowA[x:], windowB[y:])
+	} else {
+		for _, l := range windowA {
+			ops = append(ops, diffOp{'-', l})
+		}
+		for _, l := range windowB {
+			ops = append(ops, diffOp{'+', l})
+		}
+	}
+	for _, l := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	return ops
+}
+
+// findMiddle finds a point on a shortest edit path from `a` to `b`, by
+// searching from both ends at the same time until the paths meet. It returns
+// `false` if there is no such point that splits the problem, i.e. if either
+// text is empty, or if the texts have no line in common.
+func findMiddle(a []string, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+	delta := n - m
+	isOddDelta := delta%2 != 0
+
+	// The diagonals that have run off the edges don’t need to be explored.
+	forwardStart, forwardEnd, backwardStart, backwardEnd := 0, 0, 0, 0
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + forwardStart; k <= d-forwardEnd; k += 2 {
+			i := offset + k
+			x := 0
+			if k == -d || (k != d && forward[i-1] < forward[i+1]) {
+				x = forward[i+1]
+			} else {
+				x = forward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[i] = x
+			if x > n {
+				forwardEnd += 2
+			} else if y > m {
+				forwardStart += 2
+			} else if isOddDelta {
+				j := offset + delta - k
+				if j >= 0 && j < len(backward) && backward[j] != -1 && x >= n-backward[j] {
+					return x, y, true
+				}
+			}
+		}
+		for k := -d + backwardStart; k <= d-backwardEnd; k += 2 {
+			i := offset + k
+			x := 0
+			if k == -d || (k != d && backward[i-1] < backward[i+1]) {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			backward[i] = x
+			if x > n {
+				backwardEnd += 2
+			} else if y > m {
+				backwardStart += 2
+			} else if !isOddDelta {
+				j := offset + delta - k
+				if j >= 0 && j < len(forward) && forward[j] != -1 {
+					forwardX := forward[j]
+					forwardY := offset + forwardX - j
+					if forwardX >= n-x {
+						return forwardX, forwardY, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+type diffHunk struct {
+	header string
+	ops    []diffOp
+}
+
+func groupIntoHunks(ops []diffOp) []diffHunk {
+	var hunks []diffHunk
+	for start := 0; start < len(ops); {
+		// Find the next change.
+		firstChange := -1
+		for k := start; k < len(ops); k++ {
+			if ops[k].kind != ' ' {
+				firstChange = k
+				break
+			}
+		}
+		if firstChange == -1 {
+			break
+		}
+
+		// Extend the hunk as long as the changes are close to each other.
+		from := firstChange - diffContextLines
+		if from < start {
+			from = start
+		}
+		to := firstChange
+		for k := firstChange; k < len(ops); k++ {
+			if ops[k].kind != ' ' {
+				to = k
+			} else if k-to > 2*diffContextLines {
+				break
+			}
+		}
+		to += diffContextLines
+		if to > len(ops)-1 {
+			to = len(ops) - 1
+		}
+
+		// Determine the line numbers in the old and new text.
+		oldStart, newStart := 1, 1
+		for k := 0; k < from; k++ {
+			if ops[k].kind != '+' {
+				oldStart++
+			}
+			if ops[k].kind != '-' {
+				newStart++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for k := from; k <= to; k++ {
+			if ops[k].kind != '+' {
+				oldCount++
+			}
+			if ops[k].kind != '-' {
+				newCount++
+			}
+		}
+		hunks = append(hunks, diffHunk{
+			header: fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, oldCount, newStart, newCount),
+			ops:    ops[from : to+1],
+		})
+		start = to + 1
+	}
+	return hunks
+}
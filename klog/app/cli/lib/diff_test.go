@@ -0,0 +1,94 @@
+package lib
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestPrintsNoDiffForIdenticalTexts(t *testing.T) {
+	assert.Equal(t, "", PrettifyDiff(CliSerialiser{Unstyled: true}, "file.klg", "a\nb\n", "a\nb\n"))
+}
+
+func TestPrintsDiffInUnifiedFormat(t *testing.T) {
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	after := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n11.5\n12\n"
+	assert.Equal(t, `--- file.klg
++++ file.klg
+@@ -1,5 +1,5 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+@@ -10,3 +10,4 @@
+ 10
+ 11
++11.5
+ 12
+`, PrettifyDiff(CliSerialiser{Unstyled: true}, "file.klg", before, after))
+}
+
+func TestMergesChangesThatAreCloseToEachOther(t *testing.T) {
+	assert.Equal(t, `--- file.klg
++++ file.klg
+@@ -1,6 +1,6 @@
+-1
++one
+ 2
+ 3
+ 4
+-5
++five
+ 6
+`, PrettifyDiff(CliSerialiser{Unstyled: true}, "file.klg", "1\n2\n3\n4\n5\n6\n", "one\n2\n3\n4\nfive\n6\n"))
+}
+
+func TestPrintsHintIfOnlyLineEndingsDiffer(t *testing.T) {
+	assert.Equal(t, `--- file.klg
++++ file.klg
+(The line endings differ)
+`, PrettifyDiff(CliSerialiser{Unstyled: true}, "file.klg", "a\r\nb\r\n", "a\nb\n"))
+}
+
+func TestDiffsLinesMinimally(t *testing.T) {
+	for _, x := range []struct {
+		before    string
+		after     string
+		unchanged int
+	}{
+		{"a\nb\nc\n", "a\nb\nc\n", 3},
+		{"", "a\nb\n", 0},
+		{"a\nb\n", "", 0},
+		{"a\nb\nc\nd\n", "a\nx\nc\nd\ny\n", 3},
+		{"x\na\nb\nc\n", "a\nb\nc\nx\n", 3},
+		{"a\nb\nc\na\nb\nb\na\n", "c\nb\na\nb\na\nc\n", 4},
+	} {
+		a, b := splitIntoLines(x.before), splitIntoLines(x.after)
+		var before, after []string
+		unchanged := 0
+		for _, op := range diffLines(a, b) {
+			if op.kind != '+' {
+				before = append(before, op.text)
+			}
+			if op.kind != '-' {
+				after = append(after, op.text)
+			}
+			if op.kind == ' ' {
+				unchanged++
+			}
+		}
+		assert.Equal(t, a, before, x.before)
+		assert.Equal(t, b, after, x.after)
+		assert.Equal(t, x.unchanged, unchanged, x.before)
+	}
+}
+
+func TestCountsChangedLinesOfLargeTexts(t *testing.T) {
+	before := strings.Repeat("2020-01-01\n    8h\n\n", 20000)
+	after := strings.Replace(before, "    8h", "    7h", 1) + "2030-01-01\n"
+	added, removed := CountChangedLines(before, after)
+	assert.Equal(t, 2, added)
+	assert.Equal(t, 1, removed)
+}
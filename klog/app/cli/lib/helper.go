@@ -1,22 +1,108 @@
 package lib
 
 import (
+	"bytes"
+	"encoding/csv"
+	"github.com/jotaen/klog/klog"
 	"github.com/jotaen/klog/klog/app"
 	"github.com/jotaen/klog/klog/parser"
 	"github.com/jotaen/klog/klog/parser/reconciling"
+	"github.com/jotaen/klog/klog/service"
 )
 
 type ReconcileOpts struct {
 	OutputFileArgs
 	WarnArgs
+	DryRunArgs
 }
 
 func Reconcile(ctx app.Context, opts ReconcileOpts, creators []reconciling.Creator, reconcile reconciling.Reconcile) app.Error {
-	result, err := ctx.ReconcileFile(opts.OutputFileArgs.File, creators, reconcile)
+	result, err := ctx.ReconcileFile(opts.OutputFileArgs.File, creators, reconcile, !opts.DryRun, false)
 	if err != nil {
 		return err
 	}
+	if opts.DryRun {
+		PrintDryRun(ctx, opts.OutputFileArgs.File, result)
+		return nil
+	}
 	ctx.Print("\n" + parser.SerialiseRecords(ctx.Serialiser(), result.Record).ToString() + "\n")
 	opts.WarnArgs.PrintWarnings(ctx, result.AllRecords, nil)
 	return nil
 }
+
+// PrintDryRun prints the changes that a reconciler would make to a file.
+func PrintDryRun(ctx app.Context, fileArg app.FileOrBookmarkName, result *reconciling.Result) {
+	diff := PrettifyDiff(ctx.Serialiser(), DisplayName(fileArg), result.Original, result.AllSerialised)
+	if diff == "" {
+		ctx.Print("The file would not be changed.\n")
+		return
+	}
+	ctx.Print(diff)
+}
+
+// ApplyTagColours makes the serialiser colour the tags in summaries as
+// specified in the tag registry.
+func ApplyTagColours(ctx *app.Context, registry service.TagRegistry) {
+	if s, ok := (*ctx).Serialiser().(CliSerialiser); ok {
+		s.Tags = registry
+		(*ctx).SetSerialiser(s)
+	}
+}
+
+// ConfiguredCheckRules returns the severities of the checks as per the config.
+func ConfiguredCheckRules(ctx app.Context) service.CheckRules {
+	rules := service.CheckRules{}
+	ctx.Config().CheckRules.Map(func(r service.CheckRules) {
+		rules = r
+	})
+	return rules
+}
+
+// ConfiguredShouldTotal returns the should-total for a new record at the given
+// date as per the config. The default should-total takes precedence over the
+// schedule. It returns nil if there is none.
+func ConfiguredShouldTotal(ctx app.Context, date klog.Date) klog.ShouldTotal {
+	var shouldTotal klog.ShouldTotal
+	ctx.Config().ShouldTotalSchedule.Map(func(s service.ShouldTotalSchedule) {
+		should := s.ShouldTotalAt(date)
+		if should != nil && should.InMinutes() != 0 {
+			shouldTotal = should
+		}
+	})
+	ctx.Config().DefaultShouldTotal.Map(func(s klog.ShouldTotal) {
+		shouldTotal = s
+	})
+	return shouldTotal
+}
+
+// ConfiguredWorkTimeLimits returns the thresholds of the working-time
+// compliance checks as per the config.
+func ConfiguredWorkTimeLimits(ctx app.Context) service.WorkTimeLimits {
+	limits := service.WorkTimeLimits{}
+	cfg := ctx.Config()
+	cfg.MaxDailyWorkTime.Map(func(d klog.Duration) {
+		limits.MaxDaily = d
+	})
+	cfg.MaxWeeklyWorkTime.Map(func(d klog.Duration) {
+		limits.MaxWeekly = d
+	})
+	cfg.MinRestPeriod.Map(func(d klog.Duration) {
+		limits.MinRest = d
+	})
+	cfg.RequiredBreaks.Map(func(bs service.BreakRequirements) {
+		limits.RequiredBreaks = bs
+	})
+	return limits
+}
+
+// ToDelimited serialises the rows as delimiter-separated values, e.g. CSV.
+func ToDelimited(rows [][]string, separator rune) string {
+	buffer := new(bytes.Buffer)
+	w := csv.NewWriter(buffer)
+	w.Comma = separator
+	for _, row := range rows {
+		_ = w.Write(row)
+	}
+	w.Flush()
+	return buffer.String()
+}
@@ -3,6 +3,7 @@ package lib
 import (
 	"errors"
 	"fmt"
+	"github.com/jotaen/klog/klog"
 	"github.com/jotaen/klog/klog/app"
 	"github.com/jotaen/klog/klog/app/cli/lib/terminalformat"
 	"github.com/jotaen/klog/klog/service"
@@ -17,11 +18,13 @@ func PrettifyError(err error, isDebug bool) error {
 	case app.ParserErrors:
 		message := ""
 		INDENT := "    "
+		path := e.Path()
 		for _, e := range e.All() {
-			message += fmt.Sprintf(
-				terminalformat.Style{Background: "160", Color: "015"}.Format(" ERROR in line %d: "),
-				e.LineNumber(),
-			) + "\n"
+			location := fmt.Sprintf("line %d", e.LineNumber())
+			if path != "" {
+				location = path + ", " + location
+			}
+			message += terminalformat.Style{Background: "160", Color: "015"}.Format(" ERROR in "+location+": ") + "\n"
 			message += fmt.Sprintf(
 				terminalformat.Style{Color: "247"}.Format(INDENT+"%s"),
 				// Replace all tabs with one space each, otherwise the carets might
@@ -50,9 +53,27 @@ func PrettifyError(err error, isDebug bool) error {
 	return errors.New("Error: " + err.Error())
 }
 
-// PrettifyWarning formats a warning about a record.
+// PrettifyWarning formats a warning about a record. If the source of the
+// record is known, the warning includes its location.
 func PrettifyWarning(w service.Warning) string {
-	return PrettifyGeneralWarning(w.Date().ToString() + ": " + w.Warning())
+	message := w.Date().ToString() + ": " + w.Warning()
+	if w.Record() != nil && w.Record().Source() != nil {
+		message += " (" + PrettifySource(*w.Record().Source()) + ")"
+	}
+	return PrettifyGeneralWarning(message)
+}
+
+// PrettifySource formats the location of a record, e.g. `times.klg:4-7`.
+func PrettifySource(s klog.Source) string {
+	path := s.Path
+	if path == "" {
+		path = "(stdin)"
+	}
+	lines := fmt.Sprint(s.FirstLine)
+	if s.LastLine > s.FirstLine {
+		lines += fmt.Sprintf("-%d", s.LastLine)
+	}
+	return path + ":" + lines
 }
 
 // PrettifyGeneralWarning formats a general warning message.
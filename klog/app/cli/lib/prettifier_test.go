@@ -2,6 +2,7 @@ package lib
 
 import (
 	"errors"
+	"github.com/jotaen/klog/klog"
 	"github.com/jotaen/klog/klog/app"
 	"github.com/jotaen/klog/klog/app/cli/lib/terminalformat"
 	"github.com/jotaen/klog/klog/parser/txt"
@@ -30,6 +31,26 @@ func TestFormatParserError(t *testing.T) {
 `, terminalformat.StripAllAnsiSequences(text))
 }
 
+func TestFormatParserErrorWithPath(t *testing.T) {
+	block, _ := txt.ParseBlock("Foo bar", 1)
+	err := app.NewParserErrorsInFile("/tmp/times.klg", []txt.Error{
+		txt.NewError(block, 0, 4, 3, "CODE", "Error", "Short explanation."),
+	})
+	text := PrettifyError(err, false).Error()
+	assert.Equal(t, ` ERROR in /tmp/times.klg, line 2: 
+    Foo bar
+        ^^^
+    Error: Short explanation.
+
+`, terminalformat.StripAllAnsiSequences(text))
+}
+
+func TestFormatSource(t *testing.T) {
+	assert.Equal(t, "/tmp/times.klg:4-7", PrettifySource(klog.Source{Path: "/tmp/times.klg", FirstLine: 4, LastLine: 7}))
+	assert.Equal(t, "/tmp/times.klg:4", PrettifySource(klog.Source{Path: "/tmp/times.klg", FirstLine: 4, LastLine: 4}))
+	assert.Equal(t, "(stdin):1-2", PrettifySource(klog.Source{Path: "", FirstLine: 1, LastLine: 2}))
+}
+
 func TestReflowsLongMessages(t *testing.T) {
 	block, _ := txt.ParseBlock("Foo bar", 1)
 	err := app.NewParserErrors([]txt.Error{
@@ -13,6 +13,7 @@ import (
 	"github.com/jotaen/klog/klog/service/period"
 	kongcompletion "github.com/jotaen/kong-completion"
 	"reflect"
+	"regexp"
 )
 
 func Run(homeDir app.File, meta app.Meta, config app.Config, args []string) error {
@@ -38,6 +39,10 @@ func Run(homeDir app.File, meta app.Meta, config app.Config, args []string) erro
 			p := period.NewPeriod(someSinceDate, someUntilDate)
 			return kong.TypeMapper(reflect.TypeOf(&p).Elem(), periodDecoder())
 		}(),
+		func() kong.Option {
+			d := klog.NewDuration(0, 0)
+			return kong.TypeMapper(reflect.TypeOf(&d).Elem(), durationDecoder())
+		}(),
 		func() kong.Option {
 			f, _ := service.NewRounding(30)
 			return kong.TypeMapper(reflect.TypeOf(&f).Elem(), roundingDecoder())
@@ -46,6 +51,13 @@ func Run(homeDir app.File, meta app.Meta, config app.Config, args []string) erro
 			t := klog.NewTagOrPanic("test", "")
 			return kong.TypeMapper(reflect.TypeOf(&t).Elem(), tagDecoder())
 		}(),
+		func() kong.Option {
+			q, _ := service.NewTagQueryFromString("#test")
+			return kong.TypeMapper(reflect.TypeOf(&q).Elem(), tagQueryDecoder())
+		}(),
+		func() kong.Option {
+			return kong.TypeMapper(reflect.TypeOf(&regexp.Regexp{}), regexpDecoder())
+		}(),
 		func() kong.Option {
 			s, _ := klog.NewRecordSummary("test")
 			return kong.TypeMapper(reflect.TypeOf(&s).Elem(), recordSummaryDecoder())
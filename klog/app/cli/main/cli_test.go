@@ -63,6 +63,204 @@ func TestWriteToFile(t *testing.T) {
 	assert.True(t, strings.Contains(out[1], "1 record"), out)
 }
 
+func TestFormatFile(t *testing.T) {
+	klog := &Env{
+		files: map[string]string{
+			"test.klg": "2020-01-01\nSome stuff\n  1h\n\n\n2020/01/02\n  9:00-10:00\n",
+		},
+	}
+	out := klog.run(
+		[]string{"fmt", "--check", "test.klg"},
+		[]string{"fmt", "test.klg"},
+		[]string{"fmt", "--check", "test.klg"},
+		[]string{"print", "test.klg"},
+	)
+	// Out 0 like: `Error: Not formatted`
+	assert.True(t, strings.Contains(out[0], "Not formatted"), out)
+	assert.Equal(t, "", out[1])
+	assert.Equal(t, "", out[2])
+	assert.True(t, strings.Contains(out[3], "2020-01-02"), out)
+}
+
+func TestDryRun(t *testing.T) {
+	klog := &Env{
+		files: map[string]string{
+			"test.klg": "2020-01-01\n\t1h\n",
+		},
+	}
+	out := klog.run(
+		[]string{"track", "--dry-run", "--date", "2020-01-01", "30m", "test.klg"},
+		[]string{"create", "--dry-run", "--date", "2020-01-02", "test.klg"},
+		[]string{"pause", "--dry-run", "test.klg"},
+		[]string{"start", "--dry-run", "--date", "2020-01-01", "--time", "9:00", "test.klg"},
+		[]string{"total", "test.klg"},
+	)
+	assert.True(t, strings.Contains(out[0], "+\t30m"), out)
+	assert.True(t, strings.Contains(out[1], "+2020-01-02"), out)
+	assert.True(t, strings.Contains(out[2], "No such record"), out)
+	assert.True(t, strings.Contains(out[3], "+\t9:00 - ?"), out)
+	assert.True(t, strings.Contains(out[4], "Total: 1h"), out)
+}
+
+func TestAmend(t *testing.T) {
+	klog := &Env{
+		files: map[string]string{
+			"test.klg": "2020-01-01\n\t9:00-10:00 Foo\n\t1h Bar\n",
+		},
+	}
+	out := klog.run(
+		[]string{"amend", "--date", "2020-01-01", "--entry", "1", "--end", "11:00", "--clear-summary", "test.klg"},
+		[]string{"amend", "--date", "2020-01-01", "--delete", "test.klg"},
+		[]string{"print", "test.klg"},
+	)
+	assert.True(t, strings.Contains(out[0], "9:00-11:00\n"), out)
+	assert.True(t, strings.Contains(out[2], "9:00-11:00"), out)
+	assert.False(t, strings.Contains(out[2], "Bar"), out)
+}
+
+func TestSortSplitAndMerge(t *testing.T) {
+	klog := &Env{
+		files: map[string]string{
+			"test.klg": "2021-03-01\n\t1h\n\n2020-05-01\n\t2h\n\n2021-01-01\n\t3h\n",
+		},
+	}
+	out := klog.run(
+		[]string{"sort", "test.klg"},
+		[]string{"split", "--by", "year", "test.klg"},
+		[]string{"print", "test-2021.klg"},
+		[]string{"split", "--by", "year", "test.klg"},
+		[]string{"merge", "test-2020.klg", "test-2021.klg"},
+		[]string{"merge", "test.klg", "test-2021.klg"},
+	)
+	assert.True(t, strings.Contains(out[0], "Sorted the records"), out)
+	assert.True(t, strings.Contains(out[1], "test-2020.klg"), out)
+	assert.True(t, strings.Contains(out[1], "test-2021.klg"), out)
+	assert.True(t, strings.Contains(out[2], "2021-01-01\n    3h\n\n2021-03-01\n    1h"), out)
+	assert.False(t, strings.Contains(out[2], "2020-05-01"), out)
+	assert.True(t, strings.Contains(out[3], "File already exists"), out)
+	assert.Equal(t, "2020-05-01\n\t2h\n\n2021-01-01\n\t3h\n\n2021-03-01\n\t1h\n", out[4])
+	assert.True(t, strings.Contains(out[5], "Duplicate records"), out)
+}
+
+func TestRetag(t *testing.T) {
+	klog := &Env{
+		files: map[string]string{
+			"test.klg": "2020-01-01\n\t1h #foo=1\n\n2020-01-02\n\t1h #foo\n",
+		},
+	}
+	out := klog.run(
+		[]string{"retag", "#foo", "#bar", "--date", "2020-01-01", "test.klg"},
+		[]string{"print", "test.klg"},
+	)
+	assert.True(t, strings.Contains(out[0], "Replaced #foo with #bar in 1 line(s)"), out)
+	assert.True(t, strings.Contains(out[1], "1h #bar=1"), out)
+	assert.True(t, strings.Contains(out[1], "1h #foo\n"), out)
+}
+
+func TestTagAliases(t *testing.T) {
+	klog := &Env{
+		files: map[string]string{
+			"tags.ini": "#meeting = #mtg\n",
+			"test.klg": "2020-01-01\n\t1h #meeting\n\t2h #mtg\n\t4h #other\n",
+		},
+	}
+	out := klog.run(
+		[]string{"total", "--tag", "meeting", "--no-warn", "test.klg"},
+		[]string{"total", "--tag", "mtg", "test.klg"},
+		[]string{"total", "test.klg"},
+	)
+	assert.True(t, strings.Contains(out[0], "Total: 3h"), out)
+	assert.True(t, strings.Contains(out[1], "Total: 3h"), out)
+	assert.False(t, strings.Contains(out[1], "Unknown tag"), out)
+	assert.True(t, strings.Contains(out[2], "Unknown tag"), out)
+}
+
+func TestCheck(t *testing.T) {
+	klog := &Env{
+		files: map[string]string{
+			"good.klg": "2020-01-01\n\t8:00-9:00\n",
+			"bad.klg":  "2020-01-01\n\t8:00-9:00\n\n2020-01-02\n\t8:00-9:00\n\t8:30-9:30\n",
+		},
+	}
+	out := klog.run(
+		[]string{"check", "good.klg"},
+		[]string{"check", "good.klg", "bad.klg"},
+		[]string{"check", "--strict", "good.klg", "bad.klg"},
+	)
+	assert.True(t, strings.Contains(out[0], "No problems found"), out)
+	assert.True(t, strings.Contains(out[1], "bad.klg:4: warning: 2020-01-02: Overlapping time ranges"), out)
+	assert.Equal(t, "Check failed", out[2])
+}
+
+func TestSourceLocations(t *testing.T) {
+	klog := &Env{
+		files: map[string]string{
+			"a.klg":   "2020-01-01\n\t1h\n",
+			"b.klg":   "\n2020-01-02\n\t8:00-9:00\n\t8:30-9:30\n",
+			"bad.klg": "2020-01-03\n\tfoo\n",
+		},
+	}
+	out := klog.run(
+		[]string{"print", "--with-source", "--no-warn", "a.klg", "b.klg"},
+		[]string{"total", "a.klg", "b.klg"},
+		[]string{"json", "b.klg"},
+		[]string{"json", "bad.klg"},
+	)
+	assert.True(t, strings.Contains(out[0], "2020-01-01  "), out)
+	assert.True(t, strings.Contains(out[0], "a.klg:1-2\n"), out)
+	assert.True(t, strings.Contains(out[0], "b.klg:2-4\n"), out)
+	assert.True(t, strings.Contains(out[1], "2020-01-02: Overlapping time ranges ("), out)
+	assert.True(t, strings.Contains(out[1], "b.klg:2-4)"), out)
+	assert.True(t, strings.Contains(out[2], `"first_line":2,"last_line":4}`), out)
+	assert.True(t, strings.Contains(out[3], `bad.klg","line":2,`), out)
+}
+
+func TestLenientParsing(t *testing.T) {
+	klog := &Env{
+		files: map[string]string{
+			"test.klg": "2020-01-01\n\t1h\n\n2020-01-02\n\tfoo\n\n2020-01-03\n\t2h\n",
+		},
+	}
+	out := klog.run(
+		[]string{"total", "test.klg"},
+		[]string{"total", "--lenient", "test.klg"},
+		[]string{"report", "--lenient", "--no-warn", "test.klg"},
+		[]string{"json", "--lenient", "test.klg"},
+	)
+	assert.True(t, strings.Contains(out[0], "1 parsing errors"), out)
+	assert.True(t, strings.Contains(out[1], "Total: 3h"), out)
+	assert.False(t, strings.Contains(out[1], "Skipped invalid record"), out) // That goes to stderr.
+	assert.True(t, strings.Contains(out[2], "3h"), out)
+	assert.True(t, strings.HasPrefix(out[3], `{"records":[{"date":"2020-01-01",`), out)
+	assert.True(t, strings.Contains(out[3], `"date":"2020-01-03",`), out)
+	assert.True(t, strings.Contains(out[3], `test.klg","line":5,`), out)
+}
+
+func TestUndoFileChanges(t *testing.T) {
+	klog := &Env{
+		files: map[string]string{
+			"test.klg": "2020-01-01\n\t1h\n",
+		},
+	}
+	out := klog.run(
+		[]string{"track", "--date", "2020-01-01", "30m", "test.klg"},
+		[]string{"track", "--date", "2020-01-01", "15m", "test.klg"},
+		[]string{"history", "test.klg"},
+		[]string{"undo", "test.klg"},
+		[]string{"total", "test.klg"},
+		[]string{"undo", "--count", "2", "test.klg"},
+		[]string{"undo", "test.klg"},
+		[]string{"total", "test.klg"},
+	)
+	// Out 2 like: ` 1  2020-01-01 12:00  +1 -0`
+	assert.Equal(t, 2, strings.Count(out[2], "+1 -0"), out)
+	assert.True(t, strings.Contains(out[3], "Reverted 1 change(s)"), out)
+	assert.True(t, strings.Contains(out[4], "1h30m"), out)
+	assert.True(t, strings.Contains(out[5], "Cannot undo"), out)
+	assert.True(t, strings.Contains(out[6], "Reverted 1 change(s)"), out)
+	assert.True(t, strings.Contains(out[7], "Total: 1h"), out)
+}
+
 func TestDecodesDate(t *testing.T) {
 	klog := &Env{
 		files: map[string]string{
@@ -109,6 +307,20 @@ func TestDecodesShouldTotal(t *testing.T) {
 	assert.True(t, strings.Contains(out[2], "5h1m!"), out)
 }
 
+func TestDecodesDuration(t *testing.T) {
+	klog := &Env{
+		files: map[string]string{
+			"test.klg": "2020-01-01 (8h!)\n\t7h",
+		},
+	}
+	out := klog.run(
+		[]string{"report", "--balance", "--initial-balance", "2x", "test.klg"},
+		[]string{"report", "--balance", "--initial-balance", "+3h", "test.klg"},
+	)
+	assert.True(t, strings.Contains(out[0], "`2x` is not a valid duration"), out)
+	assert.True(t, strings.Contains(out[1], "+2h"), out)
+}
+
 func TestDecodesPeriod(t *testing.T) {
 	klog := &Env{
 		files: map[string]string{
@@ -163,6 +375,36 @@ func TestDecodesTags(t *testing.T) {
 	assert.True(t, strings.Contains(out[4], "#bar=1"), out)
 }
 
+func TestDecodesTagExpressions(t *testing.T) {
+	klog := &Env{
+		files: map[string]string{
+			"test.klg": "2020-01-01\n#foo\n\t2h\n\n2020-01-02\n\t1h #bar=1\n\t3h #bar=2",
+		},
+	}
+	out := klog.run(
+		[]string{"total", "--tag", "#foo or #bar=1", "test.klg"},
+		[]string{"total", "--tag", "#bar and not #bar=1", "test.klg"},
+		[]string{"total", "--tag", "(#foo", "test.klg"},
+	)
+	assert.True(t, strings.Contains(out[0], "Total: 3h"), out)
+	assert.True(t, strings.Contains(out[1], "Total: 3h"), out)
+	assert.True(t, strings.Contains(out[2], "`(#foo` is not a valid tag"), out)
+}
+
+func TestDecodesRegexp(t *testing.T) {
+	klog := &Env{
+		files: map[string]string{
+			"test.klg": "2020-01-01\n\t1h Migration\n\t2h Other",
+		},
+	}
+	out := klog.run(
+		[]string{"total", "--match", "Migr(", "test.klg"},
+		[]string{"total", "--match", "^Migr", "test.klg"},
+	)
+	assert.True(t, strings.Contains(out[0], "`Migr(` is not a valid regular expression"), out)
+	assert.True(t, strings.Contains(out[1], "Total: 1h"), out)
+}
+
 func TestDecodesRecordSummary(t *testing.T) {
 	klog := &Env{
 		files: map[string]string{
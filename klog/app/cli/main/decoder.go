@@ -7,6 +7,7 @@ import (
 	"github.com/jotaen/klog/klog/service"
 	"github.com/jotaen/klog/klog/service/period"
 	"reflect"
+	"regexp"
 	"strings"
 )
 
@@ -66,6 +67,24 @@ func shouldTotalDecoder() kong.MapperFunc {
 	}
 }
 
+func durationDecoder() kong.MapperFunc {
+	return func(ctx *kong.DecodeContext, target reflect.Value) error {
+		var value string
+		if err := ctx.Scan.PopValueInto("duration", &value); err != nil {
+			return err
+		}
+		if value == "" {
+			return errors.New("Please provide a valid duration")
+		}
+		d, err := klog.NewDurationFromString(value)
+		if err != nil {
+			return errors.New("`" + value + "` is not a valid duration")
+		}
+		target.Set(reflect.ValueOf(d))
+		return nil
+	}
+}
+
 func periodDecoder() kong.MapperFunc {
 	return func(ctx *kong.DecodeContext, target reflect.Value) error {
 		var value string
@@ -120,6 +139,42 @@ func tagDecoder() kong.MapperFunc {
 	}
 }
 
+func tagQueryDecoder() kong.MapperFunc {
+	return func(ctx *kong.DecodeContext, target reflect.Value) error {
+		var value string
+		if err := ctx.Scan.PopValueInto("tag", &value); err != nil {
+			return err
+		}
+		if value == "" {
+			return errors.New("Please provide a valid tag or tag expression")
+		}
+		q, err := service.NewTagQueryFromString(value)
+		if err != nil {
+			return errors.New("`" + value + "` is not a valid tag or tag expression")
+		}
+		target.Set(reflect.ValueOf(q))
+		return nil
+	}
+}
+
+func regexpDecoder() kong.MapperFunc {
+	return func(ctx *kong.DecodeContext, target reflect.Value) error {
+		var value string
+		if err := ctx.Scan.PopValueInto("regexp", &value); err != nil {
+			return err
+		}
+		if value == "" {
+			return errors.New("Please provide a valid regular expression")
+		}
+		r, err := regexp.Compile(value)
+		if err != nil {
+			return errors.New("`" + value + "` is not a valid regular expression")
+		}
+		target.Set(reflect.ValueOf(r))
+		return nil
+	}
+}
+
 func recordSummaryDecoder() kong.MapperFunc {
 	return func(ctx *kong.DecodeContext, target reflect.Value) error {
 		var value string
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"errors"
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/app"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/jotaen/klog/klog/parser"
+	"github.com/jotaen/klog/klog/parser/reconciling"
+	"strings"
+)
+
+type Merge struct {
+	Into            app.FileOrBookmarkName   `name:"into" type:"string" predictor:"file_or_bookmark" help:"Merge the records into this file, instead of printing them"`
+	AllowDuplicates bool                     `name:"allow-duplicates" help:"Proceed even if there are records with the same date in more than one file"`
+	File            []app.FileOrBookmarkName `arg:"" type:"string" predictor:"file_or_bookmark" name:"file or bookmark" help:".klg source file(s)"`
+	lib.NoStyleArgs
+	lib.DryRunArgs
+}
+
+func (opt *Merge) Help() string {
+	return `It combines the records of multiple files, sorted by date (oldest first). The text of every record stays exactly as it is.
+
+By default, the result is printed. With --into, the records are merged into that file instead, along with the records that are already in there. The source files are not modified.
+
+If there are records with the same date in more than one file, it aborts, because that usually indicates that a file was merged already. With --allow-duplicates, it proceeds anyway.`
+}
+
+func (opt *Merge) Run(ctx app.Context) app.Error {
+	opt.NoStyleArgs.Apply(&ctx)
+	var inputs []reconciling.MergeInput
+	for _, f := range opt.File {
+		source, err := ctx.RetrieveTargetFile(f)
+		if err != nil {
+			return err
+		}
+		records, blocks, errs := parser.NewSerialParser().Parse(source.Contents())
+		if errs != nil {
+			return app.NewParserErrorsInFile(source.Path(), errs)
+		}
+		inputs = append(inputs, reconciling.MergeInput{Records: records, Blocks: blocks})
+	}
+
+	var collisions []klog.Date
+	check := func(cs []klog.Date) error {
+		if len(cs) > 0 && !opt.AllowDuplicates {
+			collisions = cs
+			return errors.New("Duplicate records")
+		}
+		return nil
+	}
+	newCollisionError := func() app.Error {
+		var dates []string
+		for _, d := range collisions {
+			dates = append(dates, d.ToString())
+		}
+		return app.NewErrorWithCode(
+			app.LOGICAL_ERROR,
+			"Duplicate records",
+			"There are records with the same date in more than one file: "+strings.Join(dates, ", ")+"\n"+
+				"Use --allow-duplicates to merge them anyway",
+			nil,
+		)
+	}
+
+	if opt.Into == "" {
+		text, cs, err := reconciling.Merge(inputs)
+		if err != nil {
+			return app.NewErrorWithCode(app.LOGICAL_ERROR, "Merging failed", err.Error(), err)
+		}
+		if check(cs) != nil {
+			return newCollisionError()
+		}
+		ctx.Print(text)
+		return nil
+	}
+
+	result, err := ctx.FormatFile(opt.Into, reconciling.NewMerger(inputs, check), !opt.DryRun)
+	if len(collisions) > 0 {
+		return newCollisionError()
+	}
+	if err != nil {
+		return err
+	}
+	if opt.DryRun {
+		if !result.HasChanged() {
+			ctx.Print("The file would not be changed.\n")
+			return nil
+		}
+		ctx.Print(lib.PrettifyDiff(ctx.Serialiser(), lib.DisplayName(opt.Into), result.Original, result.Formatted))
+		return nil
+	}
+	ctx.Print("Merged the records into " + lib.DisplayName(opt.Into) + "\n")
+	return nil
+}
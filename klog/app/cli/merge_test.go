@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"github.com/jotaen/klog/klog/app"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMergesFilesAndPrintsResult(t *testing.T) {
+	state, err := NewTestingContext().
+		_SetFile("/tmp/a.klg", "2020-01-03\n\n2020-01-01\n\t1h\n").
+		_SetFile("/tmp/b.klg", "2020-01-02\nFoo\n").
+		_Run((&Merge{File: []app.FileOrBookmarkName{"/tmp/a.klg", "/tmp/b.klg"}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\n2020-01-01\n\t1h\n\n2020-01-02\nFoo\n\n2020-01-03\n", state.printBuffer)
+	assert.Equal(t, "", state.writtenFileContents)
+}
+
+func TestMergesFilesIntoTarget(t *testing.T) {
+	state, err := NewTestingContext().
+		_SetRecords("2020-01-02\n\t2h\n").
+		_SetFile("/tmp/a.klg", "2020-01-01\n\t1h\n").
+		_Run((&Merge{Into: "/tmp/target.klg", File: []app.FileOrBookmarkName{"/tmp/a.klg"}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\nMerged the records into /tmp/target.klg\n", state.printBuffer)
+	assert.Equal(t, "2020-01-01\n\t1h\n\n2020-01-02\n\t2h\n", state.writtenFileContents)
+}
+
+func TestMergeAbortsOnDuplicateRecords(t *testing.T) {
+	ctx := NewTestingContext().
+		_SetRecords("2020-01-02\n\t2h\n").
+		_SetFile("/tmp/a.klg", "2020-01-01\n\n2020-01-02\n")
+
+	state, err := ctx._Run((&Merge{Into: "/tmp/target.klg", File: []app.FileOrBookmarkName{"/tmp/a.klg"}}).Run)
+	require.Error(t, err)
+	assert.Equal(t, "Duplicate records", err.Error())
+	assert.Contains(t, err.Details(), "2020-01-02")
+	assert.Equal(t, "", state.writtenFileContents)
+
+	state, err = ctx._Run((&Merge{Into: "/tmp/target.klg", AllowDuplicates: true, File: []app.FileOrBookmarkName{"/tmp/a.klg"}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "2020-01-01\n\n2020-01-02\n\t2h\n\n2020-01-02\n", state.writtenFileContents)
+}
+
+func TestMergeIntoTargetWithDryRun(t *testing.T) {
+	state, err := NewTestingContext().
+		_SetRecords("2020-01-02\n").
+		_SetFile("/tmp/a.klg", "2020-01-01\n").
+		_Run((&Merge{Into: "/tmp/target.klg", DryRunArgs: lib.DryRunArgs{DryRun: true}, File: []app.FileOrBookmarkName{"/tmp/a.klg"}}).Run)
+	require.Nil(t, err)
+	assert.Contains(t, state.printBuffer, "+2020-01-01")
+	assert.Equal(t, "", state.writtenFileContents)
+}
@@ -17,34 +17,28 @@ type Pause struct {
 	lib.OutputFileArgs
 	lib.NoStyleArgs
 	lib.WarnArgs
+	lib.DryRunArgs
 }
 
 func (opt *Pause) Help() string {
 	return `Creates a pause entry for a record with an open time range.
 The command is blocking – it keeps updating the pause entry until the process is exited.
 (The file will be written into once per minute.)
+The entire pause can be reverted with a single 'klog undo'.
+
+With --dry-run, it only prints how the pause entry would be added initially, and exits right away.
 `
 }
 
 func (opt *Pause) Run(ctx app.Context) app.Error {
 	opt.NoStyleArgs.Apply(&ctx)
 	today := klog.NewDateFromGo(ctx.Now())
-	doReconcile := func(reconcile reconciling.Reconcile) (*reconciling.Result, app.Error) {
-		return ctx.ReconcileFile(
-			opt.OutputFileArgs.File,
-			[]reconciling.Creator{
-				reconciling.NewReconcilerAtRecord(today),
-				reconciling.NewReconcilerAtRecord(today.PlusDays(-1)),
-			},
-			reconcile,
-		)
-	}
 
 	// Initial run:
 	// Ensure that an open range exists, and set up the pause entry:
 	// - Without `--extend`, append a new entry, including the summary
 	// - With `--extend`, find a pause and append the summary
-	lastResult, err := doReconcile(func(reconciler *reconciling.Reconciler) (*reconciling.Result, error) {
+	lastResult, err := opt.reconcile(ctx, today, false, func(reconciler *reconciling.Reconciler) (*reconciling.Result, error) {
 		if opt.Extend {
 			return reconciler.ExtendPause(klog.NewDuration(0, 0), opt.Summary)
 		}
@@ -53,6 +47,10 @@ func (opt *Pause) Run(ctx app.Context) app.Error {
 	if err != nil {
 		return err
 	}
+	if opt.DryRun {
+		lib.PrintDryRun(ctx, opt.OutputFileArgs.File, lastResult)
+		return nil
+	}
 
 	// Subsequent runs:
 	// We don’t rely on the accumulated counter, because then it might also accumulate
@@ -70,7 +68,7 @@ func (opt *Pause) Run(ctx app.Context) app.Error {
 			ctx.Print("\n")
 		})
 		if uncapturedIncrement > 0 {
-			lastResult, err = doReconcile(func(reconciler *reconciling.Reconciler) (*reconciling.Result, error) {
+			lastResult, err = opt.reconcile(ctx, today, true, func(reconciler *reconciling.Reconciler) (*reconciling.Result, error) {
 				// Don’t add the summary here, as we already appended it in the initial run.
 				return reconciler.ExtendPause(klog.NewDuration(0, -1*uncapturedIncrement), nil)
 			})
@@ -99,6 +97,22 @@ func (opt *Pause) Run(ctx app.Context) app.Error {
 	})
 }
 
+// reconcile applies a change to the pause entry. All subsequent changes are
+// merged into the journal entry of the initial one, so that the entire pause
+// can be undone at once.
+func (opt *Pause) reconcile(ctx app.Context, today klog.Date, isSubsequent bool, reconcile reconciling.Reconcile) (*reconciling.Result, app.Error) {
+	return ctx.ReconcileFile(
+		opt.OutputFileArgs.File,
+		[]reconciling.Creator{
+			reconciling.NewReconcilerAtRecord(today),
+			reconciling.NewReconcilerAtRecord(today.PlusDays(-1)),
+		},
+		reconcile,
+		!opt.DryRun,
+		isSubsequent,
+	)
+}
+
 // diffInMinutes computes the “wall-clock” difference between two times.
 // Note, the built-in `Time.Sub` function computes the difference of the
 // underlying monotonic time counter, which would yield incorrect results
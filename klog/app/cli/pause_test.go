@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/jotaen/klog/klog/parser/reconciling"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPauseIsUndoneAtOnce(t *testing.T) {
+	original := `
+2020-01-01
+	1h
+
+2020-01-02
+	8:00 - ?
+`
+	ctx := NewTestingContext()._SetRecords(original)._SetNow(2020, 1, 2, 10, 0)
+	opt := &Pause{OutputFileArgs: lib.OutputFileArgs{File: "/tmp/test.klg"}}
+	today := klog.Ɀ_Date_(2020, 1, 2)
+
+	_, err := opt.reconcile(&ctx, today, false, func(reconciler *reconciling.Reconciler) (*reconciling.Result, error) {
+		return reconciler.AppendPause(nil)
+	})
+	require.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = opt.reconcile(&ctx, today, true, func(reconciler *reconciling.Reconciler) (*reconciling.Result, error) {
+			return reconciler.ExtendPause(klog.NewDuration(0, -1), nil)
+		})
+		require.Nil(t, err)
+	}
+	assert.Equal(t, `
+2020-01-01
+	1h
+
+2020-01-02
+	8:00 - ?
+	-3m
+`, ctx.writtenFileContents)
+
+	reverted, uErr := ctx.UndoFile("/tmp/test.klg", 1)
+	require.Nil(t, uErr)
+	assert.Len(t, reverted, 1)
+	assert.Equal(t, original, ctx.writtenFileContents)
+}
@@ -12,9 +12,11 @@ import (
 
 type Print struct {
 	WithTotals bool `name:"with-totals" help:"Amend output with evaluated total times"`
+	WithSource bool `name:"with-source" help:"Amend output with the file and lines of each record"`
 	lib.FilterArgs
 	lib.SortArgs
 	lib.WarnArgs
+	lib.LenientArgs
 	lib.NoStyleArgs
 	lib.InputFilesArgs
 }
@@ -25,17 +27,26 @@ func (opt *Print) Help() string {
 
 func (opt *Print) Run(ctx app.Context) app.Error {
 	opt.NoStyleArgs.Apply(&ctx)
-	records, err := ctx.ReadInputs(opt.File...)
+	opt.FilterArgs.ApplyHighlight(&ctx)
+	records, err := opt.LenientArgs.ReadInputs(ctx, opt.File...)
 	if err != nil {
 		return err
 	}
 	now := ctx.Now()
-	records = opt.ApplyFilter(now, records)
+	registry, rErr := ctx.ReadTagRegistry()
+	if rErr != nil {
+		return rErr
+	}
+	lib.ApplyTagColours(&ctx, registry)
+	records = opt.ApplyFilter(now, registry, records)
 	if len(records) == 0 {
 		return nil
 	}
 	records = opt.ApplySort(records)
 	serialisedRecords := parser.SerialiseRecords(ctx.Serialiser(), records...)
+	if opt.WithSource {
+		appendSources(ctx.Serialiser(), serialisedRecords)
+	}
 	output := func() string {
 		if opt.WithTotals {
 			return printWithDurations(ctx.Serialiser(), serialisedRecords)
@@ -48,6 +59,17 @@ func (opt *Print) Run(ctx app.Context) app.Error {
 	return nil
 }
 
+// appendSources appends the source of the record to the headline of every record.
+func appendSources(serialiser parser.Serialiser, ls parser.Lines) {
+	var previousRecord klog.Record
+	for i, l := range ls {
+		if l.Record != nil && l.Record != previousRecord && l.Record.Source() != nil {
+			ls[i].Text += "  " + serialiser.Format(terminalformat.Style{Color: "247"}, lib.PrettifySource(*l.Record.Source()))
+		}
+		previousRecord = l.Record
+	}
+}
+
 func printWithDurations(serialiser parser.Serialiser, ls parser.Lines) string {
 	type Prefix struct {
 		d     klog.Duration
@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"github.com/jotaen/klog/klog/app"
 	"github.com/jotaen/klog/klog/app/cli/lib"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"strings"
 	"testing"
 )
 
@@ -37,6 +39,35 @@ Hello #world
 `, state.printBuffer)
 }
 
+func TestPrintOutRecordsWithSource(t *testing.T) {
+	state, err := NewTestingContext()._SetFile("/tmp/a.klg", `
+2018-01-31
+Hello #world
+    1h
+
+2018-02-01
+    2h
+`)._SetFile("/tmp/b.klg", `2018-02-02
+    3h
+`)._Run((&Print{
+		WithSource:     true,
+		InputFilesArgs: lib.InputFilesArgs{File: []app.FileOrBookmarkName{"/tmp/a.klg", "/tmp/b.klg"}},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+2018-01-31  /tmp/a.klg:2-4
+Hello #world
+    1h
+
+2018-02-01  /tmp/a.klg:6-7
+    2h
+
+2018-02-02  /tmp/b.klg:1-2
+    3h
+
+`, state.printBuffer)
+}
+
 func TestPrintOutRecordInCanonicalFormat(t *testing.T) {
 	state, err := NewTestingContext()._SetRecords(`
 2018-01-31
@@ -117,3 +148,44 @@ Hello #world
 
 `, state.printBuffer)
 }
+
+func TestPrintFilteredBySummaryText(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+2018-01-30
+    1h Database migration
+
+2018-01-31
+Migration day
+    2h
+    3h
+
+2018-02-01
+    4h Something else
+`)._Run((&Print{
+		FilterArgs: lib.FilterArgs{Grep: "MIGRATION"},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+2018-01-30
+    1h Database migration
+
+2018-01-31
+Migration day
+    2h
+    3h
+
+`, state.printBuffer)
+}
+
+func TestPrintLenientlyWithOnlyInvalidRecords(t *testing.T) {
+	state, err := NewTestingContext()._SetFile("/tmp/a.klg", `
+2018-01-31
+    foo
+`)._Run((&Print{
+		LenientArgs:    lib.LenientArgs{Lenient: true},
+		InputFilesArgs: lib.InputFilesArgs{File: []app.FileOrBookmarkName{"/tmp/a.klg"}},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "", state.printBuffer)
+	assert.True(t, strings.Contains(state.stderrBuffer, "Skipped invalid record: Malformed entry (/tmp/a.klg:3)"), state.stderrBuffer)
+}
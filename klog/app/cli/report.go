@@ -6,19 +6,25 @@ import (
 	"github.com/jotaen/klog/klog/app/cli/lib"
 	"github.com/jotaen/klog/klog/app/cli/lib/terminalformat"
 	"github.com/jotaen/klog/klog/app/cli/report"
+	"github.com/jotaen/klog/klog/parser/json"
 	"github.com/jotaen/klog/klog/service"
 	"github.com/jotaen/klog/klog/service/period"
+	"strconv"
 	"strings"
 )
 
 type Report struct {
-	AggregateBy string `name:"aggregate" short:"a" help:"Aggregate data by: day, week, month, quarter, year" enum:"DAY,day,d,WEEK,week,w,MONTH,month,m,QUARTER,quarter,q,YEAR,year,y," default:"day"`
-	Fill        bool   `name:"fill" short:"f" help:"Fill the gaps and show a consecutive stream"`
+	AggregateBy    string        `name:"aggregate" short:"a" help:"Aggregate data by: day, week, month, quarter, year" enum:"DAY,day,d,WEEK,week,w,MONTH,month,m,QUARTER,quarter,q,YEAR,year,y," default:"day"`
+	Fill           bool          `name:"fill" short:"f" help:"Fill the gaps and show a consecutive stream"`
+	Balance        bool          `name:"balance" short:"b" help:"Show running balance of the differences (implies --diff)"`
+	InitialBalance klog.Duration `name:"initial-balance" placeholder:"DURATION" help:"Balance to start from (overrides the config)"`
 	lib.DiffArgs
 	lib.FilterArgs
 	lib.NowArgs
+	lib.OutputArgs
 	lib.DecimalArgs
 	lib.WarnArgs
+	lib.LenientArgs
 	lib.NoStyleArgs
 	lib.InputFilesArgs
 }
@@ -26,41 +32,94 @@ type Report struct {
 func (opt *Report) Help() string {
 	return `It aggregates the totals by period, and prints the respective values from oldest to latest.
 
-The default aggregation is by day, but you choose other periods via the --aggregate flag.`
+The default aggregation is by day, but you choose other periods via the --aggregate flag.
+
+The running balance (--balance) is the cumulative sum of the differences, starting from the initial balance. The latter can be specified in the config file, or via --initial-balance. Since the balance only takes into account what is displayed, you can use the filter flags to see the balance at a certain point in time, e.g. by using --until.
+
+If calendar files are configured, the --fill flag also displays the absences (e.g. public holidays) in the respective periods.
+
+With --output json, csv, or tsv, the report is printed in a machine-readable format. That always includes the should-total times, the differences, and the running balance. For csv and tsv, the first line is a header with the column names; the grand total is not included.`
 }
 
 func (opt *Report) Run(ctx app.Context) app.Error {
+	if opt.Balance || opt.IsMachineReadable() {
+		opt.Diff = true
+	}
 	opt.DecimalArgs.Apply(&ctx)
 	opt.NoStyleArgs.Apply(&ctx)
-	records, err := ctx.ReadInputs(opt.File...)
+	records, err := opt.LenientArgs.ReadInputs(ctx, opt.File...)
 	if err != nil {
 		return err
 	}
 	now := ctx.Now()
-	records = opt.ApplyFilter(now, records)
+	registry, rErr := ctx.ReadTagRegistry()
+	if rErr != nil {
+		return rErr
+	}
+	records = opt.ApplyFilter(now, registry, records)
+	aggregator := opt.findAggregator()
 	if len(records) == 0 {
+		if opt.IsMachineReadable() {
+			opt.printMachineReadable(ctx, opt.toReportEnvelop(ctx, aggregator, nil, nil, nil, nil))
+		}
 		return nil
 	}
 	nErr := opt.ApplyNow(now, records...)
 	if nErr != nil {
 		return nErr
 	}
+	sErr := opt.ApplyShouldTotals(ctx, records...)
+	if sErr != nil {
+		return sErr
+	}
 	records = service.Sort(records, true)
-	aggregator := opt.findAggregator()
 	recordGroups, dates := groupByDate(aggregator.DateHash, records)
-	if opt.Fill {
-		dates = allDatesRange(records[0].Date(), records[len(records)-1].Date())
+	labels := make(map[period.Hash][]string)
+	hasLabel := make(map[period.Hash]map[string]bool)
+	if opt.Fill || opt.IsMachineReadable() {
+		allDates := allDatesRange(records[0].Date(), records[len(records)-1].Date())
+		if opt.Fill {
+			dates = allDates
+		}
+		c, cErr := ctx.ReadCalendar()
+		if cErr != nil {
+			return cErr
+		}
+		for _, date := range allDates {
+			label := c.Label(date)
+			hash := aggregator.DateHash(date)
+			if label == "" || hasLabel[hash][label] {
+				continue
+			}
+			if hasLabel[hash] == nil {
+				hasLabel[hash] = make(map[string]bool)
+			}
+			hasLabel[hash][label] = true
+			labels[hash] = append(labels[hash], label)
+		}
 	}
+	if opt.IsMachineReadable() {
+		opt.printMachineReadable(ctx, opt.toReportEnvelop(ctx, aggregator, records, recordGroups, dates, labels))
+		return nil
+	}
+	hasLabels := len(labels) > 0
 
 	// Table setup
 	numberOfValueColumns := func() int {
+		if opt.Balance {
+			return 4
+		}
 		if opt.Diff {
 			return 3
 		}
 		return 1
 	}()
+	numberOfLabelColumns := 0
+	if hasLabels {
+		numberOfLabelColumns = 1
+	}
 	table := terminalformat.NewTable(
-		aggregator.NumberOfPrefixColumns()+numberOfValueColumns,
+		aggregator.NumberOfPrefixColumns()+numberOfValueColumns+numberOfLabelColumns,
 		" ",
 	)
 
@@ -70,8 +129,13 @@ func (opt *Report) Run(ctx app.Context) app.Error {
 	if opt.Diff {
 		table.CellR("   Should").CellR("    Diff")
 	}
+	if opt.Balance {
+		table.CellR(" Balance")
+	}
+	table.Skip(numberOfLabelColumns)
 
 	// Rows
+	balance := opt.initialBalance(ctx.Config())
 	hashesAlreadyProcessed := make(map[period.Hash]bool)
 	for _, date := range dates {
 		hash := aggregator.DateHash(date)
@@ -83,16 +147,22 @@ func (opt *Report) Run(ctx app.Context) app.Error {
 		rs := recordGroups[hash]
 		if len(rs) == 0 {
 			table.Skip(numberOfValueColumns)
-			continue
-		}
-
-		total := service.Total(rs...)
-		table.CellR(ctx.Serialiser().Duration(total))
+		} else {
+			total := service.Total(rs...)
+			table.CellR(ctx.Serialiser().Duration(total))
 
-		if opt.Diff {
-			should := service.ShouldTotalSum(rs...)
-			diff := service.Diff(should, total)
-			table.CellR(ctx.Serialiser().ShouldTotal(should)).CellR(ctx.Serialiser().SignedDuration(diff))
+			if opt.Diff {
+				should := service.ShouldTotalSum(rs...)
+				diff := service.Diff(should, total)
+				table.CellR(ctx.Serialiser().ShouldTotal(should)).CellR(ctx.Serialiser().SignedDuration(diff))
+				balance = balance.Plus(diff)
+			}
+			if opt.Balance {
+				table.CellR(ctx.Serialiser().SignedDuration(balance))
+			}
+		}
+		if hasLabels {
+			table.CellL(" " + ctx.Serialiser().Format(terminalformat.Style{Color: "247"}, strings.Join(labels[hash], ", ")))
 		}
 	}
 
@@ -101,6 +171,10 @@ func (opt *Report) Run(ctx app.Context) app.Error {
 	if opt.Diff {
 		table.Fill("=").Fill("=")
 	}
+	if opt.Balance {
+		table.Fill("=")
+	}
+	table.Skip(numberOfLabelColumns)
 	grandTotal := service.Total(records...)
 
 	// Footer
@@ -111,12 +185,77 @@ func (opt *Report) Run(ctx app.Context) app.Error {
 		grandDiff := service.Diff(grandShould, grandTotal)
 		table.CellR(ctx.Serialiser().ShouldTotal(grandShould)).CellR(ctx.Serialiser().SignedDuration(grandDiff))
 	}
+	if opt.Balance {
+		table.CellR(ctx.Serialiser().SignedDuration(balance))
+	}
+	table.Skip(numberOfLabelColumns)
 
 	table.Collect(ctx.Print)
 	opt.WarnArgs.PrintWarnings(ctx, records, opt.GetNowWarnings())
 	return nil
 }
 
+func (opt *Report) toReportEnvelop(ctx app.Context, aggregator report.Aggregator, records []klog.Record, recordGroups map[period.Hash][]klog.Record, dates []klog.Date, labels map[period.Hash][]string) json.ReportEnvelop {
+	envelop := json.ReportEnvelop{AggregateBy: aggregator.Name()}
+	balance := opt.initialBalance(ctx.Config())
+	hashesAlreadyProcessed := make(map[period.Hash]bool)
+	for _, date := range dates {
+		hash := aggregator.DateHash(date)
+		if hashesAlreadyProcessed[hash] {
+			continue
+		}
+		hashesAlreadyProcessed[hash] = true
+		rs := recordGroups[hash]
+		total := service.Total(rs...)
+		should := service.ShouldTotalSum(rs...)
+		diff := service.Diff(should, total)
+		balance = balance.Plus(diff)
+		name, p := aggregator.Period(date)
+		envelop.Periods = append(envelop.Periods, json.ReportPeriodView{
+			Period:      name,
+			Since:       p.Since().ToString(),
+			Until:       p.Until().ToString(),
+			BalanceView: json.NewBalanceView(total, should, diff, balance),
+			Labels:      labels[hash],
+		})
+	}
+	grandTotal := service.Total(records...)
+	grandShould := service.ShouldTotalSum(records...)
+	envelop.Total = json.NewBalanceView(grandTotal, grandShould, service.Diff(grandShould, grandTotal), balance)
+	return envelop
+}
+
+func (opt *Report) printMachineReadable(ctx app.Context, envelop json.ReportEnvelop) {
+	if opt.Output == "json" {
+		ctx.Print(json.ReportToJson(envelop, opt.Pretty) + "\n")
+		return
+	}
+	rows := [][]string{{
+		"period", "since", "until",
+		"total", "total_mins", "should_total", "should_total_mins", "diff", "diff_mins", "balance", "balance_mins",
+		"labels",
+	}}
+	for _, p := range envelop.Periods {
+		rows = append(rows, []string{
+			p.Period, p.Since, p.Until,
+			p.Total, strconv.Itoa(p.TotalMins), p.ShouldTotal, strconv.Itoa(p.ShouldTotalMins), p.Diff, strconv.Itoa(p.DiffMins), p.Balance, strconv.Itoa(p.BalanceMins),
+			strings.Join(p.Labels, "; "),
+		})
+	}
+	opt.PrintRows(ctx, rows)
+}
+
+func (opt *Report) initialBalance(config app.Config) klog.Duration {
+	if opt.InitialBalance != nil {
+		return klog.NewDuration(0, opt.InitialBalance.InMinutes())
+	}
+	result := klog.NewDuration(0, 0)
+	config.InitialBalance.Map(func(d klog.Duration) {
+		result = d
+	})
+	return result
+}
+
 func (opt *Report) findAggregator() report.Aggregator {
 	category := (func() string {
 		if opt.AggregateBy == "" {
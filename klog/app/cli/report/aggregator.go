@@ -14,4 +14,11 @@ type Aggregator interface {
 	DateHash(klog.Date) period.Hash
 	OnHeaderPrefix(*terminalformat.Table)
 	OnRowPrefix(*terminalformat.Table, klog.Date)
+
+	// Name returns the name of the aggregation category, e.g. `week`.
+	Name() string
+
+	// Period returns the period that the date belongs to, along with an
+	// identifier in the format of the `--period` filter (e.g. `2020-W05`).
+	Period(klog.Date) (string, period.Period)
 }
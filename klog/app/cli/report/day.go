@@ -21,6 +21,15 @@ func (a *dayAggregator) NumberOfPrefixColumns() int {
 	return 4
 }
 
+func (a *dayAggregator) Name() string {
+	return "day"
+}
+
+func (a *dayAggregator) Period(date klog.Date) (string, period.Period) {
+	day, _ := klog.NewDate(date.Year(), date.Month(), date.Day()) // Normalise the date format
+	return day.ToString(), period.NewPeriod(day, day)
+}
+
 func (a *dayAggregator) DateHash(date klog.Date) period.Hash {
 	return period.Hash(period.NewDayFromDate(date).Hash())
 }
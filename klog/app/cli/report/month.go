@@ -20,6 +20,14 @@ func (a *monthAggregator) NumberOfPrefixColumns() int {
 	return 2
 }
 
+func (a *monthAggregator) Name() string {
+	return "month"
+}
+
+func (a *monthAggregator) Period(date klog.Date) (string, period.Period) {
+	return fmt.Sprintf("%04d-%02d", date.Year(), date.Month()), period.NewMonthFromDate(date).Period()
+}
+
 func (a *monthAggregator) DateHash(date klog.Date) period.Hash {
 	return period.Hash(period.NewMonthFromDate(date).Hash())
 }
@@ -19,6 +19,14 @@ func (a *quarterAggregator) NumberOfPrefixColumns() int {
 	return 2
 }
 
+func (a *quarterAggregator) Name() string {
+	return "quarter"
+}
+
+func (a *quarterAggregator) Period(date klog.Date) (string, period.Period) {
+	return fmt.Sprintf("%04d-Q%d", date.Year(), date.Quarter()), period.NewQuarterFromDate(date).Period()
+}
+
 func (a *quarterAggregator) DateHash(date klog.Date) period.Hash {
 	return period.Hash(period.NewQuarterFromDate(date).Hash())
 }
@@ -19,6 +19,15 @@ func (a *weekAggregator) NumberOfPrefixColumns() int {
 	return 2
 }
 
+func (a *weekAggregator) Name() string {
+	return "week"
+}
+
+func (a *weekAggregator) Period(date klog.Date) (string, period.Period) {
+	year, week := date.WeekNumber()
+	return fmt.Sprintf("%04d-W%02d", year, week), period.NewWeekFromDate(date).Period()
+}
+
 func (a *weekAggregator) DateHash(date klog.Date) period.Hash {
 	return period.Hash(period.NewWeekFromDate(date).Hash())
 }
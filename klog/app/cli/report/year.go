@@ -17,6 +17,14 @@ func (a *yearAggregator) NumberOfPrefixColumns() int {
 	return 1
 }
 
+func (a *yearAggregator) Name() string {
+	return "year"
+}
+
+func (a *yearAggregator) Period(date klog.Date) (string, period.Period) {
+	return fmt.Sprintf("%04d", date.Year()), period.NewYearFromDate(date).Period()
+}
+
 func (a *yearAggregator) DateHash(date klog.Date) period.Hash {
 	return period.Hash(period.NewYearFromDate(date).Hash())
 }
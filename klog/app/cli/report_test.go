@@ -94,6 +94,35 @@ func TestDayReportConsecutive(t *testing.T) {
 `, state.printBuffer)
 }
 
+func TestDayReportConsecutiveWithAbsences(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+2020-12-23 (8h!)
+	8h
+
+2020-12-25 (8h!)
+	1h
+
+2020-12-28 (8h!)
+	8h
+`)._SetCalendar("BEGIN:VCALENDAR\n" +
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20201225\nDTEND;VALUE=DATE:20201227\nSUMMARY:Christmas\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20201226\nSUMMARY:Family visit\nEND:VEVENT\n" +
+		"END:VCALENDAR\n",
+	)._Run((&Report{Fill: true, DiffArgs: lib.DiffArgs{Diff: true}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+                       Total    Should     Diff                         
+2020 Dec    Wed 23.       8h       8h!       0m                         
+            Thu 24.                                                     
+            Fri 25.       1h       0m!      +1h  Christmas              
+            Sat 26.                              Christmas, Family visit
+            Sun 27.                                                     
+            Mon 28.       8h       8h!       0m                         
+                    ======== ========= ========                         
+                         17h      16h!      +1h                         
+`, state.printBuffer)
+}
+
 func TestDayReportWithDiff(t *testing.T) {
 	state, err := NewTestingContext()._SetRecords(`
 2018-07-07 (8h!)
@@ -118,6 +147,60 @@ func TestDayReportWithDiff(t *testing.T) {
 `, state.printBuffer)
 }
 
+func TestDayReportWithBalance(t *testing.T) {
+	records := `
+2018-07-07 (8h!)
+	8h
+
+2018-07-08 (5h30m!)
+	2h
+
+2018-07-09 (2h!)
+	5h20m
+`
+	t.Run("Starting from zero", func(t *testing.T) {
+		state, err := NewTestingContext()._SetRecords(records)._Run((&Report{Balance: true}).Run)
+		require.Nil(t, err)
+		assert.Equal(t, `
+                       Total    Should     Diff  Balance
+2018 Jul    Sat  7.       8h       8h!       0m       0m
+            Sun  8.       2h    5h30m!   -3h30m   -3h30m
+            Mon  9.    5h20m       2h!   +3h20m     -10m
+                    ======== ========= ======== ========
+                      15h20m   15h30m!     -10m     -10m
+`, state.printBuffer)
+	})
+
+	t.Run("With initial balance from config", func(t *testing.T) {
+		state, err := NewTestingContext()._SetRecords(records)._SetFileConfig(`initial_balance = +1h`)._Run((&Report{Balance: true}).Run)
+		require.Nil(t, err)
+		assert.Equal(t, `
+                       Total    Should     Diff  Balance
+2018 Jul    Sat  7.       8h       8h!       0m      +1h
+            Sun  8.       2h    5h30m!   -3h30m   -2h30m
+            Mon  9.    5h20m       2h!   +3h20m     +50m
+                    ======== ========= ======== ========
+                      15h20m   15h30m!     -10m     +50m
+`, state.printBuffer)
+	})
+
+	t.Run("With initial balance from flag, and filter", func(t *testing.T) {
+		state, err := NewTestingContext()._SetRecords(records)._SetFileConfig(`initial_balance = +1h`)._Run((&Report{
+			Balance:        true,
+			InitialBalance: klog.NewDuration(-2, 0),
+			FilterArgs:     lib.FilterArgs{Until: klog.Ɀ_Date_(2018, 7, 8)},
+		}).Run)
+		require.Nil(t, err)
+		assert.Equal(t, `
+                       Total    Should     Diff  Balance
+2018 Jul    Sat  7.       8h       8h!       0m      -2h
+            Sun  8.       2h    5h30m!   -3h30m   -5h30m
+                    ======== ========= ======== ========
+                         10h   13h30m!   -3h30m   -5h30m
+`, state.printBuffer)
+	})
+}
+
 func TestDayReportWithDecimal(t *testing.T) {
 	state, err := NewTestingContext()._SetRecords(`
 2018-07-07 (8h!)
@@ -280,3 +363,70 @@ func TestYearReport(t *testing.T) {
        15h20m   15h49m!     -29m
 `, state.printBuffer)
 }
+
+func TestReportAsJson(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+2018-07-07 (8h!)
+	8h
+
+2018-07-09 (2h!)
+	5h20m
+`)._SetFileConfig(`initial_balance = -1h`)._Run((&Report{
+		AggregateBy: "week",
+		OutputArgs:  lib.OutputArgs{Output: "json"},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\n"+`{"aggregate_by":"week","periods":[`+
+		`{"period":"2018-W27","since":"2018-07-02","until":"2018-07-08","total":"8h","total_mins":480,"should_total":"8h!","should_total_mins":480,"diff":"0m","diff_mins":0,"balance":"-1h","balance_mins":-60,"labels":[]},`+
+		`{"period":"2018-W28","since":"2018-07-09","until":"2018-07-15","total":"5h20m","total_mins":320,"should_total":"2h!","should_total_mins":120,"diff":"+3h20m","diff_mins":200,"balance":"+2h20m","balance_mins":140,"labels":[]}`+
+		`],"total":{"total":"13h20m","total_mins":800,"should_total":"10h!","should_total_mins":600,"diff":"+3h20m","diff_mins":200,"balance":"+2h20m","balance_mins":140}}
+`, state.printBuffer)
+}
+
+func TestReportOfEmptyInputAsJson(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(``)._Run((&Report{
+		OutputArgs: lib.OutputArgs{Output: "json"},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\n"+`{"aggregate_by":"day","periods":[],"total":{"total":"0m","total_mins":0,"should_total":"0m!","should_total_mins":0,"diff":"0m","diff_mins":0,"balance":"0m","balance_mins":0}}
+`, state.printBuffer)
+}
+
+func TestReportAsCsvAndTsv(t *testing.T) {
+	records := `
+2020-12-24 (8h!)
+	8h
+
+2020-12-26 (8h!)
+	1h
+`
+	calendar := "BEGIN:VCALENDAR\n" +
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20201225\nDTEND;VALUE=DATE:20201227\nSUMMARY:Christmas\nEND:VEVENT\n" +
+		"END:VCALENDAR\n"
+
+	t.Run("CSV", func(t *testing.T) {
+		state, err := NewTestingContext()._SetRecords(records)._SetCalendar(calendar)._Run((&Report{
+			Fill:       true,
+			OutputArgs: lib.OutputArgs{Output: "csv"},
+		}).Run)
+		require.Nil(t, err)
+		assert.Equal(t, `
+period,since,until,total,total_mins,should_total,should_total_mins,diff,diff_mins,balance,balance_mins,labels
+2020-12-24,2020-12-24,2020-12-24,8h,480,8h!,480,0m,0,0m,0,
+2020-12-25,2020-12-25,2020-12-25,0m,0,0m!,0,0m,0,0m,0,Christmas
+2020-12-26,2020-12-26,2020-12-26,1h,60,0m!,0,+1h,60,+1h,60,Christmas
+`, state.printBuffer)
+	})
+
+	t.Run("TSV", func(t *testing.T) {
+		state, err := NewTestingContext()._SetRecords(records)._SetCalendar(calendar)._Run((&Report{
+			AggregateBy: "month",
+			OutputArgs:  lib.OutputArgs{Output: "tsv"},
+		}).Run)
+		require.Nil(t, err)
+		assert.Equal(t, "\n"+
+			"period\tsince\tuntil\ttotal\ttotal_mins\tshould_total\tshould_total_mins\tdiff\tdiff_mins\tbalance\tbalance_mins\tlabels\n"+
+			"2020-12\t2020-12-01\t2020-12-31\t9h\t540\t8h!\t480\t+1h\t60\t+1h\t60\tChristmas\n",
+			state.printBuffer)
+	})
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/app"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/jotaen/klog/klog/parser/reconciling"
+)
+
+type Retag struct {
+	From klog.Tag `arg:"" name:"old" placeholder:"OLD" help:"The tag to replace, e.g. '#foo' or '#foo=bar'"`
+	To   klog.Tag `arg:"" name:"new" placeholder:"NEW" help:"The new tag"`
+	lib.OutputFileArgs
+	lib.FilterArgs
+	lib.NoStyleArgs
+	lib.DryRunArgs
+}
+
+func (opt *Retag) Help() string {
+	return `It replaces a tag in all record and entry summaries of a file. All other text in the file stays as it is.
+
+If the old tag has no value (e.g. '#foo'), all occurrences of that tag are replaced, and their values are retained. That includes hierarchical tags, e.g. '#foo/bar' becomes '#new/bar'. If the new tag has a value, it replaces the existing values.
+If the old tag has a value (e.g. '#foo=bar'), only the occurrences with that value are replaced.
+
+The filter flags restrict which entries are considered. The tag is only replaced in the summaries of the matching entries. In a record summary, it is only replaced if all entries of the record match (or if the record has no entries and matches itself), because the record summary applies to all of its entries.
+
+Examples:
+    klog retag '#acme' '#acme-inc'                           (Rename the tag everywhere)
+    klog retag '#project=a' '#project=b' --since 2020-01-01  (Change the value for a subset of records)`
+}
+
+func (opt *Retag) Run(ctx app.Context) app.Error {
+	opt.NoStyleArgs.Apply(&ctx)
+	now := ctx.Now()
+	registry, rErr := ctx.ReadTagRegistry()
+	if rErr != nil {
+		return rErr
+	}
+	isEligible := func(r klog.Record, e *klog.Entry) bool {
+		entries := r.Entries()
+		if e != nil {
+			entries = []klog.Entry{*e}
+		}
+		// The filter reduces the entries of the record, so it must operate
+		// on a copy to not alter the original one.
+		rs := opt.ApplyFilter(now, registry, []klog.Record{copyWithEntries(r, entries)})
+		return len(rs) > 0 && len(rs[0].Entries()) == len(entries)
+	}
+	result, err := ctx.FormatFile(opt.File, reconciling.NewRetagger(opt.From, opt.To, isEligible), !opt.DryRun)
+	if err != nil {
+		return err
+	}
+	if !result.HasChanged() {
+		ctx.Print("There are no occurrences of " + opt.From.ToString() + "\n")
+		return nil
+	}
+	if opt.DryRun {
+		ctx.Print(lib.PrettifyDiff(ctx.Serialiser(), lib.DisplayName(opt.File), result.Original, result.Formatted))
+		return nil
+	}
+	changedLines, _ := lib.CountChangedLines(result.Original, result.Formatted)
+	ctx.Print(fmt.Sprintf("Replaced %s with %s in %d line(s)\n", opt.From.ToString(), opt.To.ToString(), changedLines))
+	return nil
+}
+
+func copyWithEntries(r klog.Record, entries []klog.Entry) klog.Record {
+	c := klog.NewRecord(r.Date())
+	if r.HasShouldTotal() {
+		c.SetShouldTotal(r.ShouldTotal())
+	}
+	c.SetSummary(r.Summary())
+	c.SetEntries(append([]klog.Entry(nil), entries...))
+	return c
+}
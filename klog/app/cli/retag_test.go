@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/jotaen/klog/klog/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const recordsToRetag = `
+2020-01-01
+Project #foo
+	1h #foo=1
+
+2020-01-02
+	2h #foo/sub
+`
+
+func TestRetagsFile(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(recordsToRetag)._Run((&Retag{
+		From: klog.NewTagOrPanic("foo", ""),
+		To:   klog.NewTagOrPanic("bar", ""),
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\nReplaced #foo with #bar in 3 line(s)\n", state.printBuffer)
+	assert.Equal(t, `
+2020-01-01
+Project #bar
+	1h #bar=1
+
+2020-01-02
+	2h #bar/sub
+`, state.writtenFileContents)
+}
+
+func TestRetagsFilteredRecords(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(recordsToRetag)._Run((&Retag{
+		From:       klog.NewTagOrPanic("foo", ""),
+		To:         klog.NewTagOrPanic("bar", ""),
+		FilterArgs: lib.FilterArgs{Since: klog.Ɀ_Date_(2020, 1, 2)},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+2020-01-01
+Project #foo
+	1h #foo=1
+
+2020-01-02
+	2h #bar/sub
+`, state.writtenFileContents)
+}
+
+func TestRetagsFilteredEntries(t *testing.T) {
+	query, _ := service.NewTagQueryFromString("#x")
+	state, err := NewTestingContext()._SetRecords(`
+2020-01-01
+#a
+	1h #a #x
+	2h #a
+
+2020-01-02
+#a #x
+	3h #a
+`)._Run((&Retag{
+		From:       klog.NewTagOrPanic("a", ""),
+		To:         klog.NewTagOrPanic("b", ""),
+		FilterArgs: lib.FilterArgs{Tags: []service.TagQuery{query}},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+2020-01-01
+#a
+	1h #b #x
+	2h #a
+
+2020-01-02
+#b #x
+	3h #b
+`, state.writtenFileContents)
+}
+
+func TestRetagWithDryRun(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(recordsToRetag)._Run((&Retag{
+		From:       klog.NewTagOrPanic("foo", "1"),
+		To:         klog.NewTagOrPanic("foo", "2"),
+		DryRunArgs: lib.DryRunArgs{DryRun: true},
+	}).Run)
+	require.Nil(t, err)
+	assert.Contains(t, state.printBuffer, "-\t1h #foo=1")
+	assert.Contains(t, state.printBuffer, "+\t1h #foo=2")
+	assert.Equal(t, "", state.writtenFileContents)
+}
+
+func TestRetagWithoutOccurrences(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(recordsToRetag)._Run((&Retag{
+		From: klog.NewTagOrPanic("xyz", ""),
+		To:   klog.NewTagOrPanic("bar", ""),
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\nThere are no occurrences of #xyz\n", state.printBuffer)
+	assert.Equal(t, "", state.writtenFileContents)
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"github.com/jotaen/klog/klog/app"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/jotaen/klog/klog/parser/reconciling"
+	"strings"
+)
+
+type Sort struct {
+	Order string `name:"order" help:"Sort order: asc (oldest first) or desc (newest first)" enum:"asc,desc,ASC,DESC" default:"asc"`
+	lib.NoStyleArgs
+	lib.OutputFileArgs
+	lib.DryRunArgs
+}
+
+func (opt *Sort) Help() string {
+	return `It reorders the records in the file by date. The text of every record stays exactly as it is; only the blank lines between the records are normalised. Records with the same date keep their relative order.
+
+With --dry-run, the file is not modified. Instead, it prints the differences.`
+}
+
+func (opt *Sort) Run(ctx app.Context) app.Error {
+	opt.NoStyleArgs.Apply(&ctx)
+	startWithOldest := strings.ToLower(opt.Order) == "asc"
+	result, err := ctx.FormatFile(opt.File, reconciling.NewSorter(startWithOldest), !opt.DryRun)
+	if err != nil {
+		return err
+	}
+	if !result.HasChanged() {
+		ctx.Print("The records are already sorted.\n")
+		return nil
+	}
+	if opt.DryRun {
+		ctx.Print(lib.PrettifyDiff(ctx.Serialiser(), lib.DisplayName(opt.File), result.Original, result.Formatted))
+		return nil
+	}
+	ctx.Print("Sorted the records of " + lib.DisplayName(opt.File) + "\n")
+	return nil
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSortsRecords(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+2020-01-02
+	1h Foo
+
+2020-01-01
+Summary
+`)._Run((&Sort{Order: "asc"}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\nSorted the records of (default bookmark)\n", state.printBuffer)
+	assert.Equal(t, `2020-01-01
+Summary
+
+2020-01-02
+	1h Foo
+`, state.writtenFileContents)
+}
+
+func TestSortsRecordsDescending(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+2020-01-01
+
+2020-01-02
+`)._Run((&Sort{Order: "DESC"}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "2020-01-02\n\n2020-01-01\n", state.writtenFileContents)
+}
+
+func TestSortDoesNothingIfAlreadySorted(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+2020-01-01
+
+2020-01-02
+`)._Run((&Sort{Order: "asc"}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\nThe records are already sorted.\n", state.printBuffer)
+	assert.Equal(t, "", state.writtenFileContents)
+}
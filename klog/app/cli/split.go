@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/app"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/jotaen/klog/klog/parser"
+	"github.com/jotaen/klog/klog/parser/reconciling"
+	"path/filepath"
+	"strings"
+)
+
+type Split struct {
+	By string `name:"by" required:"" help:"The period to split by: month or year" enum:"month,year"`
+	lib.NoStyleArgs
+	lib.OutputFileArgs
+}
+
+func (opt *Split) Help() string {
+	return `It distributes the records of a file into new files, one per month or year. The new files are placed next to the original file, and their names are derived from it, e.g. 'times.klg' is split into 'times-2020.klg', 'times-2021.klg', etc.
+
+The text of every record stays exactly as it is. The original file is not modified, and existing files are never overwritten: if any of the new files exists already, no file is created at all.`
+}
+
+func (opt *Split) Run(ctx app.Context) app.Error {
+	opt.NoStyleArgs.Apply(&ctx)
+	source, err := ctx.RetrieveTargetFile(opt.File)
+	if err != nil {
+		return err
+	}
+	records, blocks, errs := parser.NewSerialParser().Parse(source.Contents())
+	if errs != nil {
+		return app.NewParserErrorsInFile(source.Path(), errs)
+	}
+	if len(records) == 0 {
+		return app.NewErrorWithCode(
+			app.LOGICAL_ERROR,
+			"Nothing to split",
+			"The file doesn’t contain any records",
+			nil,
+		)
+	}
+	keys, texts, sErr := reconciling.Split(records, blocks, func(d klog.Date) string {
+		if opt.By == "year" {
+			return fmt.Sprintf("%04d", d.Year())
+		}
+		return fmt.Sprintf("%04d-%02d", d.Year(), d.Month())
+	})
+	if sErr != nil {
+		return app.NewErrorWithCode(app.LOGICAL_ERROR, "Splitting failed", sErr.Error(), sErr)
+	}
+	extension := filepath.Ext(source.Name())
+	baseName := strings.TrimSuffix(source.Name(), extension)
+	var targets []app.FileWithContents
+	for _, k := range keys {
+		target, fErr := app.NewFileWithContents(filepath.Join(source.Location(), baseName+"-"+k+extension), texts[k])
+		if fErr != nil {
+			return fErr
+		}
+		targets = append(targets, target)
+	}
+	// Either all files are created, or none, so that there is never a
+	// half-finished split.
+	cErr := ctx.CreateFiles(targets)
+	if cErr != nil {
+		return cErr
+	}
+	for _, t := range targets {
+		ctx.Print("Created " + t.Path() + "\n")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const recordsToSplit = `2019-12-31
+	1h
+
+2020-01-01
+Foo
+
+2020-01-15
+
+2020-02-01
+`
+
+func TestSplitsFileByMonth(t *testing.T) {
+	state, err := NewTestingContext().
+		_SetFile("/tmp/times.klg", recordsToSplit).
+		_Run((&Split{By: "month", OutputFileArgs: lib.OutputFileArgs{File: "/tmp/times.klg"}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+Created /tmp/times-2019-12.klg
+Created /tmp/times-2020-01.klg
+Created /tmp/times-2020-02.klg
+`, state.printBuffer)
+	assert.Equal(t, map[string]string{
+		"/tmp/times-2019-12.klg": "2019-12-31\n\t1h\n",
+		"/tmp/times-2020-01.klg": "2020-01-01\nFoo\n\n2020-01-15\n",
+		"/tmp/times-2020-02.klg": "2020-02-01\n",
+	}, state.createdFiles)
+	assert.Equal(t, "", state.writtenFileContents)
+}
+
+func TestSplitsFileByYear(t *testing.T) {
+	state, err := NewTestingContext().
+		_SetFile("/tmp/times.klg", recordsToSplit).
+		_Run((&Split{By: "year", OutputFileArgs: lib.OutputFileArgs{File: "/tmp/times.klg"}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"/tmp/times-2019.klg": "2019-12-31\n\t1h\n",
+		"/tmp/times-2020.klg": "2020-01-01\nFoo\n\n2020-01-15\n\n2020-02-01\n",
+	}, state.createdFiles)
+}
+
+func TestSplitDoesNotOverwriteExistingFiles(t *testing.T) {
+	_, err := NewTestingContext().
+		_SetFile("/tmp/times.klg", recordsToSplit).
+		_SetFile("/tmp/times-2019.klg", "").
+		_Run((&Split{By: "year", OutputFileArgs: lib.OutputFileArgs{File: "/tmp/times.klg"}}).Run)
+	require.Error(t, err)
+	assert.Equal(t, "File already exists", err.Error())
+}
+
+func TestSplitDoesNotCreateAnyFileIfOneExists(t *testing.T) {
+	state, err := NewTestingContext().
+		_SetFile("/tmp/times.klg", recordsToSplit).
+		_SetFile("/tmp/times-2020-01.klg", "").
+		_Run((&Split{By: "month", OutputFileArgs: lib.OutputFileArgs{File: "/tmp/times.klg"}}).Run)
+	require.Error(t, err)
+	assert.Equal(t, "File already exists", err.Error())
+	assert.Empty(t, state.createdFiles)
+	assert.Equal(t, "", state.printBuffer)
+}
@@ -14,6 +14,7 @@ type Start struct {
 	lib.NoStyleArgs
 	lib.OutputFileArgs
 	lib.WarnArgs
+	lib.DryRunArgs
 }
 
 func (opt *Start) Help() string {
@@ -30,11 +31,11 @@ func (opt *Start) Run(ctx app.Context) app.Error {
 	if tErr != nil {
 		return tErr
 	}
-	additionalData := reconciling.AdditionalData{}
-	ctx.Config().DefaultShouldTotal.Map(func(s klog.ShouldTotal) {
-		additionalData.ShouldTotal = s
+	additionalData := reconciling.AdditionalData{ShouldTotal: lib.ConfiguredShouldTotal(ctx, date)}
+	ctx.Config().AppendNewRecords.Map(func(a bool) {
+		additionalData.AppendAtEnd = a
 	})
-	return lib.Reconcile(ctx, lib.ReconcileOpts{OutputFileArgs: opt.OutputFileArgs, WarnArgs: opt.WarnArgs},
+	return lib.Reconcile(ctx, lib.ReconcileOpts{OutputFileArgs: opt.OutputFileArgs, WarnArgs: opt.WarnArgs, DryRunArgs: opt.DryRunArgs},
 		[]reconciling.Creator{
 			reconciling.NewReconcilerAtRecord(date),
 			reconciling.NewReconcilerForNewRecord(date, opt.DateFormat(ctx.Config()), additionalData),
@@ -13,6 +13,7 @@ type Stop struct {
 	lib.NoStyleArgs
 	lib.OutputFileArgs
 	lib.WarnArgs
+	lib.DryRunArgs
 }
 
 func (opt *Stop) Help() string {
@@ -34,7 +35,7 @@ func (opt *Stop) Run(ctx app.Context) app.Error {
 	// Otherwise, it wouldn’t make sense to decrement the day.
 	shouldTryYesterday := opt.WasAutomatic()
 	yesterday := date.PlusDays(-1)
-	return lib.Reconcile(ctx, lib.ReconcileOpts{OutputFileArgs: opt.OutputFileArgs, WarnArgs: opt.WarnArgs},
+	return lib.Reconcile(ctx, lib.ReconcileOpts{OutputFileArgs: opt.OutputFileArgs, WarnArgs: opt.WarnArgs, DryRunArgs: opt.DryRunArgs},
 		[]reconciling.Creator{
 			reconciling.NewReconcilerAtRecord(date),
 			func() reconciling.Creator {
@@ -222,3 +222,24 @@ default_rounding = 30m
 `, state.writtenFileContents)
 	}
 }
+
+func TestStopWithDryRunPrintsDiff(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`1920-02-02
+	11:22-?
+`)._SetNow(1920, 2, 2, 15, 24)._Run((&Stop{
+		AtDateAndTimeArgs: lib.AtDateAndTimeArgs{
+			AtDateArgs: lib.AtDateArgs{Date: klog.Ɀ_Date_(1920, 2, 2)},
+		},
+		DryRunArgs: lib.DryRunArgs{DryRun: true},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "", state.writtenFileContents)
+	assert.Equal(t, `
+--- (default bookmark)
++++ (default bookmark)
+@@ -1,2 +1,2 @@
+ 1920-02-02
+-	11:22-?
++	11:22-15:24
+`, state.printBuffer)
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/app"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/jotaen/klog/klog/parser"
+	"github.com/jotaen/klog/klog/parser/reconciling"
+)
+
+type Switch struct {
+	Summary     klog.EntrySummary `name:"summary" short:"s" placeholder:"TEXT" help:"Summary text for the new entry"`
+	StopSummary klog.EntrySummary `name:"stop-summary" placeholder:"TEXT" help:"Text to append to the summary of the closed entry"`
+	lib.AtDateAndTimeArgs
+	lib.NoStyleArgs
+	lib.OutputFileArgs
+	lib.WarnArgs
+	lib.DryRunArgs
+}
+
+func (opt *Switch) Help() string {
+	return `It closes the open-ended time range of the record (like 'klog stop'), and starts a new open-ended one right away (like 'klog start').
+The end time of the closed range and the start time of the new range are guaranteed to be identical.
+If no date is specified and the open-ended time range is in yesterday’s record (e.g., when working past midnight), it is closed there, and the new range is started in today’s record.
+
+If the --time flag is not specified, it defaults to the current time. In the latter case, the time can be rounded via --round.`
+}
+
+func (opt *Switch) Run(ctx app.Context) app.Error {
+	opt.NoStyleArgs.Apply(&ctx)
+	now := ctx.Now()
+	date := opt.AtDate(now)
+	time, err := opt.AtTime(now, ctx.Config())
+	if err != nil {
+		return err
+	}
+	// Only fall back to yesterday if no explicit date has been given.
+	// Otherwise, it wouldn’t make sense to decrement the day.
+	shouldTryYesterday := opt.WasAutomatic()
+	yesterday := date.PlusDays(-1)
+	return lib.Reconcile(ctx, lib.ReconcileOpts{OutputFileArgs: opt.OutputFileArgs, WarnArgs: opt.WarnArgs, DryRunArgs: opt.DryRunArgs},
+		[]reconciling.Creator{
+			reconciling.NewReconcilerAtRecord(date),
+			func() reconciling.Creator {
+				if shouldTryYesterday {
+					return reconciling.NewReconcilerAtRecord(yesterday)
+				}
+				return nil
+			}(),
+		},
+
+		func(reconciler *reconciling.Reconciler) (*reconciling.Result, error) {
+			timeFormat := opt.TimeFormat(ctx.Config())
+			if !shouldTryYesterday || !reconciler.Record.Date().IsEqualTo(yesterday) {
+				return reconciler.SwitchOpenRange(time, timeFormat, opt.StopSummary, opt.Summary)
+			}
+			// The open range is in yesterday’s record, so it’s closed there. The new
+			// range belongs to today, though, so it’s started in today’s record.
+			endTime, _ := time.Plus(klog.NewDuration(24, 0))
+			closed, cErr := reconciler.CloseOpenRange(endTime, timeFormat, opt.StopSummary)
+			if cErr != nil {
+				return nil, cErr
+			}
+			additionalData := reconciling.AdditionalData{ShouldTotal: lib.ConfiguredShouldTotal(ctx, date)}
+			ctx.Config().AppendNewRecords.Map(func(a bool) {
+				additionalData.AppendAtEnd = a
+			})
+			records, blocks, _ := parser.NewSerialParser().Parse(closed.AllSerialised)
+			started, sErr := app.ApplyReconciler(records, blocks,
+				[]reconciling.Creator{
+					reconciling.NewReconcilerAtRecord(date),
+					reconciling.NewReconcilerForNewRecord(date, opt.DateFormat(ctx.Config()), additionalData),
+				},
+				func(reconciler *reconciling.Reconciler) (*reconciling.Result, error) {
+					return reconciler.StartOpenRange(time, timeFormat, opt.Summary)
+				},
+			)
+			if sErr != nil {
+				return nil, sErr
+			}
+			started.Original = closed.Original
+			return started, nil
+		},
+	)
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/jotaen/klog/klog/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSwitch(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+1920-02-02
+	9:00-? Foo
+`)._SetNow(1920, 2, 2, 11, 24)._Run((&Switch{
+		Summary:     klog.Ɀ_EntrySummary_("Bar"),
+		StopSummary: klog.Ɀ_EntrySummary_("(done)"),
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+1920-02-02
+	9:00-11:24 Foo (done)
+	11:24-? Bar
+`, state.writtenFileContents)
+}
+
+func TestSwitchWithRounding(t *testing.T) {
+	r15, _ := service.NewRounding(15)
+	state, err := NewTestingContext()._SetRecords(`
+1920-02-02
+	9:00-?
+`)._SetNow(1920, 2, 2, 11, 24)._Run((&Switch{
+		AtDateAndTimeArgs: lib.AtDateAndTimeArgs{Round: r15},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+1920-02-02
+	9:00-11:30
+	11:30-?
+`, state.writtenFileContents)
+}
+
+func TestSwitchFallsBackWithShiftedTimeToYesterdayWithAutoTime(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+1920-02-02
+	22:22-? Foo
+`)._SetNow(1920, 2, 3, 0, 16)._Run((&Switch{
+		Summary:     klog.Ɀ_EntrySummary_("Bar"),
+		StopSummary: klog.Ɀ_EntrySummary_("(done)"),
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+1920-02-02
+	22:22-0:16> Foo (done)
+
+1920-02-03
+	0:16-? Bar
+`, state.writtenFileContents)
+}
+
+func TestSwitchFallsBackToYesterdayAndCreatesRecordOfTodayAsConfigured(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+1920-02-02
+	22:22-?
+`)._SetNow(1920, 2, 3, 0, 16)._SetFileConfig(`
+default_should_total = 8h!
+`)._Run((&Switch{}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+1920-02-02
+	22:22-0:16>
+
+1920-02-03 (8h!)
+	0:16-?
+`, state.writtenFileContents)
+}
+
+func TestSwitchFallsBackToYesterdayInDryRun(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+1920-02-02
+	22:22-?
+`)._SetNow(1920, 2, 3, 0, 16)._Run((&Switch{
+		DryRunArgs: lib.DryRunArgs{DryRun: true},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "", state.writtenFileContents)
+	assert.Contains(t, state.printBuffer, "-	22:22-?")
+	assert.Contains(t, state.printBuffer, "+	22:22-0:16>")
+	assert.Contains(t, state.printBuffer, "+1920-02-03")
+	assert.Contains(t, state.printBuffer, "+	0:16-?")
+}
+
+func TestSwitchFailsIfNoOpenRange(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+1920-02-02
+	9:00-10:00
+`)._SetNow(1920, 2, 2, 11, 24)._Run((&Switch{}).Run)
+	require.Error(t, err)
+	assert.Equal(t, "", state.writtenFileContents)
+}
@@ -2,19 +2,28 @@ package cli
 
 import (
 	"fmt"
+	"github.com/jotaen/klog/klog"
 	"github.com/jotaen/klog/klog/app"
 	"github.com/jotaen/klog/klog/app/cli/lib"
 	"github.com/jotaen/klog/klog/app/cli/lib/terminalformat"
+	"github.com/jotaen/klog/klog/parser/json"
 	"github.com/jotaen/klog/klog/service"
+	"math"
+	"strconv"
+	"strings"
 )
 
 type Tags struct {
-	Values bool `name:"values" short:"v" help:"Display breakdown of tag values"`
-	Count  bool `name:"count" short:"c" help:"Display the number of matching entries per tag"`
+	Values  bool `name:"values" short:"v" help:"Display breakdown of tag values"`
+	Count   bool `name:"count" short:"c" help:"Display the number of matching entries per tag"`
+	Numeric bool `name:"numeric" help:"Display sum, min, max and average of numeric tag values"`
+	Unknown bool `name:"unknown" help:"List the tags that are not declared in the tag registry"`
 	lib.FilterArgs
 	lib.NowArgs
+	lib.OutputArgs
 	lib.DecimalArgs
 	lib.WarnArgs
+	lib.LenientArgs
 	lib.NoStyleArgs
 	lib.InputFilesArgs
 }
@@ -24,26 +33,96 @@ func (opt *Tags) Help() string {
 
 If a tag appears in the overall record summary, then all of the record’s entries match. If a tag appears in an entry summary, only that particular entry matches.
 
-Every matching entry is counted individually.`
+Every matching entry is counted individually.
+
+Hierarchical tags (e.g. #acme/backend) are displayed as tree. The totals of a parent tag include all its descendants.
+
+If all values of a tag are numbers (e.g. #km=12 or #km="3.5"), the --numeric flag displays their sum, minimum, maximum and average. In the machine-readable output (--output json, csv, or tsv), these stats are always included.
+
+Tag aliases can be specified in the file ` + app.TAGS_FILE_NAME + ` in the klog config folder (see 'klog info config-folder'). Every line contains a tag, optionally followed by its aliases. Aliases are folded into that tag when evaluating (also for the --tag filter), but the files themselves are not changed. If the file contains any tags, klog warns about all tags that are not listed. Lines starting with ; are comments. Example:
+
+    #meeting = #meetings #mtg
+    #acme
+
+Optionally, the subsequent indented lines can specify a description (name), a category, a colour (0-255) and whether the tag is billable. The tags are grouped by category then, and the colours are also used by 'klog print'. Example:
+
+    #acme
+        name = ACME Corporation
+        category = Clients
+        colour = 214
+        billable = yes
+
+With --unknown, it lists the tags that appear in the data, but that are not declared in the file.`
 }
 
 func (opt *Tags) Run(ctx app.Context) app.Error {
 	opt.DecimalArgs.Apply(&ctx)
 	opt.NoStyleArgs.Apply(&ctx)
-	records, err := ctx.ReadInputs(opt.File...)
+	records, err := opt.LenientArgs.ReadInputs(ctx, opt.File...)
 	if err != nil {
 		return err
 	}
 	now := ctx.Now()
-	records = opt.ApplyFilter(now, records)
+	registry, rErr := ctx.ReadTagRegistry()
+	if rErr != nil {
+		return rErr
+	}
+	records = opt.ApplyFilter(now, registry, records)
 	nErr := opt.ApplyNow(now, records...)
 	if nErr != nil {
 		return nErr
 	}
-	totalByTag := service.AggregateTotalsByTags(records...)
+	if opt.Unknown {
+		return opt.printUnknown(ctx, registry, records)
+	}
+	totalByTag := service.AggregateTotalsByTags(registry, records...)
+	if opt.IsMachineReadable() {
+		opt.printMachineReadable(ctx, totalByTag)
+		return nil
+	}
 	if len(totalByTag) == 0 {
 		return nil
 	}
+	categories := registry.Categories()
+	if len(categories) == 0 {
+		opt.printTable(ctx, registry, totalByTag)
+	} else {
+		// The tags are grouped by the category of their top-level tag, so
+		// that the tree structure is retained.
+		const otherCategory = "Other"
+		categories = append(categories, otherCategory)
+		statsByCategory := make(map[string][]*service.TagStats)
+		for _, t := range totalByTag {
+			category := otherCategory
+			if info, ok := registry.Info(topLevelTag(t.Tag)); ok && info.Category != "" {
+				category = info.Category
+			}
+			statsByCategory[category] = append(statsByCategory[category], t)
+		}
+		isFirst := true
+		for _, c := range categories {
+			if len(statsByCategory[c]) == 0 {
+				continue
+			}
+			if !isFirst {
+				ctx.Print("\n")
+			}
+			isFirst = false
+			ctx.Print(ctx.Serialiser().Format(terminalformat.Style{IsUnderlined: true}, c) + "\n")
+			opt.printTable(ctx, registry, statsByCategory[c])
+		}
+	}
+	opt.WarnArgs.PrintWarnings(ctx, records, opt.GetNowWarnings())
+	return nil
+}
+
+func (opt *Tags) printTable(ctx app.Context, registry service.TagRegistry, totalByTag []*service.TagStats) {
+	hasDescriptions := false
+	for _, t := range totalByTag {
+		if info, ok := registry.Info(t.Tag); ok && (info.Name != "" || info.IsBillable) {
+			hasDescriptions = true
+		}
+	}
 	numberOfColumns := 2
 	if opt.Values {
 		numberOfColumns++
@@ -51,12 +130,24 @@ func (opt *Tags) Run(ctx app.Context) app.Error {
 	if opt.Count {
 		numberOfColumns++
 	}
+	if opt.Numeric {
+		numberOfColumns += 4
+	}
+	if hasDescriptions {
+		numberOfColumns++
+	}
+	numericStyle := terminalformat.Style{Color: "247"}
 	table := terminalformat.NewTable(numberOfColumns, " ")
 	for _, t := range totalByTag {
 		totalString := ctx.Serialiser().Duration(t.Total)
 		countString := ctx.Serialiser().Format(terminalformat.Style{Color: "247"}, fmt.Sprintf(" (%d)", t.Count))
+		indentation := strings.Repeat("  ", len(t.Tag.Ancestors()))
 		if t.Tag.Value() == "" {
-			table.CellL("#" + t.Tag.Name())
+			tagString := "#" + t.Tag.Name()
+			if info, ok := registry.NearestInfo(t.Tag); ok && info.Colour != "" {
+				tagString = ctx.Serialiser().Format(terminalformat.Style{Color: info.Colour}, tagString)
+			}
+			table.CellL(indentation + tagString)
 			table.CellL(totalString)
 			if opt.Values {
 				table.Skip(1)
@@ -64,16 +155,106 @@ func (opt *Tags) Run(ctx app.Context) app.Error {
 			if opt.Count {
 				table.CellL(countString)
 			}
+			if opt.Numeric {
+				if t.Numeric == nil {
+					table.Skip(4)
+				} else {
+					table.CellR(ctx.Serialiser().Format(numericStyle, "sum ") + formatNumber(t.Numeric.Sum))
+					table.CellR(ctx.Serialiser().Format(numericStyle, "min ") + formatNumber(t.Numeric.Min))
+					table.CellR(ctx.Serialiser().Format(numericStyle, "max ") + formatNumber(t.Numeric.Max))
+					table.CellR(ctx.Serialiser().Format(numericStyle, "avg ") + formatNumber(t.Numeric.Avg()))
+				}
+			}
+			if hasDescriptions {
+				table.CellL(ctx.Serialiser().Format(terminalformat.Style{Color: "247"}, describeTag(registry, t.Tag)))
+			}
 		} else if opt.Values {
-			table.CellL(" " + ctx.Serialiser().Format(terminalformat.Style{Color: "247"}, t.Tag.Value()))
+			table.CellL(indentation + " " + ctx.Serialiser().Format(terminalformat.Style{Color: "247"}, t.Tag.Value()))
 			table.Skip(1)
 			table.CellL(totalString)
 			if opt.Count {
 				table.CellL(countString)
 			}
+			if opt.Numeric {
+				table.Skip(4)
+			}
+			if hasDescriptions {
+				table.Skip(1)
+			}
 		}
 	}
 	table.Collect(ctx.Print)
-	opt.WarnArgs.PrintWarnings(ctx, records, opt.GetNowWarnings())
+}
+
+func (opt *Tags) printUnknown(ctx app.Context, registry service.TagRegistry, records []klog.Record) app.Error {
+	if registry.IsEmpty() {
+		return app.NewErrorWithCode(
+			app.LOGICAL_ERROR,
+			"No tags declared",
+			"Please specify the known tags in the file "+app.Join(ctx.KlogConfigFolder(), app.TAGS_FILE_NAME).Path(),
+			nil,
+		)
+	}
+	for _, t := range registry.UnknownTags(records...) {
+		ctx.Print(t.ToString() + "\n")
+	}
 	return nil
 }
+
+// describeTag returns the name of the tag as per the registry, and whether
+// it’s billable.
+func describeTag(registry service.TagRegistry, t klog.Tag) string {
+	info, ok := registry.Info(t)
+	if !ok {
+		return ""
+	}
+	var parts []string
+	if info.Name != "" {
+		parts = append(parts, info.Name)
+	}
+	if info.IsBillable {
+		parts = append(parts, "(billable)")
+	}
+	return strings.Join(parts, " ")
+}
+
+// topLevelTag returns the (value-less) top-most ancestor of a hierarchical
+// tag, or the tag itself.
+func topLevelTag(t klog.Tag) klog.Tag {
+	if ancestors := t.Ancestors(); len(ancestors) > 0 {
+		return ancestors[0]
+	}
+	return klog.NewTagOrPanic(t.Name(), "")
+}
+
+func (opt *Tags) printMachineReadable(ctx app.Context, totalByTag []*service.TagStats) {
+	if opt.Output == "json" {
+		ctx.Print(json.TagStatsToJson(totalByTag, opt.Pretty) + "\n")
+		return
+	}
+	rows := [][]string{{
+		"tag", "name", "value", "total", "total_mins", "count",
+		"numeric_sum", "numeric_min", "numeric_max", "numeric_avg", "numeric_count",
+	}}
+	for _, t := range totalByTag {
+		numeric := []string{"", "", "", "", ""}
+		if t.Numeric != nil {
+			numeric = []string{
+				formatNumber(t.Numeric.Sum),
+				formatNumber(t.Numeric.Min),
+				formatNumber(t.Numeric.Max),
+				formatNumber(t.Numeric.Avg()),
+				strconv.Itoa(t.Numeric.Count),
+			}
+		}
+		rows = append(rows, append([]string{
+			t.Tag.ToString(), t.Tag.Name(), t.Tag.Value(), t.Total.ToString(), strconv.Itoa(t.Total.InMinutes()), strconv.Itoa(t.Count),
+		}, numeric...))
+	}
+	opt.PrintRows(ctx, rows)
+}
+
+// formatNumber prints a number with at most two decimal places.
+func formatNumber(x float64) string {
+	return strconv.FormatFloat(math.Round(x*100)/100, 'f', -1, 64)
+}
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/jotaen/klog/klog/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -125,3 +127,172 @@ func TestPrintTagsOverviewWithoutValueGrouping(t *testing.T) {
 #ticket 4h
 `, state.printBuffer)
 }
+
+func TestPrintHierarchicalTagsAsTree(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+1995-03-17
+	3h #acme/backend
+	1h #acme/frontend=ui
+	2h #acme/backend/db
+	30m #other
+`)._Run((&Tags{
+		Values: true,
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+#acme                6h    
+  #acme/backend      5h    
+    #acme/backend/db 2h    
+  #acme/frontend     1h    
+   ui                    1h
+#other               30m   
+`, state.printBuffer)
+}
+
+func TestPrintTagsWithNumericStats(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+1995-03-17
+	3h #km=12 #ticket=AB-1
+	1h #km="2.5"
+	1h #km=3
+`)._Run((&Tags{
+		Numeric: true,
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+#km     5h sum 17.5 min 2.5 max 12 avg 5.83
+#ticket 3h                                 
+`, state.printBuffer)
+}
+
+func TestPrintTagsAsJson(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+1995-03-17
+	3h #km=12
+	1h #km=3 #foo
+`)._Run((&Tags{
+		OutputArgs: lib.OutputArgs{Output: "json"},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\n"+`{"tags":[`+
+		`{"tag":"#foo","name":"foo","value":"","total":"1h","total_mins":60,"count":1,"numeric":null},`+
+		`{"tag":"#km","name":"km","value":"","total":"4h","total_mins":240,"count":2,"numeric":{"sum":15,"min":3,"max":12,"avg":7.5,"count":2}},`+
+		`{"tag":"#km=12","name":"km","value":"12","total":"3h","total_mins":180,"count":1,"numeric":{"sum":12,"min":12,"max":12,"avg":12,"count":1}},`+
+		`{"tag":"#km=3","name":"km","value":"3","total":"1h","total_mins":60,"count":1,"numeric":{"sum":3,"min":3,"max":3,"avg":3,"count":1}}`+
+		`]}
+`, state.printBuffer)
+}
+
+func TestPrintTagsAsCsv(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+1995-03-17
+	3h #km=12
+	1h #km=3 #foo
+`)._Run((&Tags{
+		OutputArgs: lib.OutputArgs{Output: "csv"},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+tag,name,value,total,total_mins,count,numeric_sum,numeric_min,numeric_max,numeric_avg,numeric_count
+#foo,foo,,1h,60,1,,,,,
+#km,km,,4h,240,2,15,3,12,7.5,2
+#km=12,km,12,3h,180,1,12,12,12,12,1
+#km=3,km,3,1h,60,1,3,3,3,3,1
+`, state.printBuffer)
+}
+
+func TestPrintTagsWithAliases(t *testing.T) {
+	query, _ := service.NewTagQueryFromString("#mtg")
+	state, err := NewTestingContext()._SetRecords(`
+1995-03-17
+	1h #meeting
+	2h #mtg=daily
+	4h #meetings #other
+`)._SetTags(`
+#meeting = #meetings #mtg
+#other
+`)._Run((&Tags{Values: true, FilterArgs: lib.FilterArgs{Tags: []service.TagQuery{query}}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+#meeting 7h   
+ daily      2h
+#other   4h   
+`, state.printBuffer)
+}
+
+func TestPrintTagsWarnsAboutUnknownTags(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+1995-03-17
+	1h #meeting
+	2h #unknown
+`)._SetTags(`
+#meeting
+`)._Run((&Tags{}).Run)
+	require.Nil(t, err)
+	assert.Contains(t, state.printBuffer, "1995-03-17: Unknown tag: #unknown")
+}
+
+func TestPrintTagsOmitsWarningsOfDisabledChecks(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+1995-03-17
+	1h #meeting
+	2h #unknown
+`)._SetTags(`
+#meeting
+`)._SetFileConfig(`
+check_rules = unknown-tag: off
+`)._Run((&Tags{}).Run)
+	require.Nil(t, err)
+	assert.NotContains(t, state.printBuffer, "Unknown tag")
+}
+
+func TestPrintTagsGroupedByCategory(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+1995-03-17
+	1h #acme/backend
+	2h #meeting
+	4h #globex
+	30m #misc
+`)._SetTags(`
+#meeting
+    category = Internal
+#acme
+    name = ACME Corporation
+    category = Clients
+    billable = yes
+#acme/backend
+    name = Backend
+#globex
+    category = Clients
+#misc
+`)._Run((&Tags{}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+Internal
+#meeting 2h
+
+Clients
+#acme           1h ACME Corporation (billable)
+  #acme/backend 1h Backend                    
+#globex         4h                            
+
+Other
+#misc 30m
+`, state.printBuffer)
+}
+
+func TestPrintUnknownTags(t *testing.T) {
+	ctx := NewTestingContext()._SetRecords(`
+1995-03-17
+#foo
+	1h #acme/backend
+	2h #mtg=1 #bar
+`)
+	_, err := ctx._Run((&Tags{Unknown: true}).Run)
+	require.Error(t, err)
+	assert.Equal(t, "No tags declared", err.Error())
+
+	state, err := ctx._SetTags("#acme\n#meeting = #mtg\n")._Run((&Tags{Unknown: true}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\n#bar\n#foo\n", state.printBuffer)
+}
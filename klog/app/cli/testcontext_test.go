@@ -9,6 +9,9 @@ import (
 	"github.com/jotaen/klog/klog/parser"
 	"github.com/jotaen/klog/klog/parser/reconciling"
 	"github.com/jotaen/klog/klog/parser/txt"
+	"github.com/jotaen/klog/klog/service"
+	"github.com/jotaen/klog/klog/service/billing"
+	"github.com/jotaen/klog/klog/service/calendar"
 	gotime "time"
 )
 
@@ -19,6 +22,7 @@ func NewTestingContext() TestingContext {
 		State: State{
 			printBuffer:         "",
 			writtenFileContents: "",
+			createdFiles:        make(map[string]string),
 		},
 		now:            gotime.Now(),
 		records:        nil,
@@ -31,7 +35,12 @@ func NewTestingContext() TestingContext {
 		execute: func(_ command.Command) app.Error {
 			return nil
 		},
-		config: &config,
+		config:   &config,
+		calendar: calendar.NewEmptyCalendar(),
+		rates:    billing.NewEmptyRates(),
+		tags:     service.NewEmptyTagRegistry(),
+		journal:  app.NewEmptyJournal(),
+		files:    make(map[string]string),
 	}
 }
 
@@ -70,6 +79,45 @@ func (ctx TestingContext) _SetFileConfig(configFile string) TestingContext {
 	return ctx
 }
 
+func (ctx TestingContext) _SetCalendar(icsText string) TestingContext {
+	c, err := calendar.Parse(icsText)
+	if err != nil {
+		panic("Invalid calendar")
+	}
+	ctx.calendar = c
+	return ctx
+}
+
+func (ctx TestingContext) _SetRates(ratesFile string) TestingContext {
+	rates, err := billing.NewRatesFromString(ratesFile)
+	if err != nil {
+		panic(err)
+	}
+	ctx.rates = rates
+	return ctx
+}
+
+func (ctx TestingContext) _SetTags(tagsFile string) TestingContext {
+	tags, err := service.NewTagRegistryFromString(tagsFile)
+	if err != nil {
+		panic(err)
+	}
+	ctx.tags = tags
+	return ctx
+}
+
+func (ctx TestingContext) _SetJournal(entries ...app.JournalEntry) TestingContext {
+	for _, e := range entries {
+		ctx.journal.Append(e)
+	}
+	return ctx
+}
+
+func (ctx TestingContext) _SetFile(path string, contents string) TestingContext {
+	ctx.files[path] = contents
+	return ctx
+}
+
 func (ctx TestingContext) _SetExecute(execute func(command.Command) app.Error) TestingContext {
 	ctx.execute = execute
 	return ctx
@@ -81,12 +129,15 @@ func (ctx TestingContext) _Run(cmd func(app.Context) app.Error) (State, app.Erro
 	if len(out) > 0 && out[0] != '\n' {
 		out = "\n" + out
 	}
-	return State{out, ctx.writtenFileContents}, cmdErr
+	stderr := terminalformat.StripAllAnsiSequences(ctx.stderrBuffer)
+	return State{out, stderr, ctx.writtenFileContents, ctx.createdFiles}, cmdErr
 }
 
 type State struct {
 	printBuffer         string
+	stderrBuffer        string
 	writtenFileContents string
+	createdFiles        map[string]string
 }
 
 type TestingContext struct {
@@ -101,12 +152,21 @@ type TestingContext struct {
 	fileExplorers  []command.Command
 	execute        func(command.Command) app.Error
 	config         *app.Config
+	calendar       calendar.Calendar
+	rates          billing.Rates
+	tags           service.TagRegistry
+	journal        app.Journal
+	files          map[string]string
 }
 
 func (ctx *TestingContext) Print(s string) {
 	ctx.printBuffer += s
 }
 
+func (ctx *TestingContext) PrintToStderr(s string) {
+	ctx.stderrBuffer += s
+}
+
 func (ctx *TestingContext) ReadLine() (string, app.Error) {
 	return "", nil
 }
@@ -128,16 +188,76 @@ func (ctx *TestingContext) Meta() app.Meta {
 	}
 }
 
-func (ctx *TestingContext) ReadInputs(_ ...app.FileOrBookmarkName) ([]klog.Record, app.Error) {
-	return ctx.records, nil
+func (ctx *TestingContext) ReadInputs(fileArgs ...app.FileOrBookmarkName) ([]klog.Record, app.Error) {
+	if len(fileArgs) == 0 {
+		return ctx.records, nil
+	}
+	files, err := ctx.ReadInputFiles(false, fileArgs...)
+	if err != nil {
+		return nil, err
+	}
+	var records []klog.Record
+	for _, f := range files {
+		records = append(records, f.Records...)
+	}
+	return records, nil
+}
+
+func (ctx *TestingContext) ReadInputFiles(lenient bool, fileArgs ...app.FileOrBookmarkName) ([]app.ParsedFile, app.Error) {
+	if len(fileArgs) == 0 {
+		return []app.ParsedFile{{Records: ctx.records, Blocks: ctx.blocks}}, nil
+	}
+	var result []app.ParsedFile
+	for _, f := range fileArgs {
+		file, err := app.NewFileWithContents(string(f), ctx.files[string(f)])
+		if err != nil {
+			return nil, err
+		}
+		if lenient {
+			records, blocks, errs := parser.NewSerialParser().ParseLeniently(file.Contents())
+			parsedFile := app.NewParsedFile(file, records, blocks)
+			parsedFile.Errors = errs
+			result = append(result, parsedFile)
+			continue
+		}
+		records, blocks, errs := parser.NewSerialParser().Parse(file.Contents())
+		if errs != nil {
+			return nil, app.NewParserErrorsInFile(file.Path(), errs)
+		}
+		result = append(result, app.NewParsedFile(file, records, blocks))
+	}
+	return result, nil
 }
 
-func (ctx *TestingContext) ReconcileFile(_ app.FileOrBookmarkName, creators []reconciling.Creator, reconcile reconciling.Reconcile) (*reconciling.Result, app.Error) {
+func (ctx *TestingContext) ReconcileFile(fileArg app.FileOrBookmarkName, creators []reconciling.Creator, reconcile reconciling.Reconcile, write bool, amendJournal bool) (*reconciling.Result, app.Error) {
 	result, err := app.ApplyReconciler(ctx.records, ctx.blocks, creators, reconcile)
 	if err != nil {
 		return nil, err
 	}
-	ctx.writtenFileContents = result.AllSerialised
+	if write {
+		ctx.writtenFileContents = result.AllSerialised
+		if fileArg != "" {
+			entry := app.JournalEntry{Path: string(fileArg), Time: ctx.now, Before: ctx.contents(), After: result.AllSerialised}
+			if amendJournal {
+				ctx.journal.Amend(entry)
+			} else {
+				ctx.journal.Append(entry)
+			}
+			// Continue with the new contents, like on a real file.
+			ctx.records, ctx.blocks, _ = parser.NewSerialParser().Parse(result.AllSerialised)
+		}
+	}
+	return result, nil
+}
+
+func (ctx *TestingContext) FormatFile(_ app.FileOrBookmarkName, format reconciling.Format, write bool) (*reconciling.FormatResult, app.Error) {
+	result, err := app.ApplyFormatter(ctx.records, ctx.blocks, format)
+	if err != nil {
+		return nil, err
+	}
+	if write && result.HasChanged() {
+		ctx.writtenFileContents = result.Formatted
+	}
 	return result, nil
 }
 
@@ -146,6 +266,34 @@ func (ctx *TestingContext) WriteFile(_ app.File, contents string) app.Error {
 	return nil
 }
 
+func (ctx *TestingContext) ReadJournal() (app.Journal, app.Error) {
+	return ctx.journal, nil
+}
+
+// contents returns the original text of the records.
+func (ctx *TestingContext) contents() string {
+	contents := ""
+	for _, b := range ctx.blocks {
+		for _, l := range b.Lines() {
+			contents += l.Original()
+		}
+	}
+	return contents
+}
+
+func (ctx *TestingContext) UndoFile(fileArg app.FileOrBookmarkName, count int) ([]app.JournalEntry, app.Error) {
+	target, err := app.NewFileWithContents(string(fileArg), ctx.contents())
+	if err != nil {
+		return nil, err
+	}
+	restored, reverted, uErr := ctx.journal.Undo(target, count)
+	if uErr != nil {
+		return nil, uErr
+	}
+	ctx.writtenFileContents = restored
+	return reverted, nil
+}
+
 func (ctx *TestingContext) Now() gotime.Time {
 	return ctx.now
 }
@@ -154,7 +302,27 @@ func (ctx *TestingContext) RetrieveTargetFile(fileArg app.FileOrBookmarkName) (a
 	if fileArg == "" {
 		return nil, app.NewError("Error", "Error", nil)
 	}
-	return app.NewFileWithContents(string(fileArg), "")
+	return app.NewFileWithContents(string(fileArg), ctx.files[string(fileArg)])
+}
+
+func (ctx *TestingContext) CreateFile(target app.File, contents string) app.Error {
+	if _, exists := ctx.files[target.Path()]; exists {
+		return app.NewErrorWithCode(app.IO_ERROR, "File already exists", "Location: "+target.Path(), nil)
+	}
+	ctx.createdFiles[target.Path()] = contents
+	return nil
+}
+
+func (ctx *TestingContext) CreateFiles(targets []app.FileWithContents) app.Error {
+	for _, t := range targets {
+		if _, exists := ctx.files[t.Path()]; exists {
+			return app.NewErrorWithCode(app.IO_ERROR, "File already exists", "Location: "+t.Path(), nil)
+		}
+	}
+	for _, t := range targets {
+		ctx.createdFiles[t.Path()] = t.Contents()
+	}
+	return nil
 }
 
 func (ctx *TestingContext) ReadBookmarks() (app.BookmarksCollection, app.Error) {
@@ -190,3 +358,15 @@ func (ctx *TestingContext) Debug(_ func()) {}
 func (ctx *TestingContext) Config() app.Config {
 	return *ctx.config
 }
+
+func (ctx *TestingContext) ReadCalendar() (calendar.Calendar, app.Error) {
+	return ctx.calendar, nil
+}
+
+func (ctx *TestingContext) ReadRates() (billing.Rates, app.Error) {
+	return ctx.rates, nil
+}
+
+func (ctx *TestingContext) ReadTagRegistry() (service.TagRegistry, app.Error) {
+	return ctx.tags, nil
+}
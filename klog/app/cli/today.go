@@ -5,7 +5,9 @@ import (
 	"github.com/jotaen/klog/klog/app"
 	"github.com/jotaen/klog/klog/app/cli/lib"
 	"github.com/jotaen/klog/klog/app/cli/lib/terminalformat"
+	"github.com/jotaen/klog/klog/parser/json"
 	"github.com/jotaen/klog/klog/service"
+	"strconv"
 	gotime "time"
 )
 
@@ -13,8 +15,10 @@ type Today struct {
 	lib.DiffArgs
 	lib.NowArgs
 	Follow bool `name:"follow" short:"f" help:"Keep shell open and follow changes"`
+	lib.OutputArgs
 	lib.DecimalArgs
 	lib.WarnArgs
+	lib.LenientArgs
 	lib.NoStyleArgs
 	lib.InputFilesArgs
 }
@@ -26,10 +30,16 @@ It evaluates the total time separately for today’s records and all other recor
 When both --now and --diff are set, it also calculates the forecasted end-time at which the time goal will be reached.
 (I.e. when the difference between should and actual time will be 0.)
 
-If there are no records today, it falls back to yesterday.`
+If there are no records today, it falls back to yesterday.
+
+With --output json, csv, or tsv, the result is printed in a machine-readable format,
+which always includes the should-total times, the differences, and the end-times.`
 }
 
 func (opt *Today) Run(ctx app.Context) app.Error {
+	if opt.IsMachineReadable() {
+		opt.Diff = true
+	}
 	opt.DecimalArgs.Apply(&ctx)
 	opt.NoStyleArgs.Apply(&ctx)
 	if opt.Follow {
@@ -56,7 +66,7 @@ var (
 )
 
 func handle(opt *Today, ctx app.Context) app.Error {
-	records, err := ctx.ReadInputs(opt.File...)
+	records, err := opt.LenientArgs.ReadInputs(ctx, opt.File...)
 	if err != nil {
 		return err
 	}
@@ -65,6 +75,10 @@ func handle(opt *Today, ctx app.Context) app.Error {
 	if nErr != nil {
 		return nErr
 	}
+	sErr := opt.ApplyShouldTotals(ctx, records...)
+	if sErr != nil {
+		return sErr
+	}
 
 	currentRecords, otherRecords, isYesterday := splitIntoCurrentAndOther(now, records)
 	hasCurrentRecords := len(currentRecords) > 0
@@ -79,6 +93,34 @@ func handle(opt *Today, ctx app.Context) app.Error {
 	grandDiff := service.Diff(grandShouldTotal, grandTotal)
 	grandEndTime, _ := klog.NewTimeFromGo(now).Plus(klog.NewDuration(0, 0).Minus(grandDiff))
 
+	if opt.IsMachineReadable() {
+		currentDate := klog.NewDateFromGo(now)
+		if isYesterday {
+			currentDate = currentDate.PlusDays(-1)
+		}
+		endTime := func(t klog.Time) *string {
+			if !hasCurrentRecords || t == nil {
+				return nil
+			}
+			result := t.ToString()
+			return &result
+		}
+		opt.printMachineReadable(ctx, json.TodayEnvelop{
+			Current: json.TodayView{
+				Date:           currentDate.ToString(),
+				EvaluationView: json.NewEvaluationView(currentTotal, currentShouldTotal, currentDiff),
+				EndTime:        endTime(currentEndTime),
+			},
+			Other: json.NewEvaluationView(otherTotal, otherShouldTotal, otherDiff),
+			All: json.TodayView{
+				Date:           currentDate.ToString(),
+				EvaluationView: json.NewEvaluationView(grandTotal, grandShouldTotal, grandDiff),
+				EndTime:        endTime(grandEndTime),
+			},
+		})
+		return nil
+	}
+
 	numberOfValueColumns := func() int {
 		if opt.Diff {
 			if opt.Now {
@@ -189,7 +231,31 @@ func handle(opt *Today, ctx app.Context) app.Error {
 	return nil
 }
 
-func (opt *Today) evaluate(records []klog.Record) (klog.Duration, klog.Duration, klog.Duration) {
+func (opt *Today) printMachineReadable(ctx app.Context, envelop json.TodayEnvelop) {
+	if opt.Output == "json" {
+		ctx.Print(json.TodayToJson(envelop, opt.Pretty) + "\n")
+		return
+	}
+	row := func(name string, date string, v json.EvaluationView, endTime *string) []string {
+		end := ""
+		if endTime != nil {
+			end = *endTime
+		}
+		return []string{
+			name, date,
+			v.Total, strconv.Itoa(v.TotalMins), v.ShouldTotal, strconv.Itoa(v.ShouldTotalMins), v.Diff, strconv.Itoa(v.DiffMins),
+			end,
+		}
+	}
+	opt.PrintRows(ctx, [][]string{
+		{"row", "date", "total", "total_mins", "should_total", "should_total_mins", "diff", "diff_mins", "end_time"},
+		row("current", envelop.Current.Date, envelop.Current.EvaluationView, envelop.Current.EndTime),
+		row("other", "", envelop.Other, nil),
+		row("all", envelop.All.Date, envelop.All.EvaluationView, envelop.All.EndTime),
+	})
+}
+
+func (opt *Today) evaluate(records []klog.Record) (klog.Duration, klog.ShouldTotal, klog.Duration) {
 	total := service.Total(records...)
 	shouldTotal := service.ShouldTotalSum(records...)
 	diff := service.Diff(shouldTotal, total)
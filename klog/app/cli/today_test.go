@@ -163,3 +163,38 @@ Other        6h50m    3h10m!   +3h40m
 All          6h50m    3h10m!   +3h40m        n/a
 `, state.printBuffer)
 }
+
+func TestPrintsEvaluationAsJson(t *testing.T) {
+	state, err := NewTestingContext()._SetNow(1999, 3, 14, 18, 13)._SetRecords(`
+1999-03-12 (3h10m!)
+	6h50m
+
+1999-03-14 (6h!)
+	14:38 - ?
+`)._Run((&Today{
+		NowArgs:    lib.NowArgs{Now: true},
+		OutputArgs: lib.OutputArgs{Output: "json"},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\n"+`{`+
+		`"current":{"date":"1999-03-14","total":"3h35m","total_mins":215,"should_total":"6h!","should_total_mins":360,"diff":"-2h25m","diff_mins":-145,"end_time":"20:38"},`+
+		`"other":{"total":"6h50m","total_mins":410,"should_total":"3h10m!","should_total_mins":190,"diff":"+3h40m","diff_mins":220},`+
+		`"all":{"date":"1999-03-14","total":"10h25m","total_mins":625,"should_total":"9h10m!","should_total_mins":550,"diff":"+1h15m","diff_mins":75,"end_time":"16:58"}`+
+		`}`+"\n", state.printBuffer)
+}
+
+func TestPrintsEvaluationAsCsvWithoutCurrentRecords(t *testing.T) {
+	state, err := NewTestingContext()._SetNow(1999, 3, 16, 18, 13)._SetRecords(`
+1999-03-12 (3h10m!)
+	6h50m
+`)._Run((&Today{
+		OutputArgs: lib.OutputArgs{Output: "csv"},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, `
+row,date,total,total_mins,should_total,should_total_mins,diff,diff_mins,end_time
+current,1999-03-16,0m,0,0m!,0,0m,0,
+other,,6h50m,410,3h10m!,190,+3h40m,220,
+all,1999-03-16,6h50m,410,3h10m!,190,+3h40m,220,
+`, state.printBuffer)
+}
@@ -4,15 +4,19 @@ import (
 	"fmt"
 	"github.com/jotaen/klog/klog/app"
 	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/jotaen/klog/klog/parser/json"
 	"github.com/jotaen/klog/klog/service"
+	"strconv"
 )
 
 type Total struct {
 	lib.FilterArgs
 	lib.DiffArgs
 	lib.NowArgs
+	lib.OutputArgs
 	lib.DecimalArgs
 	lib.WarnArgs
+	lib.LenientArgs
 	lib.NoStyleArgs
 	lib.InputFilesArgs
 }
@@ -22,23 +26,45 @@ func (opt *Total) Help() string {
 
 Note that the total time by default doesn’t include open-ended time ranges.
 If you want to factor them in anyway, you can use the --now option,
-which treats all open-ended time ranges as if they were closed “right now”.`
+which treats all open-ended time ranges as if they were closed “right now”.
+
+With --output json, csv, or tsv, the result is printed in a machine-readable format,
+which always includes the should-total time and the difference.`
 }
 
 func (opt *Total) Run(ctx app.Context) app.Error {
+	if opt.IsMachineReadable() {
+		opt.Diff = true
+	}
 	opt.DecimalArgs.Apply(&ctx)
 	opt.NoStyleArgs.Apply(&ctx)
-	records, err := ctx.ReadInputs(opt.File...)
+	records, err := opt.LenientArgs.ReadInputs(ctx, opt.File...)
 	if err != nil {
 		return err
 	}
 	now := ctx.Now()
-	records = opt.ApplyFilter(now, records)
+	registry, rErr := ctx.ReadTagRegistry()
+	if rErr != nil {
+		return rErr
+	}
+	records = opt.ApplyFilter(now, registry, records)
 	nErr := opt.ApplyNow(now, records...)
 	if nErr != nil {
 		return nErr
 	}
+	sErr := opt.ApplyShouldTotals(ctx, records...)
+	if sErr != nil {
+		return sErr
+	}
 	total := service.Total(records...)
+	if opt.IsMachineReadable() {
+		should := service.ShouldTotalSum(records...)
+		opt.printMachineReadable(ctx, json.TotalEnvelop{
+			EvaluationView: json.NewEvaluationView(total, should, service.Diff(should, total)),
+			Records:        len(records),
+		})
+		return nil
+	}
 	ctx.Print(fmt.Sprintf("Total: %s\n", ctx.Serialiser().Duration(total)))
 	if opt.Diff {
 		should := service.ShouldTotalSum(records...)
@@ -56,3 +82,15 @@ func (opt *Total) Run(ctx app.Context) app.Error {
 	opt.WarnArgs.PrintWarnings(ctx, records, opt.GetNowWarnings())
 	return nil
 }
+
+func (opt *Total) printMachineReadable(ctx app.Context, envelop json.TotalEnvelop) {
+	if opt.Output == "json" {
+		ctx.Print(json.TotalToJson(envelop, opt.Pretty) + "\n")
+		return
+	}
+	opt.PrintRows(ctx, [][]string{{
+		"total", "total_mins", "should_total", "should_total_mins", "diff", "diff_mins", "records",
+	}, {
+		envelop.Total, strconv.Itoa(envelop.TotalMins), envelop.ShouldTotal, strconv.Itoa(envelop.ShouldTotalMins), envelop.Diff, strconv.Itoa(envelop.DiffMins), strconv.Itoa(envelop.Records),
+	}})
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"github.com/jotaen/klog/klog/app"
 	"github.com/jotaen/klog/klog/app/cli/lib"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -41,6 +42,39 @@ func TestTotalWithDiffing(t *testing.T) {
 	assert.Equal(t, "\nTotal: 16h30m\nShould: 15h45m!\nDiff: +45m\n(In 2 records)\n", state.printBuffer)
 }
 
+func TestTotalWithDiffingFromShouldTotalSchedule(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+2023-05-04
+	8h30m
+
+2023-05-05
+	5h
+
+2023-05-06
+	1h
+
+2023-05-08 (4h!)
+	4h
+`)._SetFileConfig(`should_total_schedule = mon-thu: 8h, fri: 6h`)._Run((&Total{DiffArgs: lib.DiffArgs{Diff: true}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\nTotal: 18h30m\nShould: 18h!\nDiff: +30m\n(In 4 records)\n", state.printBuffer)
+}
+
+func TestTotalWithDiffingReducedByAbsences(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+2023-05-04
+	8h30m
+
+2023-05-05
+	2h
+`)._SetFileConfig(`should_total_schedule = mon-fri: 8h`)._SetCalendar("BEGIN:VCALENDAR\n" +
+		"BEGIN:VEVENT\nDTSTART:20230505T120000\nDTEND:20230505T180000\nSUMMARY:Half day off\nEND:VEVENT\n" +
+		"END:VCALENDAR\n",
+	)._Run((&Total{DiffArgs: lib.DiffArgs{Diff: true}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\nTotal: 10h30m\nShould: 10h!\nDiff: +30m\n(In 2 records)\n", state.printBuffer)
+}
+
 func TestTotalWithNow(t *testing.T) {
 	state, err := NewTestingContext()._SetRecords(`
 2018-11-08 (8h!)
@@ -72,3 +106,99 @@ func TestTotalAsDecimal(t *testing.T) {
 	require.Nil(t, err)
 	assert.Equal(t, "\nTotal: 510\n(In 1 record)\n", state.printBuffer)
 }
+
+func TestTotalAsJsonAndTsv(t *testing.T) {
+	records := `
+2018-11-08 (8h!)
+	8h30m
+
+2018-11-09 (7h45m!)
+	8:00 - 16:00
+`
+	state, err := NewTestingContext()._SetRecords(records)._Run((&Total{
+		OutputArgs: lib.OutputArgs{Output: "json"},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\n"+`{"total":"16h30m","total_mins":990,"should_total":"15h45m!","should_total_mins":945,"diff":"+45m","diff_mins":45,"records":2}`+"\n", state.printBuffer)
+
+	state, err = NewTestingContext()._SetRecords(records)._Run((&Total{
+		OutputArgs: lib.OutputArgs{Output: "tsv"},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\n"+
+		"total\ttotal_mins\tshould_total\tshould_total_mins\tdiff\tdiff_mins\trecords\n"+
+		"16h30m\t990\t15h45m!\t945\t+45m\t45\t2\n",
+		state.printBuffer)
+}
+
+func TestTotalOmitsWarningsOfChecksAcrossRecords(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+2020-01-01
+	8:00-23:00
+
+2020-01-02
+	7:00-23:00
+`)._SetFileConfig(`
+max_daily_work_time = 15h
+min_rest_period = 11h
+max_weekly_work_time = 20h
+`)._SetNow(2020, 1, 10, 0, 0)._Run((&Total{}).Run)
+	require.Nil(t, err)
+	assert.Contains(t, state.printBuffer, "2020-01-02: Daily work time exceeds the maximum")
+	assert.NotContains(t, state.printBuffer, "Rest period")
+	assert.NotContains(t, state.printBuffer, "Weekly work time")
+}
+
+func TestTotalFailsOnSyntaxErrors(t *testing.T) {
+	_, err := NewTestingContext()._SetFile("/tmp/a.klg", `
+2018-11-08
+	1h
+
+2018-11-09
+	foo
+`)._Run((&Total{InputFilesArgs: lib.InputFilesArgs{File: []app.FileOrBookmarkName{"/tmp/a.klg"}}}).Run)
+	require.Error(t, err)
+}
+
+func TestTotalLenientlySkipsInvalidRecords(t *testing.T) {
+	file := `
+2018-11-08
+	1h
+
+2018-11-09
+	foo
+
+2018-11-10
+	2h
+`
+	for _, x := range []struct {
+		ctx TestingContext
+		opt Total
+		exp string
+	}{
+		{NewTestingContext(), Total{LenientArgs: lib.LenientArgs{Lenient: true}}, "\nTotal: 3h\n(In 2 records)\n"},
+		{NewTestingContext()._SetFileConfig(`parsing_mode = lenient`), Total{}, "\nTotal: 3h\n(In 2 records)\n"},
+		// The errors are printed even with --no-warn, or with machine-readable output.
+		{NewTestingContext(), Total{LenientArgs: lib.LenientArgs{Lenient: true}, WarnArgs: lib.WarnArgs{NoWarn: true}}, "\nTotal: 3h\n(In 2 records)\n"},
+		{NewTestingContext(), Total{LenientArgs: lib.LenientArgs{Lenient: true}, OutputArgs: lib.OutputArgs{Output: "csv"}}, "\n" +
+			"total,total_mins,should_total,should_total_mins,diff,diff_mins,records\n" +
+			"3h,180,0m!,0,+3h,180,2\n"},
+	} {
+		x.opt.InputFilesArgs = lib.InputFilesArgs{File: []app.FileOrBookmarkName{"/tmp/a.klg"}}
+		state, err := x.ctx._SetFile("/tmp/a.klg", file)._Run(x.opt.Run)
+		require.Nil(t, err)
+		assert.Equal(t, x.exp, state.printBuffer)
+		assert.Equal(t, " WARNING  Skipped invalid record: Malformed entry (/tmp/a.klg:6)\n", state.stderrBuffer)
+	}
+}
+
+func TestTotalStrictlyOverridesLenientConfig(t *testing.T) {
+	_, err := NewTestingContext()._SetFileConfig(`parsing_mode = lenient`)._SetFile("/tmp/a.klg", `
+2018-11-09
+	foo
+`)._Run((&Total{
+		LenientArgs:    lib.LenientArgs{Strict: true},
+		InputFilesArgs: lib.InputFilesArgs{File: []app.FileOrBookmarkName{"/tmp/a.klg"}},
+	}).Run)
+	require.Error(t, err)
+}
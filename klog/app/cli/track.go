@@ -13,6 +13,7 @@ type Track struct {
 	lib.NoStyleArgs
 	lib.OutputFileArgs
 	lib.WarnArgs
+	lib.DryRunArgs
 }
 
 func (opt *Track) Help() string {
@@ -28,11 +29,11 @@ func (opt *Track) Run(ctx app.Context) app.Error {
 	opt.NoStyleArgs.Apply(&ctx)
 	now := ctx.Now()
 	date := opt.AtDate(now)
-	additionalData := reconciling.AdditionalData{}
-	ctx.Config().DefaultShouldTotal.Map(func(s klog.ShouldTotal) {
-		additionalData.ShouldTotal = s
+	additionalData := reconciling.AdditionalData{ShouldTotal: lib.ConfiguredShouldTotal(ctx, date)}
+	ctx.Config().AppendNewRecords.Map(func(a bool) {
+		additionalData.AppendAtEnd = a
 	})
-	return lib.Reconcile(ctx, lib.ReconcileOpts{OutputFileArgs: opt.OutputFileArgs, WarnArgs: opt.WarnArgs},
+	return lib.Reconcile(ctx, lib.ReconcileOpts{OutputFileArgs: opt.OutputFileArgs, WarnArgs: opt.WarnArgs, DryRunArgs: opt.DryRunArgs},
 		[]reconciling.Creator{
 			reconciling.NewReconcilerAtRecord(date),
 			reconciling.NewReconcilerForNewRecord(date, opt.DateFormat(ctx.Config()), additionalData),
@@ -158,3 +158,25 @@ date_format = YYYY/MM/DD
 `, state.writtenFileContents)
 	}
 }
+
+func TestTrackWithDryRunPrintsDiff(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+1855-04-25
+	1h
+`)._Run((&Track{
+		Entry:          klog.Ɀ_EntrySummary_("2h"),
+		AtDateArgs:     lib.AtDateArgs{Date: klog.Ɀ_Date_(1855, 4, 25)},
+		OutputFileArgs: lib.OutputFileArgs{File: "test.klg"},
+		DryRunArgs:     lib.DryRunArgs{DryRun: true},
+	}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "", state.writtenFileContents)
+	assert.Equal(t, `
+--- test.klg
++++ test.klg
+@@ -2,2 +2,3 @@
+ 1855-04-25
+ 	1h
++	2h
+`, state.printBuffer)
+}
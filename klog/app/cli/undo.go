@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/jotaen/klog/klog/app"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+)
+
+type Undo struct {
+	Count int `name:"count" short:"n" default:"1" help:"The number of changes to revert"`
+	lib.OutputFileArgs
+	lib.NoStyleArgs
+}
+
+func (opt *Undo) Help() string {
+	return `Reverts the latest change(s) that klog made to a file, e.g. via 'klog track' or 'klog start'.
+It prints the differences between the current and the restored file contents.
+
+klog keeps a journal of the latest changes of every file in the klog config folder. A change can only be undone if the file wasn’t modified otherwise afterwards (e.g., manually in an editor).
+
+Run 'klog history' to see which changes are recorded for a file.`
+}
+
+func (opt *Undo) Run(ctx app.Context) app.Error {
+	opt.NoStyleArgs.Apply(&ctx)
+	reverted, err := ctx.UndoFile(opt.File, opt.Count)
+	if err != nil {
+		return err
+	}
+	latest, earliest := reverted[0], reverted[len(reverted)-1]
+	ctx.Print(lib.PrettifyDiff(ctx.Serialiser(), latest.Path, latest.After, earliest.Before))
+	ctx.Print(fmt.Sprintf("\nReverted %d change(s)\n", len(reverted)))
+	return nil
+}
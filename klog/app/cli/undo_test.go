@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"github.com/jotaen/klog/klog/app"
+	"github.com/jotaen/klog/klog/app/cli/lib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	gotime "time"
+)
+
+func TestUndoRevertsLatestChange(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+2020-01-01
+	1h
+	2h
+`)._SetJournal(
+		app.JournalEntry{Path: "/tmp/test.klg", Time: gotime.Now(), Before: "\n2020-01-01\n", After: "\n2020-01-01\n\t1h\n"},
+		app.JournalEntry{Path: "/tmp/test.klg", Time: gotime.Now(), Before: "\n2020-01-01\n\t1h\n", After: "\n2020-01-01\n\t1h\n\t2h\n"},
+	)._Run((&Undo{Count: 1, OutputFileArgs: lib.OutputFileArgs{File: "/tmp/test.klg"}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\n2020-01-01\n\t1h\n", state.writtenFileContents)
+	assert.Equal(t, `
+--- /tmp/test.klg
++++ /tmp/test.klg
+@@ -2,3 +2,2 @@
+ 2020-01-01
+ 	1h
+-	2h
+
+Reverted 1 change(s)
+`, state.printBuffer)
+}
+
+func TestUndoRevertsMultipleChanges(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+2020-01-01
+	1h
+	2h
+`)._SetJournal(
+		app.JournalEntry{Path: "/tmp/test.klg", Time: gotime.Now(), Before: "\n2020-01-01\n", After: "\n2020-01-01\n\t1h\n"},
+		app.JournalEntry{Path: "/tmp/test.klg", Time: gotime.Now(), Before: "\n2020-01-01\n\t1h\n", After: "\n2020-01-01\n\t1h\n\t2h\n"},
+	)._Run((&Undo{Count: 2, OutputFileArgs: lib.OutputFileArgs{File: "/tmp/test.klg"}}).Run)
+	require.Nil(t, err)
+	assert.Equal(t, "\n2020-01-01\n", state.writtenFileContents)
+}
+
+func TestUndoFailsIfFileWasModified(t *testing.T) {
+	state, err := NewTestingContext()._SetRecords(`
+2020-01-01
+	1h
+	3h
+`)._SetJournal(
+		app.JournalEntry{Path: "/tmp/test.klg", Time: gotime.Now(), Before: "\n2020-01-01\n\t1h\n", After: "\n2020-01-01\n\t1h\n\t2h\n"},
+	)._Run((&Undo{Count: 1, OutputFileArgs: lib.OutputFileArgs{File: "/tmp/test.klg"}}).Run)
+	require.Error(t, err)
+	assert.Equal(t, "Cannot undo", err.Error())
+	assert.Equal(t, "", state.writtenFileContents)
+}
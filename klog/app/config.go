@@ -36,11 +36,51 @@ type Config struct {
 	// DefaultShouldTotal is the default should total for new records.
 	DefaultShouldTotal OptionalParam[klog.ShouldTotal]
 
+	// ShouldTotalSchedule are the should totals per weekday. They apply to all
+	// records that don’t have a should total of their own.
+	ShouldTotalSchedule OptionalParam[service.ShouldTotalSchedule]
+
+	// CalendarFiles are the paths of iCalendar files that contain absences,
+	// such as public holidays or vacation.
+	CalendarFiles OptionalParam[[]string]
+
+	// BillingRounding is the default for the --round flag of `klog invoice`.
+	BillingRounding OptionalParam[service.Rounding]
+
+	// InitialBalance is the overtime balance prior to the first record.
+	InitialBalance OptionalParam[klog.Duration]
+
 	// DateUseDashes denotes the preferred date format: YYYY-MM-DD (true) or YYYY/MM/DD (false).
 	DateUseDashes OptionalParam[bool]
 
 	// TimeUse24HourClock denotes the preferred time format: 13:00 (true) or 1:00pm (false).
 	TimeUse24HourClock OptionalParam[bool]
+
+	// AppendNewRecords denotes where new records are added to a file: at the
+	// end of the file (true), or at their chronological position (false).
+	AppendNewRecords OptionalParam[bool]
+
+	// CheckRules are the severities of the checks, as far as they deviate
+	// from the default.
+	CheckRules OptionalParam[service.CheckRules]
+
+	// MaxDailyWorkTime is the maximum work time per day.
+	MaxDailyWorkTime OptionalParam[klog.Duration]
+
+	// MaxWeeklyWorkTime is the maximum work time per calendar week.
+	MaxWeeklyWorkTime OptionalParam[klog.Duration]
+
+	// MinRestPeriod is the minimum time between the last entry of a day and the
+	// first entry of the next day.
+	MinRestPeriod OptionalParam[klog.Duration]
+
+	// RequiredBreaks are the minimum break times from certain work times on.
+	RequiredBreaks OptionalParam[service.BreakRequirements]
+
+	// LenientParsing is the default for the --lenient flag: whether records
+	// with syntax errors are skipped (true), or whether they fail the
+	// command (false).
+	LenientParsing OptionalParam[bool]
 }
 
 type Reader interface {
@@ -60,12 +100,16 @@ func NewConfig(c1 FromStaticValues, c2 FromEnvVars, c3 FromConfigFile) (Config,
 
 func NewDefaultConfig() Config {
 	return Config{
-		IsDebug:            newMandatoryParam(false),
-		Editor:             newMandatoryParam(""),
-		NoColour:           newMandatoryParam(false),
-		CpuKernels:         newMandatoryParam(1),
-		DefaultRounding:    newOptionalParam[service.Rounding](),
-		DefaultShouldTotal: newOptionalParam[klog.ShouldTotal](),
+		IsDebug:             newMandatoryParam(false),
+		Editor:              newMandatoryParam(""),
+		NoColour:            newMandatoryParam(false),
+		CpuKernels:          newMandatoryParam(1),
+		DefaultRounding:     newOptionalParam[service.Rounding](),
+		DefaultShouldTotal:  newOptionalParam[klog.ShouldTotal](),
+		ShouldTotalSchedule: newOptionalParam[service.ShouldTotalSchedule](),
+		CalendarFiles:       newOptionalParam[[]string](),
+		BillingRounding:     newOptionalParam[service.Rounding](),
+		InitialBalance:      newOptionalParam[klog.Duration](),
 	}
 }
 
@@ -205,6 +249,98 @@ var CONFIG_FILE_ENTRIES = []ConfigFileEntries[any]{
 			Value:   "The config property must be a duration followed by an exclamation mark. Examples: `8h!`, `6h30m!`.",
 			Default: "If absent/empty, klog doesn’t set a should-total on new records.",
 		},
+	}, {
+		Name: "should_total_schedule",
+		Reader: func(value string, config *Config) error {
+			schedule, err := service.NewShouldTotalScheduleFromString(value)
+			if err != nil {
+				return err
+			}
+			config.ShouldTotalSchedule.set(schedule)
+			return nil
+		},
+		Value: func(c Config) string {
+			result := ""
+			c.ShouldTotalSchedule.Map(func(s service.ShouldTotalSchedule) {
+				result = s.ToString()
+			})
+			return result
+		},
+		Help: Help{
+			Summary: "The should-totals per day of the week. Evaluation commands (such as `klog today --diff`) use them for all records that don’t have a should-total of their own, and new records are created with them (unless `default_should_total` is set).",
+			Value:   "The config property must be a comma-separated list of weekdays (or ranges thereof) and durations. Weekdays are `mon`, `tue`, `wed`, `thu`, `fri`, `sat`, `sun`. Example: `mon-thu: 8h, fri: 6h`.",
+			Default: "If absent/empty, records without should-total are evaluated as having none.",
+		},
+	}, {
+		Name: "calendar_files",
+		Reader: func(value string, config *Config) error {
+			var paths []string
+			for _, p := range strings.Split(value, ",") {
+				p = strings.TrimSpace(p)
+				if p == "" {
+					return errors.New("Empty file path")
+				}
+				paths = append(paths, p)
+			}
+			config.CalendarFiles.set(paths)
+			return nil
+		},
+		Value: func(c Config) string {
+			result := ""
+			c.CalendarFiles.Map(func(ps []string) {
+				result = strings.Join(ps, ", ")
+			})
+			return result
+		},
+		Help: Help{
+			Summary: "iCalendar files (`.ics`) with absences, such as public holidays, vacation or sick days. On these dates, evaluation commands (such as `klog today --diff`) reduce the should-total accordingly, and `klog report --fill` displays them.",
+			Value:   "The config property must be a comma-separated list of file paths. A leading `~` stands for the home folder. Relative paths are resolved against the klog config folder.",
+			Default: "If absent/empty, klog doesn’t take any absences into account.",
+		},
+	}, {
+		Name: "billing_rounding",
+		Reader: func(value string, config *Config) error {
+			rounding, err := service.NewRoundingFromString(value)
+			if err != nil {
+				return err
+			}
+			config.BillingRounding.set(rounding)
+			return nil
+		},
+		Value: func(c Config) string {
+			result := ""
+			c.BillingRounding.Map(func(r service.Rounding) {
+				result = r.ToString()
+			})
+			return result
+		},
+		Help: Help{
+			Summary: "The default value by which `klog invoice` rounds up the duration of each entry, e.g. in `klog invoice --round 15m`.",
+			Value:   "The config property must be one of: `5m`, `10m`, `15m`, `30m`, `60m`.",
+			Default: "If absent/empty, klog bills the exact durations.",
+		},
+	}, {
+		Name: "initial_balance",
+		Reader: func(value string, config *Config) error {
+			d, err := klog.NewDurationFromString(value)
+			if err != nil {
+				return err
+			}
+			config.InitialBalance.set(klog.NewDuration(0, d.InMinutes()))
+			return nil
+		},
+		Value: func(c Config) string {
+			result := ""
+			c.InitialBalance.Map(func(d klog.Duration) {
+				result = d.ToStringWithSign()
+			})
+			return result
+		},
+		Help: Help{
+			Summary: "The overtime balance that shall be carried over into the running balance of `klog report --balance`, e.g. from before you started using klog.",
+			Value:   "The config property must be a duration, optionally prefixed by a sign. Examples: `+12h`, `-4h30m`.",
+			Default: "If absent/empty, the running balance starts at zero.",
+		},
 	}, {
 		Name: "date_format",
 		Reader: func(value string, config *Config) error {
@@ -265,6 +401,185 @@ var CONFIG_FILE_ENTRIES = []ConfigFileEntries[any]{
 			Value:   "The config property must be either `24h` or `12h`.",
 			Default: "If absent/empty, klog automatically tries to be consistent with what is used in the target file; in doubt, it defaults to the 24-hour clock format.",
 		},
+	}, {
+		Name: "new_record_position",
+		Reader: func(value string, config *Config) error {
+			appendAtEnd := false
+			if value == "chronological" {
+				appendAtEnd = false
+			} else if value == "end" {
+				appendAtEnd = true
+			} else {
+				return errors.New("The value must be `chronological` or `end`")
+			}
+			config.AppendNewRecords.set(appendAtEnd)
+			return nil
+		},
+		Value: func(c Config) string {
+			result := ""
+			c.AppendNewRecords.Map(func(a bool) {
+				if a {
+					result = "end"
+				} else {
+					result = "chronological"
+				}
+			})
+			return result
+		},
+		Help: Help{
+			Summary: "Where klog adds a new record to a target file (e.g., when running `klog track` for a date that doesn’t have a record yet).",
+			Value:   "The config property must be either `chronological` (at the position according to its date, respecting whether the records in the file are sorted in ascending or descending order) or `end` (at the end of the file).",
+			Default: "If absent/empty, new records are inserted at their chronological position.",
+		},
+	}, {
+		Name: "check_rules",
+		Reader: func(value string, config *Config) error {
+			rules, err := service.NewCheckRulesFromString(value)
+			if err != nil {
+				return err
+			}
+			config.CheckRules.set(rules)
+			return nil
+		},
+		Value: func(c Config) string {
+			result := ""
+			c.CheckRules.Map(func(r service.CheckRules) {
+				result = r.ToString()
+			})
+			return result
+		},
+		Help: Help{
+			Summary: "The severities of the checks that klog performs on the records, e.g. when running `klog check`. Checks that are `off` are neither reported by `klog check` nor as warnings by other commands. Checks that are `error` make `klog check` fail.",
+			Value:   "The config property must be a comma-separated list of checks and severities. The checks are `unclosed-open-range`, `future-entry`, `overlapping-ranges`, `more-than-24h`, `unknown-tag`, `max-daily-work`, `required-breaks`, `min-rest`, `max-weekly-work`, `duplicate-record`, `missing-workday`, `overlap-at-midnight`, `concurrent-open-ranges` (see `klog check --help`). The severities are `off`, `warning`, `error`. Example: `unknown-tag: error, future-entry: off`.",
+			Default: "If absent/empty, all checks are reported as warnings, except for `missing-workday`, which is off.",
+		},
+	}, {
+		Name: "max_daily_work_time",
+		Reader: func(value string, config *Config) error {
+			d, err := klog.NewDurationFromString(value)
+			if err != nil {
+				return err
+			}
+			if d.InMinutes() <= 0 {
+				return errors.New("The duration must be positive")
+			}
+			config.MaxDailyWorkTime.set(d)
+			return nil
+		},
+		Value: func(c Config) string {
+			result := ""
+			c.MaxDailyWorkTime.Map(func(d klog.Duration) {
+				result = d.ToString()
+			})
+			return result
+		},
+		Help: Help{
+			Summary: "The maximum work time per day. `klog check` (and the warnings of other commands) report records whose total time exceeds it (check `max-daily-work`).",
+			Value:   "The config property must be a positive duration. Example: `10h`.",
+			Default: "If absent/empty, klog doesn’t check this.",
+		},
+	}, {
+		Name: "max_weekly_work_time",
+		Reader: func(value string, config *Config) error {
+			d, err := klog.NewDurationFromString(value)
+			if err != nil {
+				return err
+			}
+			if d.InMinutes() <= 0 {
+				return errors.New("The duration must be positive")
+			}
+			config.MaxWeeklyWorkTime.set(d)
+			return nil
+		},
+		Value: func(c Config) string {
+			result := ""
+			c.MaxWeeklyWorkTime.Map(func(d klog.Duration) {
+				result = d.ToString()
+			})
+			return result
+		},
+		Help: Help{
+			Summary: "The maximum work time per calendar week. `klog check` reports weeks whose total time exceeds it (check `max-weekly-work`). The warnings of other commands don’t include this check, since it depends on all records.",
+			Value:   "The config property must be a positive duration. Example: `48h`.",
+			Default: "If absent/empty, klog doesn’t check this.",
+		},
+	}, {
+		Name: "min_rest_period",
+		Reader: func(value string, config *Config) error {
+			d, err := klog.NewDurationFromString(value)
+			if err != nil {
+				return err
+			}
+			if d.InMinutes() <= 0 {
+				return errors.New("The duration must be positive")
+			}
+			config.MinRestPeriod.set(d)
+			return nil
+		},
+		Value: func(c Config) string {
+			result := ""
+			c.MinRestPeriod.Map(func(d klog.Duration) {
+				result = d.ToString()
+			})
+			return result
+		},
+		Help: Help{
+			Summary: "The minimum rest period between the last time range of a day and the first time range of the next day. `klog check` reports days after which the rest period is shorter (check `min-rest`). The warnings of other commands don’t include this check, since it depends on all records.",
+			Value:   "The config property must be a positive duration. Example: `11h`.",
+			Default: "If absent/empty, klog doesn’t check this.",
+		},
+	}, {
+		Name: "required_breaks",
+		Reader: func(value string, config *Config) error {
+			requirements, err := service.NewBreakRequirementsFromString(value)
+			if err != nil {
+				return err
+			}
+			config.RequiredBreaks.set(requirements)
+			return nil
+		},
+		Value: func(c Config) string {
+			result := ""
+			c.RequiredBreaks.Map(func(bs service.BreakRequirements) {
+				result = bs.ToString()
+			})
+			return result
+		},
+		Help: Help{
+			Summary: "The minimum break times for work times of more than a certain duration. `klog check` (and the warnings of other commands) report records whose breaks are shorter (check `required-breaks`). The breaks are the gaps between the time ranges of a record, plus all negative durations. Records without time ranges are not checked.",
+			Value:   "The config property must be a comma-separated list of work times and break times. Example: `6h: 30m, 9h: 45m`.",
+			Default: "If absent/empty, klog doesn’t check this.",
+		},
+	}, {
+		Name: "parsing_mode",
+		Reader: func(value string, config *Config) error {
+			lenient := false
+			if value == "strict" {
+				lenient = false
+			} else if value == "lenient" {
+				lenient = true
+			} else {
+				return errors.New("The value must be `strict` or `lenient`")
+			}
+			config.LenientParsing.set(lenient)
+			return nil
+		},
+		Value: func(c Config) string {
+			result := ""
+			c.LenientParsing.Map(func(l bool) {
+				if l {
+					result = "lenient"
+				} else {
+					result = "strict"
+				}
+			})
+			return result
+		},
+		Help: Help{
+			Summary: "How evaluation commands (such as `klog total` or `klog report`) deal with syntax errors in the input files. In strict mode, they fail and print the errors. In lenient mode, they skip the invalid records, compute with the valid ones, and print the errors as warnings to stderr (this is the same as the --lenient flag). You can still parse strictly for a single run with --strict.",
+			Value:   "The config property must be either `strict` or `lenient`.",
+			Default: "If absent/empty, klog parses strictly.",
+		},
 	},
 }
 
@@ -4,6 +4,7 @@ import (
 	"github.com/jotaen/klog/klog"
 	"github.com/jotaen/klog/klog/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 )
 
@@ -124,6 +125,91 @@ func TestSetsDefaultShouldTotalParamFromConfigFile(t *testing.T) {
 	}
 }
 
+func TestSetsShouldTotalScheduleParamFromConfigFile(t *testing.T) {
+	for _, x := range []struct {
+		cfg string
+		exp string
+	}{
+		{`should_total_schedule = mon-thu: 8h, fri: 6h`, "mon-thu: 8h!, fri: 6h!"},
+		{`should_total_schedule = Tue: 4h!`, "tue: 4h!"},
+	} {
+		c, _ := NewConfig(
+			FromStaticValues{NumCpus: 1},
+			createMockConfigFromEnv(map[string]string{}),
+			FromConfigFile{x.cfg},
+		)
+		var value string
+		c.ShouldTotalSchedule.Map(func(s service.ShouldTotalSchedule) {
+			value = s.ToString()
+		})
+		assert.Equal(t, x.exp, value)
+	}
+}
+
+func TestSetsCalendarFilesParamFromConfigFile(t *testing.T) {
+	for _, x := range []struct {
+		cfg string
+		exp []string
+	}{
+		{`calendar_files = holidays.ics`, []string{"holidays.ics"}},
+		{`calendar_files = /tmp/a.ics,  ~/b c.ics `, []string{"/tmp/a.ics", "~/b c.ics"}},
+	} {
+		c, _ := NewConfig(
+			FromStaticValues{NumCpus: 1},
+			createMockConfigFromEnv(map[string]string{}),
+			FromConfigFile{x.cfg},
+		)
+		var value []string
+		c.CalendarFiles.Map(func(ps []string) {
+			value = ps
+		})
+		assert.Equal(t, x.exp, value)
+	}
+}
+
+func TestSetsInitialBalanceParamFromConfigFile(t *testing.T) {
+	for _, x := range []struct {
+		cfg string
+		exp string
+	}{
+		{`initial_balance = 12h`, "+12h"},
+		{`initial_balance = +1h30m`, "+1h30m"},
+		{`initial_balance = -45m`, "-45m"},
+	} {
+		c, _ := NewConfig(
+			FromStaticValues{NumCpus: 1},
+			createMockConfigFromEnv(map[string]string{}),
+			FromConfigFile{x.cfg},
+		)
+		var value string
+		c.InitialBalance.Map(func(d klog.Duration) {
+			value = d.ToStringWithSign()
+		})
+		assert.Equal(t, x.exp, value)
+	}
+}
+
+func TestSetsBillingRoundingParamFromConfigFile(t *testing.T) {
+	for _, x := range []struct {
+		cfg string
+		exp string
+	}{
+		{`billing_rounding = 15m`, "15m"},
+		{`billing_rounding = 1h`, "60m"},
+	} {
+		c, _ := NewConfig(
+			FromStaticValues{NumCpus: 1},
+			createMockConfigFromEnv(map[string]string{}),
+			FromConfigFile{x.cfg},
+		)
+		var value string
+		c.BillingRounding.Map(func(r service.Rounding) {
+			value = r.ToString()
+		})
+		assert.Equal(t, x.exp, value)
+	}
+}
+
 func TestSetsDateFormatParamFromConfigFile(t *testing.T) {
 	for _, x := range []struct {
 		cfg string
@@ -166,6 +252,96 @@ func TestSetTimeFormatParamFromConfigFile(t *testing.T) {
 	}
 }
 
+func TestSetsNewRecordPositionParamFromConfigFile(t *testing.T) {
+	for _, x := range []struct {
+		cfg string
+		exp bool
+	}{
+		{`new_record_position = chronological`, false},
+		{`new_record_position = end`, true},
+	} {
+		c, _ := NewConfig(
+			FromStaticValues{NumCpus: 1},
+			createMockConfigFromEnv(map[string]string{}),
+			FromConfigFile{x.cfg},
+		)
+		value := !x.exp
+		c.AppendNewRecords.Map(func(a bool) {
+			value = a
+		})
+		assert.Equal(t, x.exp, value)
+	}
+}
+
+func TestSetsCheckRulesParamFromConfigFile(t *testing.T) {
+	c, _ := NewConfig(
+		FromStaticValues{NumCpus: 1},
+		createMockConfigFromEnv(map[string]string{}),
+		FromConfigFile{`check_rules = unknown-tag: error, future-entry: off`},
+	)
+	var value service.CheckRules
+	c.CheckRules.Map(func(r service.CheckRules) {
+		value = r
+	})
+	assert.Equal(t, service.SEVERITY_ERROR, value.Severity(service.RULE_UNKNOWN_TAG))
+	assert.Equal(t, service.SEVERITY_OFF, value.Severity(service.RULE_FUTURE_ENTRY))
+	assert.Equal(t, service.SEVERITY_WARNING, value.Severity(service.RULE_MORE_THAN_24H))
+}
+
+func TestSetsWorkTimeLimitParamsFromConfigFile(t *testing.T) {
+	c, err := NewConfig(
+		FromStaticValues{NumCpus: 1},
+		createMockConfigFromEnv(map[string]string{}),
+		FromConfigFile{`
+max_daily_work_time = 10h
+max_weekly_work_time = 48h
+min_rest_period = 11h
+required_breaks = 6h: 30m, 9h: 45m
+`},
+	)
+	require.Nil(t, err)
+	for _, x := range []struct {
+		param OptionalParam[klog.Duration]
+		exp   klog.Duration
+	}{
+		{c.MaxDailyWorkTime, klog.NewDuration(10, 0)},
+		{c.MaxWeeklyWorkTime, klog.NewDuration(48, 0)},
+		{c.MinRestPeriod, klog.NewDuration(11, 0)},
+	} {
+		var value klog.Duration
+		x.param.Map(func(d klog.Duration) {
+			value = d
+		})
+		assert.Equal(t, x.exp, value)
+	}
+	var breaks service.BreakRequirements
+	c.RequiredBreaks.Map(func(bs service.BreakRequirements) {
+		breaks = bs
+	})
+	assert.Equal(t, "6h: 30m, 9h: 45m", breaks.ToString())
+}
+
+func TestSetsParsingModeParamFromConfigFile(t *testing.T) {
+	for _, x := range []struct {
+		cfg string
+		exp bool
+	}{
+		{`parsing_mode = strict`, false},
+		{`parsing_mode = lenient`, true},
+	} {
+		c, _ := NewConfig(
+			FromStaticValues{NumCpus: 1},
+			createMockConfigFromEnv(map[string]string{}),
+			FromConfigFile{x.cfg},
+		)
+		value := !x.exp
+		c.LenientParsing.Map(func(l bool) {
+			value = l
+		})
+		assert.Equal(t, x.exp, value)
+	}
+}
+
 func TestIgnoresUnknownPropertiesInConfigFile(t *testing.T) {
 	for _, tml := range []string{`
 unknown_property = 1
@@ -187,8 +363,19 @@ func TestIgnoresEmptyConfigFileOrEmptyParameters(t *testing.T) {
 		`editor = `,
 		`default_rounding =`,
 		`default_should_total = `,
+		`should_total_schedule = `,
+		`calendar_files = `,
+		`initial_balance = `,
+		`billing_rounding = `,
 		`date_format = `,
 		`time_convention = `,
+		`new_record_position = `,
+		`check_rules = `,
+		`max_daily_work_time = `,
+		`max_weekly_work_time = `,
+		`min_rest_period = `,
+		`required_breaks = `,
+		`parsing_mode = `,
 	} {
 		_, err := NewConfig(
 			FromStaticValues{NumCpus: 1},
@@ -209,6 +396,14 @@ func TestRejectsInvalidConfigFile(t *testing.T) {
 		`date_format = YYYY.MM.DD`,             // Invalid value
 		`time_convention = [true, false]`,      // Wrong type
 		`time_convention = 2h`,                 // Invalid value
+		`new_record_position = start`,          // Invalid value
+		`check_rules = unknown-tag`,            // Missing severity
+		`check_rules = foo: error`,             // Unknown rule
+		`check_rules = unknown-tag: fatal`,     // Invalid severity
+		`max_daily_work_time = 0m`,             // Not positive
+		`min_rest_period = 11`,                 // Invalid value
+		`required_breaks = 6h`,                 // Missing break time
+		`parsing_mode = tolerant`,              // Invalid value
 	} {
 		_, err := NewConfig(
 			FromStaticValues{NumCpus: 1},
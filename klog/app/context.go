@@ -13,6 +13,9 @@ import (
 	"github.com/jotaen/klog/klog/parser"
 	"github.com/jotaen/klog/klog/parser/reconciling"
 	"github.com/jotaen/klog/klog/parser/txt"
+	"github.com/jotaen/klog/klog/service"
+	"github.com/jotaen/klog/klog/service/billing"
+	"github.com/jotaen/klog/klog/service/calendar"
 	"os"
 	"os/exec"
 	gotime "time"
@@ -25,6 +28,9 @@ type FileOrBookmarkName string
 const (
 	BOOKMARKS_FILE_NAME = "bookmarks.json"
 	CONFIG_FILE_NAME    = "config.ini"
+	RATES_FILE_NAME     = "rates.ini"
+	TAGS_FILE_NAME      = "tags.ini"
+	JOURNAL_FILE_NAME   = "journal.json"
 )
 
 // Context is a representation of the runtime environment of klog.
@@ -33,6 +39,10 @@ type Context interface {
 	// Print prints to stdout.
 	Print(string)
 
+	// PrintToStderr prints to stderr. That is for messages that must not
+	// interfere with the regular output, e.g. when that is machine-readable.
+	PrintToStderr(string)
+
 	// ReadLine reads user input from stdin.
 	ReadLine() (string, Error)
 
@@ -45,11 +55,39 @@ type Context interface {
 	// ReadInputs retrieves all input from the given file or bookmark names.
 	ReadInputs(...FileOrBookmarkName) ([]klog.Record, Error)
 
+	// ReadInputFiles is like ReadInputs, but it returns the records per file,
+	// along with the text blocks that the records were parsed from.
+	// If `lenient` is true, it doesn’t fail on syntax errors, but it skips
+	// the invalid records and returns the errors as part of the ParsedFile.
+	ReadInputFiles(bool, ...FileOrBookmarkName) ([]ParsedFile, Error)
+
 	// RetrieveTargetFile returns the desired file, requiring that there is exactly one.
 	RetrieveTargetFile(fileArg FileOrBookmarkName) (FileWithContents, Error)
 
-	// ReconcileFile applies one or more reconcile handlers to a file and saves it.
-	ReconcileFile(FileOrBookmarkName, []reconciling.Creator, reconciling.Reconcile) (*reconciling.Result, Error)
+	// ReconcileFile applies one or more reconcile handlers to a file. It only saves
+	// the file if `write` is true. The file is locked while doing so, and the
+	// changes are only saved if the file wasn’t modified in the meantime.
+	// If `amendJournal` is true, the change is merged into the latest journal
+	// entry of the file, so that it can be undone together with that one.
+	ReconcileFile(fileArg FileOrBookmarkName, creators []reconciling.Creator, reconcile reconciling.Reconcile, write bool, amendJournal bool) (*reconciling.Result, Error)
+
+	// FormatFile applies a formatter to a file. It only saves the file if `write` is true.
+	FormatFile(FileOrBookmarkName, reconciling.Format, bool) (*reconciling.FormatResult, Error)
+
+	// CreateFile creates a new file with the given contents. It returns an
+	// error if the file already exists.
+	CreateFile(File, string) Error
+
+	// CreateFiles creates multiple new files. It either creates all of them,
+	// or none, e.g. if one of them already exists.
+	CreateFiles([]FileWithContents) Error
+
+	// ReadJournal returns the journal of the changes that klog made to files.
+	ReadJournal() (Journal, Error)
+
+	// UndoFile reverts the latest changes to a file, as recorded in the journal.
+	// It returns the reverted journal entries.
+	UndoFile(FileOrBookmarkName, int) ([]JournalEntry, Error)
 
 	// Now returns the current timestamp.
 	Now() gotime.Time
@@ -82,6 +120,15 @@ type Context interface {
 
 	// Config returns the current preferences.
 	Config() Config
+
+	// ReadCalendar reads and merges all calendar files from the config.
+	ReadCalendar() (calendar.Calendar, Error)
+
+	// ReadRates returns the billing rates of the user.
+	ReadRates() (billing.Rates, Error)
+
+	// ReadTagRegistry returns the known tags of the user, along with their aliases.
+	ReadTagRegistry() (service.TagRegistry, Error)
 }
 
 // Meta holds miscellaneous information about the klog binary.
@@ -127,6 +174,10 @@ func (ctx *context) Print(text string) {
 	fmt.Print(text)
 }
 
+func (ctx *context) PrintToStderr(text string) {
+	fmt.Fprint(os.Stderr, text)
+}
+
 func (ctx *context) ReadLine() (string, Error) {
 	scanner := bufio.NewScanner(os.Stdin)
 	if scanner.Scan() {
@@ -150,6 +201,46 @@ func (ctx *context) Meta() Meta {
 }
 
 func (ctx *context) ReadInputs(fileArgs ...FileOrBookmarkName) ([]klog.Record, Error) {
+	files, err := ctx.ReadInputFiles(false, fileArgs...)
+	if err != nil {
+		return nil, err
+	}
+	var allRecords []klog.Record
+	for _, f := range files {
+		allRecords = append(allRecords, f.Records...)
+	}
+	return allRecords, nil
+}
+
+// ParsedFile is an input file, along with its records and the text blocks
+// that the records were parsed from.
+type ParsedFile struct {
+	// File is the input file. Its path is empty if the input came from stdin.
+	File    FileWithContents
+	Records []klog.Record
+	Blocks  []txt.Block
+
+	// Errors are the syntax errors of the records that were skipped. It is
+	// only populated when parsing leniently.
+	Errors []txt.Error
+}
+
+// NewParsedFile creates a ParsedFile, and sets the source of every record
+// to its location in the file.
+func NewParsedFile(file FileWithContents, records []klog.Record, blocks []txt.Block) ParsedFile {
+	for i, r := range records {
+		significantLines, headCount, _ := blocks[i].SignificantLines()
+		firstLine := blocks[i].OverallLineIndex(headCount) + 1
+		r.SetSource(klog.Source{
+			Path:      file.Path(),
+			FirstLine: firstLine,
+			LastLine:  firstLine + len(significantLines) - 1,
+		})
+	}
+	return ParsedFile{file, records, blocks, nil}
+}
+
+func (ctx *context) ReadInputFiles(lenient bool, fileArgs ...FileOrBookmarkName) ([]ParsedFile, Error) {
 	bc, bErr := ctx.ReadBookmarks()
 	if bErr != nil {
 		return nil, bErr
@@ -172,15 +263,22 @@ func (ctx *context) ReadInputs(fileArgs ...FileOrBookmarkName) ([]klog.Record, E
 			nil,
 		)
 	}
-	var allRecords []klog.Record
+	var parsedFiles []ParsedFile
 	for _, f := range files {
-		records, _, errs := ctx.parser.Parse(f.Contents())
+		if lenient {
+			records, blocks, errs := ctx.parser.ParseLeniently(f.Contents())
+			parsedFile := NewParsedFile(f, records, blocks)
+			parsedFile.Errors = errs
+			parsedFiles = append(parsedFiles, parsedFile)
+			continue
+		}
+		records, blocks, errs := ctx.parser.Parse(f.Contents())
 		if errs != nil {
-			return nil, NewParserErrors(errs)
+			return nil, NewParserErrorsInFile(f.Path(), errs)
 		}
-		allRecords = append(allRecords, records...)
+		parsedFiles = append(parsedFiles, NewParsedFile(f, records, blocks))
 	}
-	return allRecords, nil
+	return parsedFiles, nil
 }
 
 func (ctx *context) RetrieveTargetFile(fileArg FileOrBookmarkName) (FileWithContents, Error) {
@@ -203,22 +301,140 @@ func (ctx *context) RetrieveTargetFile(fileArg FileOrBookmarkName) (FileWithCont
 	return inputs[0], nil
 }
 
-func (ctx *context) ReconcileFile(fileArg FileOrBookmarkName, creators []reconciling.Creator, reconcile reconciling.Reconcile) (*reconciling.Result, Error) {
+// retrieveAndLockTargetFile is like RetrieveTargetFile, but it also acquires
+// the lock for the file. The file contents are read only after the lock was
+// acquired, so that they reflect all changes of previous writers.
+func (ctx *context) retrieveAndLockTargetFile(fileArg FileOrBookmarkName) (FileWithContents, func(), Error) {
 	target, err := ctx.RetrieveTargetFile(fileArg)
+	if err != nil {
+		return nil, nil, err
+	}
+	unlock, lErr := LockFile(target)
+	if lErr != nil {
+		return nil, nil, lErr
+	}
+	contents, rErr := ReadFile(target)
+	if rErr != nil {
+		unlock()
+		return nil, nil, rErr
+	}
+	return &fileWithContents{target, contents}, unlock, nil
+}
+
+func (ctx *context) ReconcileFile(fileArg FileOrBookmarkName, creators []reconciling.Creator, reconcile reconciling.Reconcile, write bool, amendJournal bool) (*reconciling.Result, Error) {
+	target, unlock, err := ctx.retrieveAndLockTargetFile(fileArg)
 	if err != nil {
 		return nil, err
 	}
+	defer unlock()
 	records, blocks, errs := ctx.parser.Parse(target.Contents())
 	if errs != nil {
-		return nil, NewParserErrors(errs)
+		return nil, NewParserErrorsInFile(target.Path(), errs)
 	}
 	result, aErr := ApplyReconciler(records, blocks, creators, reconcile)
 	if aErr != nil {
 		return nil, aErr
 	}
-	wErr := WriteToFile(target, result.AllSerialised)
+	if write {
+		wErr := ctx.writeAndRecord(target, result.AllSerialised, amendJournal)
+		if wErr != nil {
+			return nil, wErr
+		}
+	}
+	return result, nil
+}
+
+func (ctx *context) FormatFile(fileArg FileOrBookmarkName, format reconciling.Format, write bool) (*reconciling.FormatResult, Error) {
+	target, unlock, err := ctx.retrieveAndLockTargetFile(fileArg)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	records, blocks, errs := ctx.parser.Parse(target.Contents())
+	if errs != nil {
+		return nil, NewParserErrorsInFile(target.Path(), errs)
+	}
+	result, fErr := ApplyFormatter(records, blocks, format)
+	if fErr != nil {
+		return nil, fErr
+	}
+	if write && result.HasChanged() {
+		wErr := ctx.writeAndRecord(target, result.Formatted, false)
+		if wErr != nil {
+			return nil, wErr
+		}
+	}
+	return result, nil
+}
+
+func (ctx *context) CreateFile(target File, contents string) Error {
+	return CreateFile(target, contents)
+}
+
+func (ctx *context) CreateFiles(targets []FileWithContents) Error {
+	return CreateFiles(targets)
+}
+
+func (ctx *context) UndoFile(fileArg FileOrBookmarkName, count int) ([]JournalEntry, Error) {
+	target, unlock, err := ctx.retrieveAndLockTargetFile(fileArg)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	var reverted []JournalEntry
+	jErr := ctx.manipulateJournal(func(j Journal) Error {
+		contents, entries, uErr := j.Undo(target, count)
+		if uErr != nil {
+			return uErr
+		}
+		reverted = entries
+		return WriteToFileIfUnchanged(target, contents)
+	})
+	if jErr != nil {
+		return nil, jErr
+	}
+	return reverted, nil
+}
+
+// writeAndRecord saves the new contents of a (previously locked) file, and
+// records the change in the journal. With `amend`, the change is merged into
+// the latest journal entry of the file.
+func (ctx *context) writeAndRecord(target FileWithContents, contents string, amend bool) Error {
+	wErr := WriteToFileIfUnchanged(target, contents)
 	if wErr != nil {
-		return nil, wErr
+		return wErr
+	}
+	// The file was written successfully at this point, so a failure to
+	// update the journal shouldn’t fail the entire operation.
+	jErr := ctx.manipulateJournal(func(j Journal) Error {
+		entry := JournalEntry{
+			Path:   target.Path(),
+			Time:   ctx.Now(),
+			Before: target.Contents(),
+			After:  contents,
+		}
+		if amend {
+			j.Amend(entry)
+		} else {
+			j.Append(entry)
+		}
+		return nil
+	})
+	if jErr != nil {
+		ctx.PrintToStderr("Warning: The change couldn’t be recorded in the journal, so it cannot be undone: " + jErr.Error() + "\n")
+	}
+	return nil
+}
+
+func ApplyFormatter(records []klog.Record, blocks []txt.Block, format reconciling.Format) (*reconciling.FormatResult, Error) {
+	result, err := format(records, blocks)
+	if err != nil {
+		return nil, NewErrorWithCode(
+			LOGICAL_ERROR,
+			"Formatting failed",
+			err.Error(),
+			err,
+		)
 	}
 	return result, nil
 }
@@ -303,6 +519,45 @@ func (ctx *context) ManipulateBookmarks(manipulate func(BookmarksCollection) Err
 	return WriteToFile(ctx.bookmarkDatabasePath(), bc.ToJson())
 }
 
+func (ctx *context) ReadJournal() (Journal, Error) {
+	contents, err := ReadFile(ctx.journalPath())
+	if err != nil {
+		if os.IsNotExist(err.Original()) {
+			// An absent journal file is equivalent to an empty one.
+			return NewEmptyJournal(), nil
+		}
+		return nil, err
+	}
+	return NewJournalFromJson(contents)
+}
+
+// manipulateJournal applies changes to the journal and saves it. The journal
+// file is locked while doing so, as multiple klog processes might write to it.
+func (ctx *context) manipulateJournal(manipulate func(Journal) Error) Error {
+	iErr := ctx.initialiseKlogFolder()
+	if iErr != nil {
+		return iErr
+	}
+	unlock, lErr := LockFile(ctx.journalPath())
+	if lErr != nil {
+		return lErr
+	}
+	defer unlock()
+	j, jErr := ctx.ReadJournal()
+	if jErr != nil {
+		return jErr
+	}
+	mErr := manipulate(j)
+	if mErr != nil {
+		return mErr
+	}
+	return WriteToFile(ctx.journalPath(), j.ToJson())
+}
+
+func (ctx *context) journalPath() File {
+	return Join(ctx.KlogConfigFolder(), JOURNAL_FILE_NAME)
+}
+
 func (ctx *context) bookmarkDatabasePath() File {
 	return Join(ctx.KlogConfigFolder(), BOOKMARKS_FILE_NAME)
 }
@@ -347,3 +602,81 @@ func (ctx *context) Debug(task func()) {
 func (ctx *context) Config() Config {
 	return ctx.config
 }
+
+func (ctx *context) ReadCalendar() (calendar.Calendar, Error) {
+	result := calendar.NewEmptyCalendar()
+	var err Error
+	ctx.config.CalendarFiles.Map(func(paths []string) {
+		homeFolder, _ := os.UserHomeDir()
+		for _, p := range paths {
+			if homeFolder != "" {
+				p = ExpandHomeFolder(p, homeFolder)
+			}
+			file := Join(ctx.KlogConfigFolder(), p)
+			if IsAbs(p) {
+				file = NewFileOrPanic(p)
+			}
+			contents, rErr := ReadFile(file)
+			if rErr != nil {
+				err = rErr
+				return
+			}
+			c, pErr := calendar.Parse(contents)
+			if pErr != nil {
+				err = NewError(
+					"Invalid calendar file",
+					"The file is not a valid iCalendar file. Location: "+file.Path(),
+					pErr,
+				)
+				return
+			}
+			result = calendar.Merge(result, c)
+		}
+	})
+	if err != nil {
+		return calendar.Calendar{}, err
+	}
+	return result, nil
+}
+
+func (ctx *context) ReadRates() (billing.Rates, Error) {
+	ratesFile := Join(ctx.KlogConfigFolder(), RATES_FILE_NAME)
+	contents, err := ReadFile(ratesFile)
+	if err != nil {
+		if os.IsNotExist(err.Original()) {
+			// An absent rates file is equivalent to an empty one.
+			return billing.NewEmptyRates(), nil
+		}
+		return billing.Rates{}, err
+	}
+	rates, pErr := billing.NewRatesFromString(contents)
+	if pErr != nil {
+		return billing.Rates{}, NewError(
+			"Invalid rates file",
+			pErr.Error()+"\nLocation: "+ratesFile.Path(),
+			pErr,
+		)
+	}
+	return rates, nil
+}
+
+func (ctx *context) ReadTagRegistry() (service.TagRegistry, Error) {
+	tagsFile := Join(ctx.KlogConfigFolder(), TAGS_FILE_NAME)
+	contents, err := ReadFile(tagsFile)
+	if err != nil {
+		if os.IsNotExist(err.Original()) {
+			// An absent tags file is equivalent to an empty one.
+			return service.NewEmptyTagRegistry(), nil
+		}
+		return service.TagRegistry{}, err
+	}
+	registry, pErr := service.NewTagRegistryFromString(contents)
+	if pErr != nil {
+		return service.TagRegistry{}, NewError(
+			"Invalid tags file",
+			pErr.Error()+"\nLocation: "+tagsFile.Path(),
+			pErr,
+		)
+	}
+	return registry, nil
+}
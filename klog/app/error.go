@@ -87,14 +87,25 @@ func (e AppError) Code() Code {
 type ParserErrors interface {
 	Error
 	All() []txt.Error
+
+	// Path returns the path of the file that contains the errors. It is empty
+	// if the file is unknown, or if the input came from stdin.
+	Path() string
 }
 
 type parserErrors struct {
 	errors []txt.Error
+	path   string
 }
 
 func NewParserErrors(errs []txt.Error) ParserErrors {
-	return parserErrors{errs}
+	return parserErrors{errs, ""}
+}
+
+// NewParserErrorsInFile is like NewParserErrors, but with the path of the
+// file that contains the errors.
+func NewParserErrorsInFile(path string, errs []txt.Error) ParserErrors {
+	return parserErrors{errs, path}
 }
 
 func (pe parserErrors) Error() string {
@@ -116,3 +127,7 @@ func (pe parserErrors) Code() Code {
 func (pe parserErrors) All() []txt.Error {
 	return pe.errors
 }
+
+func (pe parserErrors) Path() string {
+	return pe.path
+}
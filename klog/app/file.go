@@ -1,9 +1,14 @@
 package app
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	gotime "time"
 )
 
 // File is a descriptor for a file.
@@ -88,6 +93,18 @@ func Join(f File, fileOrFolderName string) File {
 	return NewFileOrPanic(filepath.Join(f.Path(), fileOrFolderName))
 }
 
+// ExpandHomeFolder replaces a leading `~` in the path with the home folder of
+// the current user. Other paths are returned as is.
+func ExpandHomeFolder(path string, homeFolder string) string {
+	if path == "~" {
+		return homeFolder
+	}
+	if strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return filepath.Join(homeFolder, path[2:])
+	}
+	return path
+}
+
 // IsAbs checks whether the given path is absolute.
 func IsAbs(path string) bool {
 	return filepath.IsAbs(path)
@@ -117,10 +134,114 @@ func ReadFile(source File) (string, Error) {
 }
 
 // WriteToFile saves contents in a file on disk.
+// The contents are written into a temporary file first, which then replaces
+// the target file. That way, the target file is never left in a half-written
+// state, e.g. if the process crashes. If the target is a symlink, the file
+// that it points to is replaced.
 // It returns an error if the file cannot be written.
 func WriteToFile(target File, contents string) Error {
-	err := os.WriteFile(target.Path(), []byte(contents), 0644)
+	err := writeAtomically(resolveSymlinks(target.Path()), []byte(contents))
+	if err != nil {
+		return NewErrorWithCode(
+			IO_ERROR,
+			"Cannot write to file",
+			"Location: "+target.Path(),
+			err,
+		)
+	}
+	return nil
+}
+
+// resolveSymlinks returns the path of the file that a symlink points to. If the
+// path is not a symlink (or doesn’t exist), it is returned as is.
+func resolveSymlinks(path string) string {
+	if resolvedPath, err := filepath.EvalSymlinks(path); err == nil {
+		return resolvedPath
+	}
+	return path
+}
+
+func writeAtomically(path string, contents []byte) error {
+	mode := os.FileMode(0644)
+	if stat, err := os.Stat(path); err == nil {
+		mode = stat.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	isDone := false
+	defer func() {
+		if !isDone {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(contents); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	isDone = true
+	return nil
+}
+
+// WriteToFileIfUnchanged is like WriteToFile, but it first verifies that the file
+// on disk still has the same contents as when it was read. Otherwise, it aborts
+// with an error, so that changes from elsewhere are not overwritten.
+func WriteToFileIfUnchanged(target FileWithContents, contents string) Error {
+	current, err := ReadFile(target)
+	if err != nil {
+		return err
+	}
+	if current != target.Contents() {
+		return NewErrorWithCode(
+			IO_ERROR,
+			"File has changed",
+			"The file was modified in the meantime, so the changes were not saved. Please try again.\n"+
+				"Location: "+target.Path(),
+			nil,
+		)
+	}
+	return WriteToFile(target, contents)
+}
+
+// CreateFile creates a new file on disk with the given contents.
+// It returns an error if the file already exists, or if it cannot be written.
+func CreateFile(target File, contents string) Error {
+	f, err := os.OpenFile(target.Path(), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return NewErrorWithCode(
+				IO_ERROR,
+				"File already exists",
+				"Location: "+target.Path(),
+				err,
+			)
+		}
+		return NewErrorWithCode(
+			IO_ERROR,
+			"Cannot create file",
+			"Location: "+target.Path(),
+			err,
+		)
+	}
+	_, err = f.Write([]byte(contents))
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
 	if err != nil {
+		_ = os.Remove(target.Path())
 		return NewErrorWithCode(
 			IO_ERROR,
 			"Cannot write to file",
@@ -131,6 +252,121 @@ func WriteToFile(target File, contents string) Error {
 	return nil
 }
 
+// CreateFiles creates multiple new files on disk. It either creates all of
+// them, or none: if one of the files cannot be created (e.g., because it
+// already exists), the ones that were created before are removed again.
+func CreateFiles(targets []FileWithContents) Error {
+	for i, t := range targets {
+		err := CreateFile(t, t.Contents())
+		if err != nil {
+			for _, created := range targets[:i] {
+				_ = os.Remove(created.Path())
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// LockFile acquires an advisory lock for a file, by creating a lock file next
+// to it. If the file is already locked, it waits for a short moment, and then
+// gives up. Lock files that are older than a certain age are considered to be
+// leftovers (e.g., from a crashed process), and are therefore disregarded.
+// It returns a function that releases the lock again.
+func LockFile(target File) (func(), Error) {
+	return lockFile(target, 3*gotime.Second, 30*gotime.Second)
+}
+
+func lockFile(target File, timeout gotime.Duration, staleAfter gotime.Duration) (func(), Error) {
+	// If the target is a symlink, the file that it points to is locked, since
+	// that is the one being written to eventually.
+	lockPath := resolveSymlinks(target.Path()) + ".lock"
+	token := newLockToken()
+	deadline := gotime.Now().Add(timeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, _ = f.WriteString(token)
+			_ = f.Close()
+			return func() {
+				// The lock might have been taken over in the meantime, in
+				// case it became stale. Then, it mustn’t be removed anymore.
+				if readLockToken(lockPath) == token {
+					_ = os.Remove(lockPath)
+				}
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, NewErrorWithCode(
+				IO_ERROR,
+				"Cannot lock file",
+				"Location: "+lockPath,
+				err,
+			)
+		}
+		if staleToken := readLockToken(lockPath); isStaleLock(lockPath, staleAfter) && takeOverStaleLock(lockPath, staleToken, staleAfter) {
+			continue
+		}
+		if gotime.Now().After(deadline) {
+			return nil, NewErrorWithCode(
+				IO_ERROR,
+				"File is locked",
+				"Another process is currently modifying the file. "+
+					"If that is not the case, please remove the lock file: "+lockPath,
+				nil,
+			)
+		}
+		gotime.Sleep(50 * gotime.Millisecond)
+	}
+}
+
+// newLockToken returns a value that identifies a lock uniquely. It consists of
+// the process id (for the user’s information) and a random part.
+func newLockToken() string {
+	nonce := make([]byte, 8)
+	_, _ = rand.Read(nonce)
+	return strconv.Itoa(os.Getpid()) + "-" + hex.EncodeToString(nonce) + "\n"
+}
+
+func readLockToken(lockPath string) string {
+	contents, err := os.ReadFile(lockPath)
+	if err != nil {
+		return ""
+	}
+	return string(contents)
+}
+
+func isStaleLock(path string, staleAfter gotime.Duration) bool {
+	stat, err := os.Stat(path)
+	return err == nil && gotime.Since(stat.ModTime()) > staleAfter
+}
+
+// takeOverStaleLock removes a stale lock file, and reports whether it did so.
+// Other processes might try to take over the same stale lock concurrently, and
+// one of them might acquire a fresh lock right away. Therefore, a takeover is
+// guarded by another lock file, and the lock is only removed if it still has
+// the token it had when it was found to be stale. That way, a fresh lock is
+// never removed or moved.
+func takeOverStaleLock(lockPath string, staleToken string, staleAfter gotime.Duration) bool {
+	guardPath := lockPath + ".takeover"
+	if isStaleLock(guardPath, staleAfter) {
+		// A leftover from a process that crashed while taking over.
+		_ = os.Remove(guardPath)
+	}
+	guard, err := os.OpenFile(guardPath, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return false
+	}
+	_ = guard.Close()
+	defer func() {
+		_ = os.Remove(guardPath)
+	}()
+	if !isStaleLock(lockPath, staleAfter) || readLockToken(lockPath) != staleToken {
+		return false
+	}
+	return os.Remove(lockPath) == nil
+}
+
 // ReadStdin reads the entire input from stdin and returns it as string.
 // It returns an error if stdin cannot be accessed, or if reading from it fails.
 func ReadStdin() (string, Error) {
@@ -0,0 +1,203 @@
+package app
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	gotime "time"
+)
+
+func TestWriteToFileReplacesContentsAndPreservesMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.klg")
+	require.Nil(t, os.WriteFile(path, []byte("old"), 0600))
+	target := NewFileOrPanic(path)
+
+	err := WriteToFile(target, "new")
+	require.Nil(t, err)
+
+	contents, _ := os.ReadFile(path)
+	assert.Equal(t, "new", string(contents))
+	stat, _ := os.Stat(path)
+	assert.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 1) // No temporary files are left behind.
+}
+
+func TestWriteToFileCreatesNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.klg")
+	err := WriteToFile(NewFileOrPanic(path), "new")
+	require.Nil(t, err)
+	contents, _ := os.ReadFile(path)
+	assert.Equal(t, "new", string(contents))
+}
+
+func TestWriteToFileIfUnchangedDetectsConflict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.klg")
+	require.Nil(t, os.WriteFile(path, []byte("original"), 0644))
+	target, _ := NewFileWithContents(path, "original")
+
+	require.Nil(t, os.WriteFile(path, []byte("modified elsewhere"), 0644))
+	err := WriteToFileIfUnchanged(target, "new")
+	require.Error(t, err)
+	assert.Equal(t, "File has changed", err.Error())
+	contents, _ := os.ReadFile(path)
+	assert.Equal(t, "modified elsewhere", string(contents))
+
+	require.Nil(t, os.WriteFile(path, []byte("original"), 0644))
+	err = WriteToFileIfUnchanged(target, "new")
+	require.Nil(t, err)
+	contents, _ = os.ReadFile(path)
+	assert.Equal(t, "new", string(contents))
+}
+
+func TestCreateFileDoesNotOverwriteExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.klg")
+	err := CreateFile(NewFileOrPanic(path), "new")
+	require.Nil(t, err)
+	contents, _ := os.ReadFile(path)
+	assert.Equal(t, "new", string(contents))
+
+	err = CreateFile(NewFileOrPanic(path), "other")
+	require.Error(t, err)
+	assert.Equal(t, "File already exists", err.Error())
+	contents, _ = os.ReadFile(path)
+	assert.Equal(t, "new", string(contents))
+}
+
+func TestCreateFilesCreatesAllOrNone(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "b.klg")
+	require.Nil(t, os.WriteFile(existing, []byte("existing"), 0644))
+	var targets []FileWithContents
+	for _, name := range []string{"a.klg", "b.klg", "c.klg"} {
+		f, _ := NewFileWithContents(filepath.Join(dir, name), "new")
+		targets = append(targets, f)
+	}
+
+	err := CreateFiles(targets)
+	require.Error(t, err)
+	assert.Equal(t, "File already exists", err.Error())
+	_, aErr := os.Stat(filepath.Join(dir, "a.klg"))
+	assert.True(t, os.IsNotExist(aErr))
+	_, cErr := os.Stat(filepath.Join(dir, "c.klg"))
+	assert.True(t, os.IsNotExist(cErr))
+	contents, _ := os.ReadFile(existing)
+	assert.Equal(t, "existing", string(contents))
+
+	require.Nil(t, os.Remove(existing))
+	require.Nil(t, CreateFiles(targets))
+	for _, f := range targets {
+		contents, _ := os.ReadFile(f.Path())
+		assert.Equal(t, "new", string(contents))
+	}
+}
+
+func TestLockFileIsExclusive(t *testing.T) {
+	target := NewFileOrPanic(filepath.Join(t.TempDir(), "test.klg"))
+
+	unlock, err := lockFile(target, 0, gotime.Minute)
+	require.Nil(t, err)
+
+	_, err = lockFile(target, 100*gotime.Millisecond, gotime.Minute)
+	require.Error(t, err)
+	assert.Equal(t, "File is locked", err.Error())
+
+	unlock()
+	unlock2, err := lockFile(target, 0, gotime.Minute)
+	require.Nil(t, err)
+	unlock2()
+	_, sErr := os.Stat(target.Path() + ".lock")
+	assert.True(t, os.IsNotExist(sErr))
+}
+
+func TestLockFileDisregardsStaleLock(t *testing.T) {
+	target := NewFileOrPanic(filepath.Join(t.TempDir(), "test.klg"))
+	lockPath := target.Path() + ".lock"
+	require.Nil(t, os.WriteFile(lockPath, []byte("123\n"), 0644))
+	past := gotime.Now().Add(-1 * gotime.Hour)
+	require.Nil(t, os.Chtimes(lockPath, past, past))
+
+	unlock, err := lockFile(target, 0, gotime.Minute)
+	require.Nil(t, err)
+	unlock()
+	entries, _ := os.ReadDir(filepath.Dir(lockPath))
+	assert.Len(t, entries, 0)
+}
+
+func TestLockFileOnlyReleasesOwnLock(t *testing.T) {
+	target := NewFileOrPanic(filepath.Join(t.TempDir(), "test.klg"))
+	lockPath := target.Path() + ".lock"
+
+	unlock, err := lockFile(target, 0, gotime.Minute)
+	require.Nil(t, err)
+	contents, _ := os.ReadFile(lockPath)
+	assert.Regexp(t, `^[0-9]+-[0-9a-f]{16}\n$`, string(contents))
+
+	// E.g., the lock became stale, and another process has taken it over.
+	require.Nil(t, os.WriteFile(lockPath, []byte("123-abc\n"), 0644))
+	unlock()
+	contents, _ = os.ReadFile(lockPath)
+	assert.Equal(t, "123-abc\n", string(contents))
+}
+
+func TestLockFileLocksTargetOfSymlink(t *testing.T) {
+	folder := t.TempDir()
+	actual := NewFileOrPanic(filepath.Join(folder, "actual.klg"))
+	link := NewFileOrPanic(filepath.Join(folder, "link.klg"))
+	require.Nil(t, os.WriteFile(actual.Path(), []byte(""), 0644))
+	require.Nil(t, os.Symlink(actual.Path(), link.Path()))
+
+	unlock, err := lockFile(link, 0, gotime.Minute)
+	require.Nil(t, err)
+	_, sErr := os.Stat(actual.Path() + ".lock")
+	assert.Nil(t, sErr)
+
+	_, err = lockFile(actual, 0, gotime.Minute)
+	require.Error(t, err)
+	unlock()
+}
+
+func TestTakeOverStaleLockLeavesFreshLockInPlace(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "test.klg.lock")
+	require.Nil(t, os.WriteFile(lockPath, []byte("123-abc\n"), 0644))
+	assertLockFileUnchanged := func() {
+		contents, err := os.ReadFile(lockPath)
+		require.Nil(t, err)
+		assert.Equal(t, "123-abc\n", string(contents))
+		entries, _ := os.ReadDir(filepath.Dir(lockPath))
+		assert.Len(t, entries, 1)
+	}
+
+	// E.g., another process has just taken over the stale lock, and acquired a new one.
+	assert.False(t, takeOverStaleLock(lockPath, "123-abc\n", gotime.Minute))
+	assertLockFileUnchanged()
+
+	past := gotime.Now().Add(-1 * gotime.Hour)
+	require.Nil(t, os.Chtimes(lockPath, past, past))
+	assert.False(t, takeOverStaleLock(lockPath, "456-def\n", gotime.Minute))
+	assertLockFileUnchanged()
+
+	// Another process is taking over at the same time.
+	require.Nil(t, os.WriteFile(lockPath+".takeover", []byte(""), 0644))
+	assert.False(t, takeOverStaleLock(lockPath, "123-abc\n", gotime.Minute))
+	require.Nil(t, os.Remove(lockPath+".takeover"))
+	assertLockFileUnchanged()
+
+	assert.True(t, takeOverStaleLock(lockPath, "123-abc\n", gotime.Minute))
+	entries, _ := os.ReadDir(filepath.Dir(lockPath))
+	assert.Len(t, entries, 0)
+
+	// There is nothing to take over anymore.
+	assert.False(t, takeOverStaleLock(lockPath, "123-abc\n", gotime.Minute))
+}
+
+func TestExpandHomeFolder(t *testing.T) {
+	assert.Equal(t, "/home/me", ExpandHomeFolder("~", "/home/me"))
+	assert.Equal(t, "/home/me/cal/holidays.ics", ExpandHomeFolder("~/cal/holidays.ics", "/home/me"))
+	assert.Equal(t, "~other/holidays.ics", ExpandHomeFolder("~other/holidays.ics", "/home/me"))
+	assert.Equal(t, "/tmp/holidays.ics", ExpandHomeFolder("/tmp/holidays.ics", "/home/me"))
+	assert.Equal(t, "holidays.ics", ExpandHomeFolder("holidays.ics", "/home/me"))
+}
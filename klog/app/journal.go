@@ -0,0 +1,275 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	gotime "time"
+	"unicode/utf8"
+)
+
+// JOURNAL_MAX_ENTRIES_PER_FILE is the number of changes that are retained
+// per file. Older changes are discarded.
+const JOURNAL_MAX_ENTRIES_PER_FILE = 20
+
+// JOURNAL_MAX_SIZE is the approximate number of bytes that the journal may
+// take up in total. Older changes (of any file) are discarded beyond that.
+const JOURNAL_MAX_SIZE = 10 * 1000 * 1000
+
+// JournalEntry is a record of a single change that klog made to a file.
+type JournalEntry struct {
+	// Path is the absolute path of the file.
+	Path string
+
+	// Time is the point in time when the change was made.
+	Time gotime.Time
+
+	// Before is the file contents prior to the change.
+	Before string
+
+	// After is the file contents after the change.
+	After string
+}
+
+// Journal keeps track of the changes that klog made to files, so that
+// these changes can be reverted later on.
+type Journal interface {
+	// Append adds a new entry to the journal. If there are too many entries
+	// for the respective file, the oldest ones are discarded.
+	Append(JournalEntry)
+
+	// Amend merges an entry into the latest entry of the respective file, so
+	// that both changes are reverted at once. That is only possible if the
+	// latest entry leads up to the new one, i.e. if the file wasn’t modified
+	// in between. Otherwise, the entry is appended as a new one.
+	Amend(JournalEntry)
+
+	// History returns all entries for a file, the latest one first.
+	History(File) []JournalEntry
+
+	// Undo reverts the latest `count` changes of a file, and removes the
+	// respective entries from the journal. It returns the file contents
+	// as they were before these changes, along with the reverted entries.
+	// It returns an error if the changes cannot be reverted, e.g. because
+	// the file was modified by something else than klog in the meantime.
+	Undo(FileWithContents, int) (string, []JournalEntry, Error)
+
+	// ToJson returns a JSON-representation of the journal.
+	ToJson() string
+}
+
+type journal struct {
+	entries []JournalEntry // In chronological order.
+}
+
+// journalEntryJson is the serialised form of an entry. In order to save space,
+// only the contents before the change are stored in full. The contents after
+// the change are stored as delta to that.
+type journalEntryJson struct {
+	Path   string           `json:"path"`
+	Time   string           `json:"time"`
+	Before string           `json:"before"`
+	After  journalDeltaJson `json:"after"`
+}
+
+// journalDeltaJson describes a text as modification of another text: the
+// text starts and ends like the other one, and has something else in between.
+type journalDeltaJson struct {
+	KeepStart int    `json:"keep_start"`
+	KeepEnd   int    `json:"keep_end"`
+	Insert    string `json:"insert"`
+}
+
+func newJournalDelta(from string, to string) journalDeltaJson {
+	start := 0
+	for start < len(from) && start < len(to) && from[start] == to[start] {
+		start++
+	}
+	// Only split at character boundaries, so that the inserted text is valid UTF-8.
+	for start > 0 && start < len(to) && !utf8.RuneStart(to[start]) {
+		start--
+	}
+	end := 0
+	for end < len(from)-start && end < len(to)-start && from[len(from)-1-end] == to[len(to)-1-end] {
+		end++
+	}
+	for end > 0 && !utf8.RuneStart(to[len(to)-end]) {
+		end--
+	}
+	return journalDeltaJson{start, end, to[start : len(to)-end]}
+}
+
+func (d journalDeltaJson) applyTo(from string) (string, bool) {
+	if d.KeepStart < 0 || d.KeepEnd < 0 || d.KeepStart+d.KeepEnd > len(from) {
+		return "", false
+	}
+	return from[:d.KeepStart] + d.Insert + from[len(from)-d.KeepEnd:], true
+}
+
+func NewEmptyJournal() Journal {
+	return &journal{}
+}
+
+// NewJournalFromJson deserialises JSON data. It returns an error
+// if the syntax is malformed.
+func NewJournalFromJson(jsonText string) (Journal, Error) {
+	newMalformedJsonError := func(err error) Error {
+		return NewErrorWithCode(
+			CONFIG_ERROR,
+			"Invalid JSON",
+			"The JSON in your journal file is malformed",
+			err,
+		)
+	}
+	j := &journal{}
+	if jsonText == "" {
+		return j, nil
+	}
+	var rawEntries []journalEntryJson
+	err := json.Unmarshal([]byte(jsonText), &rawEntries)
+	if err != nil {
+		return nil, newMalformedJsonError(err)
+	}
+	for _, e := range rawEntries {
+		t, tErr := gotime.Parse(gotime.RFC3339, e.Time)
+		if tErr != nil || !IsAbs(e.Path) {
+			return nil, newMalformedJsonError(tErr)
+		}
+		after, isValid := e.After.applyTo(e.Before)
+		if !isValid {
+			return nil, newMalformedJsonError(errors.New("Invalid delta in entry for " + e.Path))
+		}
+		j.entries = append(j.entries, JournalEntry{e.Path, t, e.Before, after})
+	}
+	return j, nil
+}
+
+func (j *journal) Append(e JournalEntry) {
+	j.entries = append(j.entries, e)
+	count := 0
+	var retained []JournalEntry
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		if j.entries[i].Path == e.Path {
+			count++
+			if count > JOURNAL_MAX_ENTRIES_PER_FILE {
+				continue
+			}
+		}
+		retained = append([]JournalEntry{j.entries[i]}, retained...)
+	}
+	j.entries = retained
+	j.discardBeyondMaxSize()
+}
+
+// discardBeyondMaxSize removes the oldest entries until the journal fits
+// into JOURNAL_MAX_SIZE. The latest entry is always retained, though.
+func (j *journal) discardBeyondMaxSize() {
+	size := 0
+	for _, e := range j.entries {
+		size += e.size()
+	}
+	for size > JOURNAL_MAX_SIZE && len(j.entries) > 1 {
+		size -= j.entries[0].size()
+		j.entries = j.entries[1:]
+	}
+}
+
+// size approximates how many bytes the entry takes up when serialised.
+func (e JournalEntry) size() int {
+	return len(e.Path) + len(e.Before) + len(newJournalDelta(e.Before, e.After).Insert)
+}
+
+func (j *journal) Amend(e JournalEntry) {
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		if j.entries[i].Path != e.Path {
+			continue
+		}
+		if j.entries[i].After == e.Before {
+			j.entries[i].After = e.After
+			j.entries[i].Time = e.Time
+			j.discardBeyondMaxSize()
+			return
+		}
+		break
+	}
+	j.Append(e)
+}
+
+func (j *journal) History(f File) []JournalEntry {
+	var history []JournalEntry
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		if j.entries[i].Path == f.Path() {
+			history = append(history, j.entries[i])
+		}
+	}
+	return history
+}
+
+func (j *journal) Undo(f FileWithContents, count int) (string, []JournalEntry, Error) {
+	history := j.History(f)
+	if len(history) == 0 {
+		return "", nil, NewErrorWithCode(
+			LOGICAL_ERROR,
+			"Nothing to undo",
+			"There are no recorded changes for this file",
+			nil,
+		)
+	}
+	if count < 1 || count > len(history) {
+		return "", nil, NewErrorWithCode(
+			LOGICAL_ERROR,
+			"Cannot undo",
+			fmt.Sprintf("There are only %d recorded change(s) for this file", len(history)),
+			nil,
+		)
+	}
+	if history[0].After != f.Contents() {
+		return "", nil, NewErrorWithCode(
+			LOGICAL_ERROR,
+			"Cannot undo",
+			"The file was modified after the latest recorded change",
+			nil,
+		)
+	}
+	for i := 0; i < count-1; i++ {
+		if history[i].Before != history[i+1].After {
+			return "", nil, NewErrorWithCode(
+				LOGICAL_ERROR,
+				"Cannot undo",
+				fmt.Sprintf("The file was modified in between, so only up to %d change(s) can be undone", i+1),
+				nil,
+			)
+		}
+	}
+	reverted := history[:count]
+	var retained []JournalEntry
+	skipped := 0
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		if j.entries[i].Path == f.Path() && skipped < count {
+			skipped++
+			continue
+		}
+		retained = append([]JournalEntry{j.entries[i]}, retained...)
+	}
+	j.entries = retained
+	return reverted[count-1].Before, reverted, nil
+}
+
+func (j *journal) ToJson() string {
+	entriesAsJson := make([]journalEntryJson, 0, len(j.entries))
+	for _, e := range j.entries {
+		entriesAsJson = append(entriesAsJson, journalEntryJson{
+			e.Path, e.Time.Format(gotime.RFC3339), e.Before, newJournalDelta(e.Before, e.After),
+		})
+	}
+	buffer := new(bytes.Buffer)
+	enc := json.NewEncoder(buffer)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(&entriesAsJson)
+	if err != nil {
+		panic(err)
+	}
+	return buffer.String()
+}
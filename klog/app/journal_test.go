@@ -0,0 +1,188 @@
+package app
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	gotime "time"
+)
+
+var journalTime = gotime.Date(2020, 1, 1, 9, 30, 0, 0, gotime.UTC)
+
+func TestJournalHistoryIsPerFileAndLatestFirst(t *testing.T) {
+	j := NewEmptyJournal()
+	j.Append(JournalEntry{"/a.klg", journalTime, "", "1"})
+	j.Append(JournalEntry{"/b.klg", journalTime, "", "x"})
+	j.Append(JournalEntry{"/a.klg", journalTime, "1", "2"})
+
+	history := j.History(NewFileOrPanic("/a.klg"))
+	require.Len(t, history, 2)
+	assert.Equal(t, "2", history[0].After)
+	assert.Equal(t, "1", history[1].After)
+	assert.Len(t, j.History(NewFileOrPanic("/b.klg")), 1)
+	assert.Len(t, j.History(NewFileOrPanic("/c.klg")), 0)
+}
+
+func TestJournalDiscardsOldestEntries(t *testing.T) {
+	j := NewEmptyJournal()
+	j.Append(JournalEntry{"/b.klg", journalTime, "", "x"})
+	for i := 0; i < JOURNAL_MAX_ENTRIES_PER_FILE+5; i++ {
+		j.Append(JournalEntry{"/a.klg", journalTime, "", string(rune('A' + i))})
+	}
+	history := j.History(NewFileOrPanic("/a.klg"))
+	require.Len(t, history, JOURNAL_MAX_ENTRIES_PER_FILE)
+	assert.Equal(t, string(rune('A'+JOURNAL_MAX_ENTRIES_PER_FILE+4)), history[0].After)
+	assert.Equal(t, "F", history[JOURNAL_MAX_ENTRIES_PER_FILE-1].After)
+	assert.Len(t, j.History(NewFileOrPanic("/b.klg")), 1)
+}
+
+func TestJournalSerialisesAndDeserialises(t *testing.T) {
+	j := NewEmptyJournal()
+	j.Append(JournalEntry{"/a.klg", journalTime, "2020-01-01\n", "2020-01-01\n\t1h <a>\n"})
+	j2, err := NewJournalFromJson(j.ToJson())
+	require.Nil(t, err)
+	history := j2.History(NewFileOrPanic("/a.klg"))
+	require.Len(t, history, 1)
+	assert.Equal(t, JournalEntry{"/a.klg", journalTime, "2020-01-01\n", "2020-01-01\n\t1h <a>\n"}, history[0])
+}
+
+func TestJournalSerialisesContentsAfterChangeAsDelta(t *testing.T) {
+	for _, x := range []struct {
+		before string
+		after  string
+	}{
+		{"", ""},
+		{"", "2020-01-01\n"},
+		{"2020-01-01\n", ""},
+		{"2020-01-01\n", "2020-01-01\n"},
+		{"2020-01-01\n\t1h\n", "2020-01-01\n\t1h\n\t2h\n"},
+		{"2020-01-01\n\t1h\n\n2020-01-02\n", "2020-01-01\n\t1h\n\t-30m\n\n2020-01-02\n"},
+		{"aaa", "aa"},
+		{"2020-01-01\n\t1h Kaffee ☕\n", "2020-01-01\n\t1h Kaffee 🍵\n"},
+		{"2020-01-01 Ä\n", "2020-01-01 Ö\n"},
+	} {
+		j := NewEmptyJournal()
+		j.Append(JournalEntry{"/a.klg", journalTime, x.before, x.after})
+		serialised := j.ToJson()
+		j2, err := NewJournalFromJson(serialised)
+		require.Nil(t, err)
+		history := j2.History(NewFileOrPanic("/a.klg"))
+		require.Len(t, history, 1)
+		assert.Equal(t, x.before, history[0].Before)
+		assert.Equal(t, x.after, history[0].After)
+	}
+
+	j := NewEmptyJournal()
+	j.Append(JournalEntry{"/a.klg", journalTime, "2020-01-01\n\t1h Kaffee\n", "2020-01-01\n\t1h Kaffee\n\t2h Tee\n"})
+	assert.Equal(t, 1, strings.Count(j.ToJson(), "Kaffee"))
+}
+
+func TestJournalDiscardsOldestEntriesBeyondMaxSize(t *testing.T) {
+	j := NewEmptyJournal()
+	contents := strings.Repeat("x", JOURNAL_MAX_SIZE/3-100)
+	j.Append(JournalEntry{"/a.klg", journalTime, "", "1"})
+	j.Append(JournalEntry{"/b.klg", journalTime, contents, contents + "1"})
+	j.Append(JournalEntry{"/c.klg", journalTime, contents, contents + "1"})
+	j.Append(JournalEntry{"/a.klg", journalTime, contents, contents + "1"})
+	assert.Len(t, j.History(NewFileOrPanic("/a.klg")), 2)
+	assert.Len(t, j.History(NewFileOrPanic("/b.klg")), 1)
+	assert.Len(t, j.History(NewFileOrPanic("/c.klg")), 1)
+
+	j.Append(JournalEntry{"/c.klg", journalTime, contents, contents + "2"})
+	assert.Len(t, j.History(NewFileOrPanic("/a.klg")), 1)
+	assert.Len(t, j.History(NewFileOrPanic("/b.klg")), 0)
+	assert.Len(t, j.History(NewFileOrPanic("/c.klg")), 2)
+
+	// The latest entry is retained even if it exceeds the limit on its own.
+	huge := strings.Repeat("x", JOURNAL_MAX_SIZE+1)
+	j.Append(JournalEntry{"/a.klg", journalTime, huge, ""})
+	require.Len(t, j.History(NewFileOrPanic("/a.klg")), 1)
+	assert.Len(t, j.History(NewFileOrPanic("/c.klg")), 0)
+}
+
+func TestJournalRejectsMalformedJson(t *testing.T) {
+	for _, text := range []string{
+		`{`,
+		`[{"path": "/a.klg", "time": "asdf", "before": "", "after": {}}]`,
+		`[{"path": "a.klg", "time": "2020-01-01T09:30:00Z", "before": "", "after": {}}]`,
+		`[{"path": "/a.klg", "time": "2020-01-01T09:30:00Z", "before": "", "after": ""}]`,
+		`[{"path": "/a.klg", "time": "2020-01-01T09:30:00Z", "before": "abc", "after": {"keep_start": 2, "keep_end": 2, "insert": ""}}]`,
+		`[{"path": "/a.klg", "time": "2020-01-01T09:30:00Z", "before": "abc", "after": {"keep_start": -1, "keep_end": 0, "insert": ""}}]`,
+	} {
+		_, err := NewJournalFromJson(text)
+		require.Error(t, err, text)
+	}
+}
+
+func TestJournalUndoesLatestChanges(t *testing.T) {
+	j := NewEmptyJournal()
+	j.Append(JournalEntry{"/a.klg", journalTime, "1", "2"})
+	j.Append(JournalEntry{"/b.klg", journalTime, "", "x"})
+	j.Append(JournalEntry{"/a.klg", journalTime, "2", "3"})
+	j.Append(JournalEntry{"/a.klg", journalTime, "3", "4"})
+	f, _ := NewFileWithContents("/a.klg", "4")
+
+	restored, reverted, err := j.Undo(f, 2)
+	require.Nil(t, err)
+	assert.Equal(t, "2", restored)
+	require.Len(t, reverted, 2)
+	assert.Equal(t, "4", reverted[0].After)
+	assert.Equal(t, "3", reverted[1].After)
+
+	history := j.History(f)
+	require.Len(t, history, 1)
+	assert.Equal(t, "2", history[0].After)
+	assert.Len(t, j.History(NewFileOrPanic("/b.klg")), 1)
+}
+
+func TestJournalRefusesToUndo(t *testing.T) {
+	j := NewEmptyJournal()
+	j.Append(JournalEntry{"/a.klg", journalTime, "1", "2"})
+	j.Append(JournalEntry{"/a.klg", journalTime, "2 (edited manually)", "3"})
+
+	for _, x := range []struct {
+		contents string
+		count    int
+	}{
+		{"3", 3},                   // Not enough entries
+		{"3", 0},                   // Invalid count
+		{"3 (edited manually)", 1}, // File was modified afterwards
+		{"3", 2},                   // File was modified in between
+	} {
+		f, _ := NewFileWithContents("/a.klg", x.contents)
+		_, _, err := j.Undo(f, x.count)
+		require.Error(t, err)
+	}
+	assert.Len(t, j.History(NewFileOrPanic("/a.klg")), 2)
+
+	_, _, err := NewEmptyJournal().Undo(&fileWithContents{NewFileOrPanic("/a.klg"), ""}, 1)
+	require.Error(t, err)
+	assert.Equal(t, "Nothing to undo", err.Error())
+}
+
+func TestJournalAmendsLatestEntry(t *testing.T) {
+	j := NewEmptyJournal()
+	j.Append(JournalEntry{"/a.klg", journalTime, "", "1"})
+	j.Append(JournalEntry{"/a.klg", journalTime, "1", "2"})
+	j.Append(JournalEntry{"/b.klg", journalTime, "", "x"})
+	j.Amend(JournalEntry{"/a.klg", journalTime.Add(gotime.Minute), "2", "3"})
+	j.Amend(JournalEntry{"/a.klg", journalTime.Add(2 * gotime.Minute), "3", "4"})
+
+	history := j.History(NewFileOrPanic("/a.klg"))
+	require.Len(t, history, 2)
+	assert.Equal(t, JournalEntry{"/a.klg", journalTime.Add(2 * gotime.Minute), "1", "4"}, history[0])
+	assert.Len(t, j.History(NewFileOrPanic("/b.klg")), 1)
+}
+
+func TestJournalAppendsWhenAmendingAfterExternalChange(t *testing.T) {
+	j := NewEmptyJournal()
+	j.Append(JournalEntry{"/a.klg", journalTime, "", "1"})
+	j.Amend(JournalEntry{"/a.klg", journalTime, "1 (edited)", "2"})
+	j.Amend(JournalEntry{"/b.klg", journalTime, "", "x"})
+
+	history := j.History(NewFileOrPanic("/a.klg"))
+	require.Len(t, history, 2)
+	assert.Equal(t, "2", history[0].After)
+	assert.Len(t, j.History(NewFileOrPanic("/b.klg")), 1)
+}
@@ -16,6 +16,11 @@ type Parser interface {
 	// so the length of the error array doesn’t say anything about the number
 	// of records.
 	Parse(string) ([]klog.Record, []txt.Block, []txt.Error)
+
+	// ParseLeniently is like Parse, except that it doesn’t discard everything
+	// in case of errors. Instead, it returns the records and blocks that are
+	// valid, along with the errors of the invalid ones.
+	ParseLeniently(string) ([]klog.Record, []txt.Block, []txt.Error)
 }
 
 // NewSerialParser returns a new parser, which processes the input text
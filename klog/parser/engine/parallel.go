@@ -22,6 +22,19 @@ type batchResult[T any] struct {
 }
 
 func (p ParallelBatchParser[T]) Parse(text string) ([]T, []txt.Block, []txt.Error) {
+	values, blocks, errs := p.parseAll(text)
+	if hasAnyErrors(errs) {
+		return nil, nil, flatten(errs)
+	}
+	return values, blocks, nil
+}
+
+func (p ParallelBatchParser[T]) ParseLeniently(text string) ([]T, []txt.Block, []txt.Error) {
+	return withoutInvalid(p.parseAll(text))
+}
+
+// parseAll parses the text. All 3 return arrays have the same arity.
+func (p ParallelBatchParser[T]) parseAll(text string) ([]T, []txt.Block, [][]txt.Error) {
 	if p.NumberOfWorkers <= 0 {
 		panic("ILLEGAL_WORKER_SIZE")
 	}
@@ -56,39 +69,32 @@ func (p ParallelBatchParser[T]) Parse(text string) ([]T, []txt.Block, []txt.Erro
 	// Process remainders and flatten results.
 	var allValues []T
 	var allBlocks []txt.Block
-	var allErrs []txt.Error
+	var allErrs [][]txt.Error
 	carryText := ""
 	for _, result := range allResults {
 		carryText += result.headText
 		if len(result.blocks) > 0 {
-			carryValues, carryBlocks, _, carryErrs, hasErrors := p.SerialParser.mapParse(carryText)
+			carryValues, carryBlocks, _, carryErrs, _ := p.SerialParser.mapParse(carryText)
 			allValues = append(allValues, carryValues...)
 			allBlocks = append(allBlocks, carryBlocks...)
-			if hasErrors {
-				allErrs = append(allErrs, flatten(carryErrs)...)
-			}
+			allErrs = append(allErrs, carryErrs...)
 			carryText = ""
 			allValues = append(allValues, result.values...)
 			allBlocks = append(allBlocks, result.blocks...)
-			allErrs = append(allErrs, flatten(result.errs)...)
+			allErrs = append(allErrs, result.errs...)
 		}
 		carryText += result.tailText
 	}
-	carryValues, carryBlocks, _, carryErrs, hasErrors := p.SerialParser.mapParse(carryText)
+	carryValues, carryBlocks, _, carryErrs, _ := p.SerialParser.mapParse(carryText)
 	allValues = append(allValues, carryValues...)
 	allBlocks = append(allBlocks, carryBlocks...)
+	allErrs = append(allErrs, carryErrs...)
 	lineCount := 0
 	for _, b := range allBlocks {
 		b.SetPrecedingLineCount(lineCount)
 		lineCount += len(b.Lines())
 	}
-	if hasErrors {
-		allErrs = append(allErrs, flatten(carryErrs)...)
-	}
-	if len(allErrs) > 0 {
-		return nil, nil, allErrs
-	}
-	return allValues, allBlocks, nil
+	return allValues, allBlocks, allErrs
 }
 
 func (p ParallelBatchParser[T]) processAsync(batches []string, work func(int, string) batchResult[T]) []batchResult[T] {
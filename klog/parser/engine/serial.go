@@ -14,6 +14,11 @@ func (p SerialParser[T]) Parse(text string) ([]T, []txt.Block, []txt.Error) {
 	return ts, blocks, nil
 }
 
+func (p SerialParser[T]) ParseLeniently(text string) ([]T, []txt.Block, []txt.Error) {
+	ts, blocks, _, errs, _ := p.mapParse(text)
+	return withoutInvalid(ts, blocks, errs)
+}
+
 // mapParse parses the text. All 3 return arrays have the same arity, and the last
 // bool indicates whether any errors occurred.
 func (p SerialParser[T]) mapParse(text string) ([]T, []txt.Block, int, [][]txt.Error, bool) {
@@ -41,6 +46,30 @@ func (p SerialParser[T]) mapParse(text string) ([]T, []txt.Block, int, [][]txt.E
 	return ts, blocks, totalBytesConsumed, errs, hasErrors
 }
 
+// withoutInvalid removes all values and blocks that have errors. It returns
+// the remaining values and blocks, along with all errors.
+func withoutInvalid[T any](ts []T, blocks []txt.Block, errs [][]txt.Error) ([]T, []txt.Block, []txt.Error) {
+	var validTs []T
+	var validBlocks []txt.Block
+	for i := range ts {
+		if len(errs[i]) > 0 {
+			continue
+		}
+		validTs = append(validTs, ts[i])
+		validBlocks = append(validBlocks, blocks[i])
+	}
+	return validTs, validBlocks, flatten(errs)
+}
+
+func hasAnyErrors(errs [][]txt.Error) bool {
+	for _, e := range errs {
+		if len(e) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func flatten[T any](xss [][]T) []T {
 	var result []T
 	for _, xs := range xss {
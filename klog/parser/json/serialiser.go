@@ -1,5 +1,6 @@
 /*
-Package json contains the logic of serialising Record’s as JSON.
+Package json contains the logic of serialising Record’s (and evaluations
+thereof) as JSON.
 */
 package json
 
@@ -10,32 +11,144 @@ import (
 	"github.com/jotaen/klog/klog/parser"
 	"github.com/jotaen/klog/klog/parser/txt"
 	"github.com/jotaen/klog/klog/service"
+	"github.com/jotaen/klog/klog/service/billing"
 	"strings"
 )
 
+// FileErrors are the parser errors in one file.
+type FileErrors struct {
+	// Path is the path of the file. It is empty if the input came from stdin.
+	Path   string
+	Errors []txt.Error
+}
+
 // ToJson serialises records into their JSON representation. The output
 // structure is RecordView at the top level.
-func ToJson(rs []klog.Record, errs []txt.Error, prettyPrint bool) string {
-	envelop := func() Envelop {
-		if errs == nil {
-			return Envelop{
-				Records: toRecordViews(rs),
-				Errors:  nil,
-			}
-		} else {
-			return Envelop{
-				Records: nil,
-				Errors:  toErrorViews(errs),
+// If there are errors, the records are omitted, unless `rs` is non-nil,
+// which is the case when the input was parsed leniently.
+func ToJson(rs []klog.Record, errs []FileErrors, prettyPrint bool) string {
+	envelop := Envelop{}
+	if rs != nil || errs == nil {
+		envelop.Records = toRecordViews(rs)
+	}
+	if errs != nil {
+		envelop.Errors = toErrorViews(errs)
+	}
+	return encode(&envelop, prettyPrint)
+}
+
+// TagStatsToJson serialises aggregated tag stats into their JSON representation.
+// The output structure is TagsEnvelop at the top level.
+func TagStatsToJson(stats []*service.TagStats, prettyPrint bool) string {
+	envelop := TagsEnvelop{Tags: []TagStatsView{}}
+	for _, s := range stats {
+		v := TagStatsView{
+			Tag:       s.Tag.ToString(),
+			Name:      s.Tag.Name(),
+			Value:     s.Tag.Value(),
+			Total:     s.Total.ToString(),
+			TotalMins: s.Total.InMinutes(),
+			Count:     s.Count,
+		}
+		if s.Numeric != nil {
+			v.Numeric = &NumericView{
+				Sum:   s.Numeric.Sum,
+				Min:   s.Numeric.Min,
+				Max:   s.Numeric.Max,
+				Avg:   s.Numeric.Avg(),
+				Count: s.Numeric.Count,
 			}
 		}
-	}()
+		envelop.Tags = append(envelop.Tags, v)
+	}
+	return encode(&envelop, prettyPrint)
+}
+
+// ReportToJson serialises the aggregated report. The output structure is
+// ReportEnvelop at the top level.
+func ReportToJson(envelop ReportEnvelop, prettyPrint bool) string {
+	if envelop.Periods == nil {
+		envelop.Periods = []ReportPeriodView{}
+	}
+	for i := range envelop.Periods {
+		if envelop.Periods[i].Labels == nil {
+			envelop.Periods[i].Labels = []string{}
+		}
+	}
+	return encode(&envelop, prettyPrint)
+}
+
+// TotalToJson serialises the overall total time. The output structure is
+// TotalEnvelop at the top level.
+func TotalToJson(envelop TotalEnvelop, prettyPrint bool) string {
+	return encode(&envelop, prettyPrint)
+}
+
+// TodayToJson serialises the evaluation of the current day. The output
+// structure is TodayEnvelop at the top level.
+func TodayToJson(envelop TodayEnvelop, prettyPrint bool) string {
+	return encode(&envelop, prettyPrint)
+}
+
+// InvoiceToJson serialises the invoice. The output structure is
+// InvoiceEnvelop at the top level.
+func InvoiceToJson(invoice billing.Invoice, prettyPrint bool) string {
+	envelop := InvoiceEnvelop{LineItems: []LineItemView{}, Sums: []SumView{}}
+	for _, item := range invoice.LineItems {
+		envelop.LineItems = append(envelop.LineItems, LineItemView{
+			Tag:       item.Tag.ToString(),
+			Total:     item.Total.ToString(),
+			TotalMins: item.Total.InMinutes(),
+			Hours:     item.Hours(),
+			Rate:      float64(item.Rate.Amount) / 100,
+			Currency:  item.Rate.Currency,
+			Amount:    float64(item.Amount) / 100,
+		})
+	}
+	for _, sum := range invoice.Sums {
+		envelop.Sums = append(envelop.Sums, SumView{Currency: sum.Currency, Amount: float64(sum.Amount) / 100})
+	}
+	return encode(&envelop, prettyPrint)
+}
+
+// CheckToJson serialises the findings of the checks. The output structure is
+// CheckEnvelop at the top level.
+func CheckToJson(envelop CheckEnvelop, prettyPrint bool) string {
+	if envelop.Findings == nil {
+		envelop.Findings = []FindingView{}
+	}
+	return encode(&envelop, prettyPrint)
+}
+
+// NewEvaluationView creates an EvaluationView from the respective values.
+func NewEvaluationView(total klog.Duration, should klog.ShouldTotal, diff klog.Duration) EvaluationView {
+	return EvaluationView{
+		Total:           total.ToString(),
+		TotalMins:       total.InMinutes(),
+		ShouldTotal:     should.ToString(),
+		ShouldTotalMins: should.InMinutes(),
+		Diff:            diff.ToStringWithSign(),
+		DiffMins:        diff.InMinutes(),
+	}
+}
+
+// NewBalanceView creates a BalanceView from the respective values.
+func NewBalanceView(total klog.Duration, should klog.ShouldTotal, diff klog.Duration, balance klog.Duration) BalanceView {
+	return BalanceView{
+		EvaluationView: NewEvaluationView(total, should, diff),
+		Balance:        balance.ToStringWithSign(),
+		BalanceMins:    balance.InMinutes(),
+	}
+}
+
+func encode(v any, prettyPrint bool) string {
 	buffer := new(bytes.Buffer)
 	enc := json.NewEncoder(buffer)
 	if prettyPrint {
 		enc.SetIndent("", "  ")
 	}
 	enc.SetEscapeHTML(false)
-	err := enc.Encode(&envelop)
+	err := enc.Encode(v)
 	if err != nil {
 		panic(err) // This should never happen
 	}
@@ -49,22 +162,29 @@ func toRecordViews(rs []klog.Record) []RecordView {
 		should := r.ShouldTotal()
 		diff := service.Diff(should, total)
 		v := RecordView{
-			Date:            r.Date().ToString(),
-			Summary:         parser.SummaryText(r.Summary()).ToString(),
-			Total:           total.ToString(),
-			TotalMins:       total.InMinutes(),
-			ShouldTotal:     should.ToString(),
-			ShouldTotalMins: should.InMinutes(),
-			Diff:            diff.ToStringWithSign(),
-			DiffMins:        diff.InMinutes(),
-			Tags:            toTagViews(r.Summary().Tags()),
-			Entries:         toEntryViews(r.Entries()),
+			Date:           r.Date().ToString(),
+			Summary:        parser.SummaryText(r.Summary()).ToString(),
+			EvaluationView: NewEvaluationView(total, should, diff),
+			Tags:           toTagViews(r.Summary().Tags()),
+			Entries:        toEntryViews(r.Entries()),
+			Source:         toSourceView(r.Source()),
 		}
 		result = append(result, v)
 	}
 	return result
 }
 
+func toSourceView(s *klog.Source) *SourceView {
+	if s == nil {
+		return nil
+	}
+	return &SourceView{
+		File:      s.Path,
+		FirstLine: s.FirstLine,
+		LastLine:  s.LastLine,
+	}
+}
+
 func toTagViews(ts klog.TagSet) []string {
 	result := ts.ToStrings()
 	if result == nil {
@@ -109,16 +229,19 @@ func toEntryViews(es []klog.Entry) []any {
 	return views
 }
 
-func toErrorViews(errs []txt.Error) []ErrorView {
+func toErrorViews(errs []FileErrors) []ErrorView {
 	var result []ErrorView
-	for _, e := range errs {
-		result = append(result, ErrorView{
-			Line:    e.LineNumber(),
-			Column:  e.Column(),
-			Length:  e.Length(),
-			Title:   e.Title(),
-			Details: e.Details(),
-		})
+	for _, f := range errs {
+		for _, e := range f.Errors {
+			result = append(result, ErrorView{
+				File:    f.Path,
+				Line:    e.LineNumber(),
+				Column:  e.Column(),
+				Length:  e.Length(),
+				Title:   e.Title(),
+				Details: e.Details(),
+			})
+		}
 	}
 	return result
 }
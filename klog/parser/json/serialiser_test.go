@@ -5,6 +5,7 @@ import (
 	"github.com/jotaen/klog/klog/parser"
 	"github.com/jotaen/klog/klog/parser/txt"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 )
 
@@ -41,7 +42,8 @@ func TestSerialiseMinimalRecord(t *testing.T) {
 		`"diff":"0m",`+
 		`"diff_mins":0,`+
 		`"tags":[],`+
-		`"entries":[]`+
+		`"entries":[],`+
+		`"source":null`+
 		`}],"errors":null}`, json)
 }
 
@@ -53,6 +55,7 @@ func TestSerialiseFullBlownRecord(t *testing.T) {
 		r.AddDuration(klog.NewDuration(2, 3), klog.Ɀ_EntrySummary_("#some #thing"))
 		r.AddRange(klog.Ɀ_Range_(klog.Ɀ_TimeYesterday_(23, 44), klog.Ɀ_Time_(5, 23)), nil)
 		r.Start(klog.NewOpenRange(klog.Ɀ_TimeTomorrow_(0, 28)), klog.Ɀ_EntrySummary_("Started #todo", "still on it"))
+		r.SetSource(klog.Source{Path: "/tmp/times.klg", FirstLine: 3, LastLine: 10})
 		return []klog.Record{r}
 	}(), nil, false)
 	assert.Equal(t, `{"records":[{`+
@@ -89,16 +92,18 @@ func TestSerialiseFullBlownRecord(t *testing.T) {
 		`"total_mins":0,`+
 		`"start":"0:28>",`+
 		`"start_mins":1468`+
-		`}]`+
+		`}],`+
+		`"source":{"file":"/tmp/times.klg","first_line":3,"last_line":10}`+
 		`}],"errors":null}`, json)
 }
 
 func TestSerialiseParserErrors(t *testing.T) {
 	block, _ := txt.ParseBlock("2018-99-99", 6)
-	json := ToJson(nil, []txt.Error{
+	json := ToJson(nil, []FileErrors{{Path: "/tmp/times.klg", Errors: []txt.Error{
 		parser.ErrorInvalidDate().New(block, 0, 0, 10),
-	}, false)
+	}}}, false)
 	assert.Equal(t, `{"records":null,"errors":[{`+
+		`"file":"/tmp/times.klg",`+
 		`"line":7,`+
 		`"column":1,`+
 		`"length":10,`+
@@ -106,3 +111,42 @@ func TestSerialiseParserErrors(t *testing.T) {
 		`"details":"Please make sure that the date format is either YYYY-MM-DD or YYYY/MM/DD, and that its value represents a valid day in the calendar."`+
 		`}]}`, json)
 }
+
+func TestSerialiseRecordsAlongWithParserErrors(t *testing.T) {
+	block, _ := txt.ParseBlock("2018-99-99", 6)
+	json := ToJson([]klog.Record{klog.NewRecord(klog.Ɀ_Date_(2000, 12, 31))}, []FileErrors{{Path: "/tmp/times.klg", Errors: []txt.Error{
+		parser.ErrorInvalidDate().New(block, 0, 0, 10),
+	}}}, false)
+	assert.True(t, strings.HasPrefix(json, `{"records":[{"date":"2000-12-31",`))
+	assert.True(t, strings.Contains(json, `"errors":[{"file":"/tmp/times.klg","line":7,`))
+}
+
+func TestSerialiseEmptyTagStats(t *testing.T) {
+	json := TagStatsToJson(nil, false)
+	assert.Equal(t, `{"tags":[]}`, json)
+}
+
+func TestSerialiseReportWithEmptyListsInsteadOfNull(t *testing.T) {
+	json := ReportToJson(ReportEnvelop{
+		AggregateBy: "year",
+		Periods: []ReportPeriodView{{
+			Period:      "2000",
+			Since:       "2000-01-01",
+			Until:       "2000-12-31",
+			BalanceView: NewBalanceView(klog.NewDuration(1, 0), klog.NewShouldTotal(2, 0), klog.NewDuration(-1, 0), klog.NewDuration(-1, 0)),
+		}},
+		Total: NewBalanceView(klog.NewDuration(1, 0), klog.NewShouldTotal(2, 0), klog.NewDuration(-1, 0), klog.NewDuration(-1, 0)),
+	}, false)
+	assert.Equal(t, `{"aggregate_by":"year","periods":[{`+
+		`"period":"2000","since":"2000-01-01","until":"2000-12-31",`+
+		`"total":"1h","total_mins":60,"should_total":"2h!","should_total_mins":120,"diff":"-1h","diff_mins":-60,"balance":"-1h","balance_mins":-60,`+
+		`"labels":[]`+
+		`}],"total":{`+
+		`"total":"1h","total_mins":60,"should_total":"2h!","should_total_mins":120,"diff":"-1h","diff_mins":-60,"balance":"-1h","balance_mins":-60`+
+		`}}`, json)
+}
+
+func TestSerialiseCheckWithEmptyListInsteadOfNull(t *testing.T) {
+	json := CheckToJson(CheckEnvelop{}, false)
+	assert.Equal(t, `{"findings":[],"errors":0,"warnings":0}`, json)
+}
@@ -1,7 +1,9 @@
 package json
 
 // Envelop is the top level data structure of the JSON output.
-// It contains two nodes, `records` and `errors`, one of which is always `null`.
+// It contains two nodes, `records` and `errors`. Usually, one of them is `null`.
+// Only when parsing leniently, both of them can be populated: `records` then
+// contains the valid records, and `errors` the errors of the invalid ones.
 type Envelop struct {
 	Records []RecordView `json:"records"`
 	Errors  []ErrorView  `json:"errors"`
@@ -10,16 +12,34 @@ type Envelop struct {
 // RecordView is the JSON representation of a record.
 // It also contains some evaluation data, such as the total time.
 type RecordView struct {
-	Date            string   `json:"date"`
-	Summary         string   `json:"summary"`
-	Total           string   `json:"total"`
-	TotalMins       int      `json:"total_mins"`
-	ShouldTotal     string   `json:"should_total"`
-	ShouldTotalMins int      `json:"should_total_mins"`
-	Diff            string   `json:"diff"`
-	DiffMins        int      `json:"diff_mins"`
-	Tags            []string `json:"tags"`
-	Entries         []any    `json:"entries"`
+	Date    string `json:"date"`
+	Summary string `json:"summary"`
+	EvaluationView
+	Tags    []string `json:"tags"`
+	Entries []any    `json:"entries"`
+
+	// Source is `null` if the location of the record is unknown.
+	Source *SourceView `json:"source"`
+}
+
+// SourceView is the JSON representation of the location of a record.
+type SourceView struct {
+	// File is empty if the input came from stdin.
+	File      string `json:"file"`
+	FirstLine int    `json:"first_line"`
+	LastLine  int    `json:"last_line"`
+}
+
+// EvaluationView is the JSON representation of the total time, the should-total
+// time, and the difference between the two. Each value is included both in the
+// textual duration format and as number of minutes.
+type EvaluationView struct {
+	Total           string `json:"total"`
+	TotalMins       int    `json:"total_mins"`
+	ShouldTotal     string `json:"should_total"`
+	ShouldTotalMins int    `json:"should_total_mins"`
+	Diff            string `json:"diff"`
+	DiffMins        int    `json:"diff_mins"`
 }
 
 // EntryView is the JSON representation of an entry.
@@ -48,9 +68,143 @@ type RangeView struct {
 
 // ErrorView is the JSON representation of a parsing error.
 type ErrorView struct {
+	// File is empty if the input came from stdin.
+	File    string `json:"file"`
 	Line    int    `json:"line"`
 	Column  int    `json:"column"`
 	Length  int    `json:"length"`
 	Title   string `json:"title"`
 	Details string `json:"details"`
 }
+
+// TagsEnvelop is the top level data structure of the JSON output of the
+// aggregated tag stats.
+type TagsEnvelop struct {
+	Tags []TagStatsView `json:"tags"`
+}
+
+// TagStatsView is the JSON representation of the aggregated stats of a tag.
+// If Value is empty, the stats refer to the tag as a whole.
+type TagStatsView struct {
+	Tag       string `json:"tag"`
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	Total     string `json:"total"`
+	TotalMins int    `json:"total_mins"`
+	Count     int    `json:"count"`
+
+	// Numeric is only populated if all values of the tag are numbers.
+	Numeric *NumericView `json:"numeric"`
+}
+
+// NumericView is the JSON representation of the numeric tag value stats.
+type NumericView struct {
+	Sum   float64 `json:"sum"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Avg   float64 `json:"avg"`
+	Count int     `json:"count"`
+}
+
+// ReportEnvelop is the top level data structure of the JSON output of the
+// report, i.e. the totals aggregated by period.
+type ReportEnvelop struct {
+	// AggregateBy is one of `day`, `week`, `month`, `quarter`, or `year`.
+	AggregateBy string             `json:"aggregate_by"`
+	Periods     []ReportPeriodView `json:"periods"`
+	Total       BalanceView        `json:"total"`
+}
+
+// ReportPeriodView is the JSON representation of one period in the report.
+type ReportPeriodView struct {
+	// Period is the identifier of the period, in the same format as the one
+	// of the `--period` filter (e.g. `2020-W05`). For days, it’s the date.
+	Period string `json:"period"`
+	Since  string `json:"since"`
+	Until  string `json:"until"`
+	BalanceView
+
+	// Labels contains the absences from the calendar files, if any.
+	Labels []string `json:"labels"`
+}
+
+// BalanceView is an EvaluationView with the running balance, i.e. the
+// cumulative sum of the differences up to this point.
+type BalanceView struct {
+	EvaluationView
+	Balance     string `json:"balance"`
+	BalanceMins int    `json:"balance_mins"`
+}
+
+// TotalEnvelop is the top level data structure of the JSON output of the
+// overall total time.
+type TotalEnvelop struct {
+	EvaluationView
+	Records int `json:"records"`
+}
+
+// TodayEnvelop is the top level data structure of the JSON output of the
+// evaluation of the current day.
+type TodayEnvelop struct {
+	// Current refers to today, or to yesterday if there are no records today.
+	Current TodayView      `json:"current"`
+	Other   EvaluationView `json:"other"`
+	All     TodayView      `json:"all"`
+}
+
+// TodayView is an EvaluationView with the forecasted end-time, at which the
+// difference between should-total and total time will be 0.
+type TodayView struct {
+	Date string `json:"date"`
+	EvaluationView
+
+	// EndTime is `null` if it cannot be determined, e.g. because there are
+	// no records for the current day.
+	EndTime *string `json:"end_time"`
+}
+
+// InvoiceEnvelop is the top level data structure of the JSON output of the
+// invoice, i.e. the billed time per rate.
+type InvoiceEnvelop struct {
+	LineItems []LineItemView `json:"line_items"`
+
+	// Sums contains the total amount per currency.
+	Sums []SumView `json:"sums"`
+}
+
+// LineItemView is the JSON representation of the billed time for a rate.
+type LineItemView struct {
+	Tag       string  `json:"tag"`
+	Total     string  `json:"total"`
+	TotalMins int     `json:"total_mins"`
+	Hours     float64 `json:"hours"`
+	Rate      float64 `json:"rate"`
+	Currency  string  `json:"currency"`
+	Amount    float64 `json:"amount"`
+}
+
+// SumView is the JSON representation of the total amount in one currency.
+type SumView struct {
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"`
+}
+
+// CheckEnvelop is the top level data structure of the JSON output of the
+// checks.
+type CheckEnvelop struct {
+	Findings []FindingView `json:"findings"`
+	Errors   int           `json:"errors"`
+	Warnings int           `json:"warnings"`
+}
+
+// FindingView is the JSON representation of an issue in a record.
+type FindingView struct {
+	// File is empty if the input came from stdin. File and Line are `null`
+	// if the finding has no location, e.g. if it is about a missing record.
+	File     *string `json:"file"`
+	Line     *int    `json:"line"`
+	Date     string  `json:"date"`
+	Rule     string  `json:"rule"`
+	Severity string  `json:"severity"`
+	Message  string  `json:"message"`
+}
@@ -489,3 +489,38 @@ What date is this?!?
 		assert.Equal(t, ErrorInvalidDate().toErrData(17, 0, 10), toErrData(errs[3]))
 	}
 }
+
+func TestParseLenientlyReturnsValidRecordsAlongWithErrors(t *testing.T) {
+	text := `
+2019-08-15
+    16:00-19:41 Something
+
+2019-08-16
+    Entry without value
+
+2019-08-17
+    2h
+
+2019-08-38
+`
+	for _, p := range parsers {
+		rs, blocks, errs := p.ParseLeniently(text)
+		require.Len(t, rs, 2)
+		require.Len(t, blocks, 2)
+		assert.Equal(t, klog.Ɀ_Date_(2019, 8, 15), rs[0].Date())
+		assert.Equal(t, klog.Ɀ_Date_(2019, 8, 17), rs[1].Date())
+		assert.Equal(t, 7, blocks[1].OverallLineIndex(0))
+
+		require.Len(t, errs, 2)
+		assert.Equal(t, ErrorMalformedEntry().toErrData(6, 4, 5), toErrData(errs[0]))
+		assert.Equal(t, ErrorInvalidDate().toErrData(11, 0, 10), toErrData(errs[1]))
+	}
+}
+
+func TestParseLenientlyWithoutErrors(t *testing.T) {
+	for _, p := range parsers {
+		rs, _, errs := p.ParseLeniently("2019-08-15\n    2h\n\n2019-08-16\n")
+		assert.Nil(t, errs)
+		require.Len(t, rs, 2)
+	}
+}
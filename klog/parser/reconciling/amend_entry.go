@@ -0,0 +1,178 @@
+package reconciling
+
+import (
+	"errors"
+	"github.com/jotaen/klog/klog"
+	"strings"
+)
+
+// EntryChange describes how an existing entry shall be modified.
+// Fields that are nil are left as they are.
+type EntryChange struct {
+	Start    klog.Time
+	End      klog.Time
+	Duration klog.Duration
+	Summary  klog.EntrySummary
+}
+
+// AmendEntry modifies the time value and/or the summary of an existing entry.
+// The entry is addressed by its index within the record. The format applies
+// to the start and end time of the change.
+func (r *Reconciler) AmendEntry(entryIndex int, change EntryChange, format ReformatDirective[klog.TimeFormat]) (*Result, error) {
+	if entryIndex < 0 || entryIndex >= len(r.Record.Entries()) {
+		return nil, errors.New("No such entry")
+	}
+	entry := r.Record.Entries()[entryIndex]
+	entryLineIndex := r.lastLinePointer - countLines(r.Record.Entries()[entryIndex:])
+	valueStart, valueEnd := findEntryValue(r.lines[entryLineIndex].Text, entry.Summary())
+
+	if change.Start != nil || change.End != nil || change.Duration != nil {
+		change.Start = r.reformatTime(change.Start, format)
+		change.End = r.reformatTime(change.End, format)
+		newValue, err := r.amendValue(entry, change)
+		if err != nil {
+			return nil, err
+		}
+		text := r.lines[entryLineIndex].Text
+		r.lines[entryLineIndex].Text = text[:valueStart] + newValue + text[valueEnd:]
+		valueEnd = valueStart + len(newValue)
+	}
+
+	if change.Summary != nil {
+		// Replace the first line of the summary, which is on the same line as the
+		// time value, and then replace all subsequent summary lines.
+		firstLine := r.lines[entryLineIndex].Text[:valueEnd]
+		if len(change.Summary) > 0 && change.Summary[0] != "" {
+			firstLine += " " + change.Summary[0]
+		}
+		r.lines[entryLineIndex].Text = firstLine
+		r.remove(entryLineIndex+1, countLines([]klog.Entry{entry})-1)
+		var subsequentSummaryLines []insertableText
+		for i, l := range change.Summary {
+			if i == 0 {
+				continue
+			}
+			subsequentSummaryLines = append(subsequentSummaryLines, insertableText{l, 2})
+		}
+		r.insert(entryLineIndex+1, subsequentSummaryLines)
+	}
+	return r.MakeResult()
+}
+
+// DeleteEntry removes an existing entry from the record.
+// The entry is addressed by its index within the record.
+func (r *Reconciler) DeleteEntry(entryIndex int) (*Result, error) {
+	if entryIndex < 0 || entryIndex >= len(r.Record.Entries()) {
+		return nil, errors.New("No such entry")
+	}
+	entryLineIndex := r.lastLinePointer - countLines(r.Record.Entries()[entryIndex:])
+	r.remove(entryLineIndex, countLines([]klog.Entry{r.Record.Entries()[entryIndex]}))
+	return r.MakeResult()
+}
+
+// DeleteRecord removes the entire record, along with one adjacent blank line.
+// The record of the result is the one that was deleted.
+func (r *Reconciler) DeleteRecord() (*Result, error) {
+	if r.recordPointer == -1 {
+		return nil, errors.New("No such record")
+	}
+	headlineIndex := r.lastLinePointer - countLines(r.Record.Entries()) - len(r.Record.Summary()) - 1
+	r.remove(headlineIndex, r.lastLinePointer-headlineIndex)
+	isBlank := func(i int) bool {
+		return i >= 0 && i < len(r.lines) && strings.TrimSpace(r.lines[i].Text) == ""
+	}
+	if isBlank(headlineIndex) {
+		r.remove(headlineIndex, 1)
+	} else if isBlank(headlineIndex - 1) {
+		r.remove(headlineIndex-1, 1)
+	}
+	r.recordPointer = -1
+	return r.MakeResult()
+}
+
+func (r *Reconciler) reformatTime(t klog.Time, format ReformatDirective[klog.TimeFormat]) klog.Time {
+	if t == nil {
+		return nil
+	}
+	format.apply(r.style.timeFormat(), func(f klog.TimeFormat) {
+		// Re-parse time to apply format.
+		reformattedTime, err := klog.NewTimeFromString(t.ToStringWithFormat(f))
+		if err != nil {
+			panic("INVALID_TIME")
+		}
+		t = reformattedTime
+	})
+	return t
+}
+
+func (r *Reconciler) amendValue(entry klog.Entry, change EntryChange) (string, error) {
+	if change.Duration != nil {
+		if change.Start != nil || change.End != nil {
+			return "", errors.New("A duration cannot be combined with a start or end time")
+		}
+		return change.Duration.ToString(), nil
+	}
+	newRange := func(start klog.Time, end klog.Time, format klog.RangeFormat) (string, error) {
+		tr, err := klog.NewRangeWithFormat(start, end, format)
+		if err != nil {
+			return "", errors.New("Start and end time must be in chronological order")
+		}
+		return tr.ToString(), nil
+	}
+	orDefault := func(t klog.Time, defaultT klog.Time) klog.Time {
+		if t != nil {
+			return t
+		}
+		return defaultT
+	}
+	var err error
+	value := klog.Unbox[string](&entry,
+		func(tr klog.Range) string {
+			var v string
+			v, err = newRange(orDefault(change.Start, tr.Start()), orDefault(change.End, tr.End()), tr.Format())
+			return v
+		},
+		func(_ klog.Duration) string {
+			if change.Start == nil || change.End == nil {
+				err = errors.New("The entry is a duration, so both start and end time are required")
+				return ""
+			}
+			var v string
+			v, err = newRange(change.Start, change.End, klog.RangeFormat{
+				UseSpacesAroundDash: r.style.rangesUseSpacesAroundDash.Get(),
+			})
+			return v
+		},
+		func(or klog.OpenRange) string {
+			start := orDefault(change.Start, or.Start())
+			if change.End == nil {
+				return klog.NewOpenRangeWithFormat(start, or.Format()).ToString()
+			}
+			var v string
+			v, err = newRange(start, change.End, klog.RangeFormat{
+				UseSpacesAroundDash: or.Format().UseSpacesAroundDash,
+			})
+			return v
+		},
+	)
+	return value, err
+}
+
+// findEntryValue returns the position of the time value within the first line
+// of an entry. The time value is preceded by the indentation, and it’s either
+// followed by the first line of the summary, or it’s at the end of the line.
+func findEntryValue(text string, summary klog.EntrySummary) (int, int) {
+	start := len(text) - len(strings.TrimLeft(text, " \t"))
+	end := len(text)
+	if len(summary) > 0 && summary[0] != "" {
+		end -= len(summary[0]) + 1
+	}
+	return start, end
+}
+
+func (r *Reconciler) remove(lineIndex int, count int) {
+	if count <= 0 {
+		return
+	}
+	r.lines = append(r.lines[:lineIndex:lineIndex], r.lines[lineIndex+count:]...)
+}
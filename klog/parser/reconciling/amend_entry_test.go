@@ -0,0 +1,152 @@
+package reconciling
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const amendOriginal = `
+2010-04-26
+    1h
+
+2010-04-27 (8h!)
+Summary
+    9:00 - 10:00 Foo
+        Bar
+    2h #baz
+    -30m
+    13:00-?
+
+2010-04-28
+    3h
+`
+
+func amendReconciler(t *testing.T) *Reconciler {
+	rs, bs, _ := parser.NewSerialParser().Parse(amendOriginal)
+	reconciler := NewReconcilerAtRecord(klog.Ɀ_Date_(2010, 4, 27))(rs, bs)
+	require.NotNil(t, reconciler)
+	return reconciler
+}
+
+func TestReconcilerAmendsTimeRange(t *testing.T) {
+	result, err := amendReconciler(t).AmendEntry(0, EntryChange{Start: klog.Ɀ_Time_(8, 30)}, NoReformat[klog.TimeFormat]())
+	require.Nil(t, err)
+	assert.Contains(t, result.AllSerialised, "\n    8:30 - 10:00 Foo\n        Bar\n")
+	assert.Equal(t, klog.NewDuration(1, 30), result.Record.Entries()[0].Duration())
+
+	result, err = amendReconciler(t).AmendEntry(0, EntryChange{End: klog.Ɀ_Time_(11, 0)}, NoReformat[klog.TimeFormat]())
+	require.Nil(t, err)
+	assert.Contains(t, result.AllSerialised, "\n    9:00 - 11:00 Foo\n")
+}
+
+func TestReconcilerAmendsDuration(t *testing.T) {
+	result, err := amendReconciler(t).AmendEntry(1, EntryChange{Duration: klog.NewDuration(1, 45)}, NoReformat[klog.TimeFormat]())
+	require.Nil(t, err)
+	assert.Contains(t, result.AllSerialised, "\n    1h45m #baz\n")
+
+	result, err = amendReconciler(t).AmendEntry(2, EntryChange{Start: klog.Ɀ_Time_(12, 0), End: klog.Ɀ_Time_(12, 30)}, NoReformat[klog.TimeFormat]())
+	require.Nil(t, err)
+	assert.Contains(t, result.AllSerialised, "\n    12:00-12:30\n")
+}
+
+func TestReconcilerAmendsOpenRange(t *testing.T) {
+	result, err := amendReconciler(t).AmendEntry(3, EntryChange{Start: klog.Ɀ_Time_(13, 15)}, NoReformat[klog.TimeFormat]())
+	require.Nil(t, err)
+	assert.Contains(t, result.AllSerialised, "\n    13:15-?\n")
+
+	result, err = amendReconciler(t).AmendEntry(3, EntryChange{End: klog.Ɀ_Time_(14, 0)}, NoReformat[klog.TimeFormat]())
+	require.Nil(t, err)
+	assert.Contains(t, result.AllSerialised, "\n    13:00-14:00\n")
+}
+
+func TestReconcilerAmendsTimeRangeWithFormat(t *testing.T) {
+	result, err := amendReconciler(t).AmendEntry(0, EntryChange{End: klog.Ɀ_Time_(13, 0)}, ReformatExplicitly(klog.TimeFormat{Use24HourClock: false}))
+	require.Nil(t, err)
+	assert.Contains(t, result.AllSerialised, "\n    9:00 - 1:00pm Foo\n")
+
+	result, err = amendReconciler(t).AmendEntry(2, EntryChange{Start: klog.Ɀ_Time_(12, 0), End: klog.Ɀ_Time_(12, 30)}, ReformatExplicitly(klog.TimeFormat{Use24HourClock: false}))
+	require.Nil(t, err)
+	assert.Contains(t, result.AllSerialised, "\n    12:00pm-12:30pm\n")
+}
+
+func TestReconcilerAmendsSummary(t *testing.T) {
+	result, err := amendReconciler(t).AmendEntry(0, EntryChange{Summary: klog.Ɀ_EntrySummary_("New", "summary", "text")}, NoReformat[klog.TimeFormat]())
+	require.Nil(t, err)
+	assert.Equal(t, `
+2010-04-26
+    1h
+
+2010-04-27 (8h!)
+Summary
+    9:00 - 10:00 New
+        summary
+        text
+    2h #baz
+    -30m
+    13:00-?
+
+2010-04-28
+    3h
+`, result.AllSerialised)
+
+	result, err = amendReconciler(t).AmendEntry(1, EntryChange{Duration: klog.NewDuration(3, 0), Summary: klog.Ɀ_EntrySummary_("")}, NoReformat[klog.TimeFormat]())
+	require.Nil(t, err)
+	assert.Contains(t, result.AllSerialised, "\n    3h\n    -30m\n")
+}
+
+func TestReconcilerRejectsInvalidAmendments(t *testing.T) {
+	for _, x := range []struct {
+		index  int
+		change EntryChange
+	}{
+		{7, EntryChange{Start: klog.Ɀ_Time_(8, 30)}},
+		{0, EntryChange{Start: klog.Ɀ_Time_(11, 0)}},
+		{1, EntryChange{Start: klog.Ɀ_Time_(11, 0)}},
+		{1, EntryChange{Start: klog.Ɀ_Time_(11, 0), Duration: klog.NewDuration(1, 0)}},
+	} {
+		result, err := amendReconciler(t).AmendEntry(x.index, x.change, NoReformat[klog.TimeFormat]())
+		require.Error(t, err)
+		assert.Nil(t, result)
+	}
+}
+
+func TestReconcilerDeletesEntry(t *testing.T) {
+	result, err := amendReconciler(t).DeleteEntry(0)
+	require.Nil(t, err)
+	assert.Contains(t, result.AllSerialised, "\nSummary\n    2h #baz\n")
+	assert.Len(t, result.Record.Entries(), 3)
+
+	result, err = amendReconciler(t).DeleteEntry(3)
+	require.Nil(t, err)
+	assert.Contains(t, result.AllSerialised, "\n    -30m\n\n2010-04-28\n")
+}
+
+func TestReconcilerDeletesRecord(t *testing.T) {
+	result, err := amendReconciler(t).DeleteRecord()
+	require.Nil(t, err)
+	assert.Equal(t, `
+2010-04-26
+    1h
+
+2010-04-28
+    3h
+`, result.AllSerialised)
+	assert.Equal(t, klog.Ɀ_Date_(2010, 4, 27), result.Record.Date())
+	assert.Len(t, result.AllRecords, 2)
+}
+
+func TestReconcilerDeletesFirstAndLastRecord(t *testing.T) {
+	original := "2010-04-26\n    1h\n\n2010-04-27\n    2h\n"
+	rs, bs, _ := parser.NewSerialParser().Parse(original)
+
+	result, err := NewReconcilerAtRecord(klog.Ɀ_Date_(2010, 4, 26))(rs, bs).DeleteRecord()
+	require.Nil(t, err)
+	assert.Equal(t, "2010-04-27\n    2h\n", result.AllSerialised)
+
+	result, err = NewReconcilerAtRecord(klog.Ɀ_Date_(2010, 4, 27))(rs, bs).DeleteRecord()
+	require.Nil(t, err)
+	assert.Equal(t, "2010-04-26\n    1h\n", result.AllSerialised)
+}
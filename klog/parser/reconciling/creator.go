@@ -3,6 +3,7 @@ package reconciling
 import (
 	"github.com/jotaen/klog/klog"
 	"github.com/jotaen/klog/klog/parser/txt"
+	"strings"
 )
 
 // Creator is a function interface for creating a new reconciler.
@@ -11,6 +12,10 @@ type Creator func([]klog.Record, []txt.Block) *Reconciler
 type AdditionalData struct {
 	ShouldTotal klog.ShouldTotal
 	Summary     klog.RecordSummary
+
+	// AppendAtEnd makes the record be appended at the end of the file, instead
+	// of inserting it at the chronological position.
+	AppendAtEnd bool
 }
 
 // NewReconcilerForNewRecord is a reconciler creator for a new record at a given date and
@@ -30,6 +35,7 @@ func NewReconcilerForNewRecord(atDate klog.Date, format ReformatDirective[klog.D
 			lastLinePointer: -1,
 			style:           elect(*defaultStyle(), rs, bs),
 			lines:           flatten(bs),
+			original:        join(bs),
 		}
 		dateValue := atDate.ToString()
 		format.apply(reconciler.style.dateFormat(), func(f klog.DateFormat) {
@@ -49,20 +55,16 @@ func NewReconcilerForNewRecord(atDate klog.Date, format ReformatDirective[klog.D
 			if len(rs) == 0 {
 				return recordText, 0, 1, 0
 			}
-			i := 0
-			for _, r := range rs {
-				if i == 0 && !atDate.IsAfterOrEqual(r.Date()) {
-					// The new record is dated prior to the first one, so we have to append a blank line.
-					recordText = append(recordText, blankLine)
-					return recordText, 0, 1, 0
-				}
-				if len(rs)-1 == i || (atDate.IsAfterOrEqual(r.Date()) && !atDate.IsAfterOrEqual(rs[i+1].Date())) {
-					// The record is in between.
-					break
-				}
-				i++
+			i := len(rs) - 1
+			if !ad.AppendAtEnd {
+				i = indexOfPrecedingRecord(atDate, rs)
+			}
+			if i == -1 {
+				// The new record goes before the first one, so we have to append a blank line.
+				recordText = append(recordText, blankLine)
+				return recordText, 0, 1, 0
 			}
-			// The new record is dated after the last one, so we have to prepend a blank line.
+			// The new record goes after another one, so we have to prepend a blank line.
 			recordText = append([]insertableText{blankLine}, recordText...)
 			return recordText, indexOfLastSignificantLine(bs[i]), 2, i + 1
 		}()
@@ -75,6 +77,23 @@ func NewReconcilerForNewRecord(atDate klog.Date, format ReformatDirective[klog.D
 	}
 }
 
+// indexOfPrecedingRecord determines the chronological position for a new record.
+// It returns the index of the record after which the new one belongs, or -1 if
+// it belongs before the first one. If the first record is dated after the last
+// one, the records are assumed to be sorted in descending order.
+func indexOfPrecedingRecord(atDate klog.Date, rs []klog.Record) int {
+	isDescending := len(rs) > 1 && !rs[len(rs)-1].Date().IsAfterOrEqual(rs[0].Date())
+	for i, r := range rs {
+		if isDescending && !r.Date().IsAfterOrEqual(atDate) {
+			return i - 1
+		}
+		if !isDescending && !atDate.IsAfterOrEqual(r.Date()) {
+			return i - 1
+		}
+	}
+	return len(rs) - 1
+}
+
 // NewReconcilerAtRecord is a reconciler creator for an existing record at a given date.
 func NewReconcilerAtRecord(atDate klog.Date) Creator {
 	return func(rs []klog.Record, bs []txt.Block) *Reconciler {
@@ -95,10 +114,19 @@ func NewReconcilerAtRecord(atDate klog.Date) Creator {
 			lastLinePointer: indexOfLastSignificantLine(bs[index]),
 			recordPointer:   index,
 			lines:           flatten(bs),
+			original:        join(bs),
 		}
 	}
 }
 
+func join(bs []txt.Block) string {
+	var result strings.Builder
+	for _, l := range flatten(bs) {
+		result.WriteString(l.Original())
+	}
+	return result.String()
+}
+
 func flatten(bs []txt.Block) []txt.Line {
 	var result []txt.Line
 	for _, b := range bs {
@@ -145,6 +145,42 @@ func TestReconcileAddBlockInBetween(t *testing.T) {
 	}
 }
 
+func TestReconcileAddBlockInDescendingOrder(t *testing.T) {
+	for _, x := range []struct {
+		original string
+		expected string
+	}{
+		{"2018-01-03\n\n2018-01-01", "2018-01-03\n\n2018-01-02\n\n2018-01-01"},
+		{"2018-01-04\n\n2018-01-03", "2018-01-04\n\n2018-01-03\n\n2018-01-02\n"},
+		{"2018-01-01\n\n2017-01-01", "2018-01-02\n\n2018-01-01\n\n2017-01-01"},
+		{"2018-01-03\n\t1h\n\n2018-01-02\n\n2018-01-01", "2018-01-03\n\t1h\n\n2018-01-02\n\n2018-01-02\n\n2018-01-01"},
+	} {
+		rs, bs, _ := parser.NewSerialParser().Parse(x.original)
+		atDate := klog.Ɀ_Date_(2018, 1, 2)
+		reconciler := NewReconcilerForNewRecord(atDate, NoReformat[klog.DateFormat](), AdditionalData{})(rs, bs)
+		result, err := reconciler.MakeResult()
+		require.Nil(t, err)
+		assert.Equal(t, x.expected, result.AllSerialised)
+	}
+}
+
+func TestReconcileAppendsNewRecordAtEndIfConfigured(t *testing.T) {
+	for _, x := range []struct {
+		original string
+		expected string
+	}{
+		{"2018-01-01\n\n2018-01-03", "2018-01-01\n\n2018-01-03\n\n2018-01-02\n"},
+		{"2018-01-03\n\n2018-01-01\n", "2018-01-03\n\n2018-01-01\n\n2018-01-02\n"},
+	} {
+		rs, bs, _ := parser.NewSerialParser().Parse(x.original)
+		atDate := klog.Ɀ_Date_(2018, 1, 2)
+		reconciler := NewReconcilerForNewRecord(atDate, NoReformat[klog.DateFormat](), AdditionalData{AppendAtEnd: true})(rs, bs)
+		result, err := reconciler.MakeResult()
+		require.Nil(t, err)
+		assert.Equal(t, x.expected, result.AllSerialised)
+	}
+}
+
 func TestReconcileAddRecordWithShouldTotal(t *testing.T) {
 	original := `
 2018-01-01
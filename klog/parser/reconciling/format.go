@@ -0,0 +1,162 @@
+package reconciling
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/parser"
+	"github.com/jotaen/klog/klog/parser/txt"
+	"strings"
+)
+
+// FormatResult is the result of a formatter.
+type FormatResult struct {
+	Original  string
+	Formatted string
+}
+
+// HasChanged checks whether the formatted text differs from the original.
+func (r *FormatResult) HasChanged() bool {
+	return r.Original != r.Formatted
+}
+
+// Format is a function interface for formatting a file.
+type Format func([]klog.Record, []txt.Block) (*FormatResult, error)
+
+// NewFormatter returns a function that serialises all records of a file in
+// a uniform manner. As opposed to the reconciler, it doesn’t retain the
+// original text, but it re-serialises all records from scratch, using the
+// prevailing style of the file. The date and time format can be overridden.
+// Summaries and the order of the records are preserved as is.
+func NewFormatter(dateFormat ReformatDirective[klog.DateFormat], timeFormat ReformatDirective[klog.TimeFormat]) Format {
+	return func(rs []klog.Record, bs []txt.Block) (*FormatResult, error) {
+		original := join(bs)
+		s := elect(*defaultStyle(), rs, bs)
+		dateFormat.apply(s.dateFormat(), func(f klog.DateFormat) {
+			s.dateUseDashes.Set(f.UseDashes)
+		})
+		timeFormat.apply(s.timeFormat(), func(f klog.TimeFormat) {
+			s.timeUse24HourClock.Set(f.Use24HourClock)
+		})
+
+		return formatWithStyle(s, rs, original)
+	}
+}
+
+// formatWithStyle serialises the records in the given style.
+func formatWithStyle(s *style, rs []klog.Record, original string) (*FormatResult, error) {
+	var lines []string
+	for i, r := range rs {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, formatRecord(s, r)...)
+	}
+	var formattedText strings.Builder
+	for _, l := range lines {
+		formattedText.WriteString(l)
+		formattedText.WriteString(s.lineEnding.Get())
+	}
+	formatted := formattedText.String()
+
+	// As a safeguard, make sure the result is parseable and equivalent.
+	newRecords, _, errs := parser.NewSerialParser().Parse(formatted)
+	if errs != nil || len(newRecords) != len(rs) {
+		return nil, errors.New("Formatting wouldn’t result in valid records")
+	}
+	for i, r := range rs {
+		if !isEquivalent(r, newRecords[i]) {
+			return nil, errors.New("Formatting would alter the record at " + r.Date().ToString())
+		}
+	}
+	return &FormatResult{original, formatted}, nil
+}
+
+// isEquivalent checks whether two records have the same contents, regardless
+// of their notation. That is, the date, the should-total, the summary and all
+// entries (including their summaries) must be the same.
+func isEquivalent(r1 klog.Record, r2 klog.Record) bool {
+	if !r1.Date().IsEqualTo(r2.Date()) ||
+		r1.HasShouldTotal() != r2.HasShouldTotal() ||
+		r1.ShouldTotal().InMinutes() != r2.ShouldTotal().InMinutes() ||
+		!equalLines(r1.Summary().Lines(), r2.Summary().Lines()) ||
+		len(r1.Entries()) != len(r2.Entries()) {
+		return false
+	}
+	for i, e1 := range r1.Entries() {
+		e2 := r2.Entries()[i]
+		if valueKey(e1) != valueKey(e2) || !equalLines(e1.Summary().Lines(), e2.Summary().Lines()) {
+			return false
+		}
+	}
+	return true
+}
+
+// valueKey returns a representation of the time value of an entry, which is
+// independent of its notation.
+func valueKey(e klog.Entry) string {
+	minutes := func(t klog.Time) string {
+		return fmt.Sprint(t.MidnightOffset().InMinutes())
+	}
+	return klog.Unbox[string](&e,
+		func(r klog.Range) string { return "range:" + minutes(r.Start()) + "," + minutes(r.End()) },
+		func(d klog.Duration) string { return "duration:" + fmt.Sprint(d.InMinutes()) },
+		func(o klog.OpenRange) string { return "open:" + minutes(o.Start()) },
+	)
+}
+
+func equalLines(l1 []string, l2 []string) bool {
+	if len(l1) != len(l2) {
+		return false
+	}
+	for i := range l1 {
+		if l1[i] != l2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatRecord(s *style, r klog.Record) []string {
+	indentation := s.indentation.Get()
+	var lines []string
+	headline := r.Date().ToStringWithFormat(s.dateFormat())
+	if r.HasShouldTotal() {
+		headline += " (" + r.ShouldTotal().ToString() + ")"
+	}
+	lines = append(lines, headline)
+	lines = append(lines, r.Summary().Lines()...)
+	for _, e := range r.Entries() {
+		value := klog.Unbox[string](&e,
+			func(r klog.Range) string {
+				return formatTimeRange(s, r.Start(), r.End().ToStringWithFormat(s.timeFormat()))
+			},
+			func(d klog.Duration) string {
+				return d.ToString()
+			},
+			func(o klog.OpenRange) string {
+				return formatTimeRange(s, o.Start(), strings.Repeat("?", 1+s.openRangeAdditionalPlaceholderChars.Get()))
+			},
+		)
+		summary := e.Summary().Lines()
+		if len(summary) > 0 && summary[0] != "" {
+			value += " " + summary[0]
+		}
+		lines = append(lines, indentation+value)
+		for i, l := range summary {
+			if i == 0 {
+				continue
+			}
+			lines = append(lines, indentation+indentation+l)
+		}
+	}
+	return lines
+}
+
+func formatTimeRange(s *style, start klog.Time, end string) string {
+	dash := "-"
+	if s.rangesUseSpacesAroundDash.Get() {
+		dash = " - "
+	}
+	return start.ToStringWithFormat(s.timeFormat()) + dash + end
+}
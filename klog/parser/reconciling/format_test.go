@@ -0,0 +1,91 @@
+package reconciling
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestFormatsFileUniformly(t *testing.T) {
+	original := "\n\n" +
+		"2018-01-01 (8h!)\r\n" +
+		"Summary of the   record\r\n" +
+		"\t9:00am-10:00am  Entry summary\r\n" +
+		"\t\tContinued\r\n" +
+		"\t1h30m\r\n" +
+		"\r\n\r\n\r\n" +
+		"2018/01/02 (0m!)\r\n" +
+		"  -90m\r\n" +
+		"  <11:00pm - ??\r\n" +
+		"\r\n" +
+		"2018-01-03\r\n" +
+		"    8:00am - 9:00am\r\n" +
+		"    \r\n"
+	rs, bs, errs := parser.NewSerialParser().Parse(original)
+	require.Nil(t, errs)
+	result, err := NewFormatter(ReformatAutoStyle[klog.DateFormat](), ReformatAutoStyle[klog.TimeFormat]())(rs, bs)
+	require.Nil(t, err)
+	assert.Equal(t, original, result.Original)
+	assert.True(t, result.HasChanged())
+	// The indentation styles are tied, so the default style wins.
+	assert.Equal(t, ""+
+		"2018-01-01 (8h!)\r\n"+
+		"Summary of the   record\r\n"+
+		"    9:00am - 10:00am  Entry summary\r\n"+
+		"        Continued\r\n"+
+		"    1h30m\r\n"+
+		"\r\n"+
+		"2018-01-02 (0m!)\r\n"+
+		"    -1h30m\r\n"+
+		"    <11:00pm - ??\r\n"+
+		"\r\n"+
+		"2018-01-03\r\n"+
+		"    8:00am - 9:00am\r\n",
+		result.Formatted)
+}
+
+func TestFormatsDatesAndTimesExplicitly(t *testing.T) {
+	rs, bs, _ := parser.NewSerialParser().Parse("2018-01-01\n    9:00-13:00\n    14:00 - ?\n")
+	result, err := NewFormatter(
+		ReformatExplicitly(klog.DateFormat{UseDashes: false}),
+		ReformatExplicitly(klog.TimeFormat{Use24HourClock: false}),
+	)(rs, bs)
+	require.Nil(t, err)
+	assert.Equal(t, "2018/01/01\n    9:00am - 1:00pm\n    2:00pm - ?\n", result.Formatted)
+}
+
+func TestFormattingIsIdempotent(t *testing.T) {
+	formatter := NewFormatter(ReformatAutoStyle[klog.DateFormat](), ReformatAutoStyle[klog.TimeFormat]())
+	rs, bs, _ := parser.NewSerialParser().Parse("2018-01-01\n    9:00 - 13:00 #foo\n\n2018-01-02\n    4h\n")
+	result, err := formatter(rs, bs)
+	require.Nil(t, err)
+	assert.False(t, result.HasChanged())
+}
+
+func TestFormatsEmptyFile(t *testing.T) {
+	rs, bs, _ := parser.NewSerialParser().Parse("\n\n")
+	result, err := NewFormatter(ReformatAutoStyle[klog.DateFormat](), ReformatAutoStyle[klog.TimeFormat]())(rs, bs)
+	require.Nil(t, err)
+	assert.Equal(t, "", result.Formatted)
+}
+
+func TestFormatterSafeguardRejectsNonEquivalentResult(t *testing.T) {
+	original := "2018-01-01\n    1h Foo\n"
+	rs, _, errs := parser.NewSerialParser().Parse(original)
+	require.Nil(t, errs)
+
+	// Without indentation, the entry would become part of the record summary,
+	// so the result would still be valid, but not equivalent.
+	s := defaultStyle()
+	s.indentation.Set("")
+	result, err := formatWithStyle(s, rs, original)
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "Formatting would alter the record at 2018-01-01", err.Error())
+
+	result, err = formatWithStyle(defaultStyle(), rs, original)
+	require.Nil(t, err)
+	assert.Equal(t, original, result.Formatted)
+}
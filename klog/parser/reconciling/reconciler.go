@@ -23,6 +23,7 @@ type Reconciler struct {
 	lastLinePointer int // Line index of the last entry
 	lines           []txt.Line
 	recordPointer   int
+	original        string // The text before any modification
 }
 
 // Result is the result of an applied reconciler.
@@ -30,6 +31,7 @@ type Result struct {
 	Record        klog.Record
 	AllRecords    []klog.Record
 	AllSerialised string
+	Original      string
 }
 
 // Reconcile is a function interface for applying a reconciler.
@@ -56,13 +58,35 @@ func (r *Reconciler) MakeResult() (*Result, error) {
 		return nil, errors.New("This operation wouldn’t result in a valid record")
 	}
 
+	record := r.Record
+	if r.recordPointer != -1 {
+		record = newRecords[r.recordPointer]
+	}
 	return &Result{
-		Record:        newRecords[r.recordPointer],
+		Record:        record,
 		AllRecords:    newRecords,
 		AllSerialised: text,
+		Original:      r.original,
 	}, nil
 }
 
+// resync re-parses the modified text, so that the state of the reconciler
+// is consistent again. That allows to apply multiple operations in a row.
+func (r *Reconciler) resync() error {
+	text := ""
+	for _, l := range r.lines {
+		text += l.Original()
+	}
+	records, blocks, errs := parser.NewSerialParser().Parse(text)
+	if errs != nil {
+		return errors.New("This operation wouldn’t result in a valid record")
+	}
+	r.Record = records[r.recordPointer]
+	r.lines = flatten(blocks)
+	r.lastLinePointer = indexOfLastSignificantLine(blocks[r.recordPointer])
+	return nil
+}
+
 // findOpenRangeIndex returns the index of the open range entry, or -1 if no open range.
 func (r *Reconciler) findOpenRangeIndex() int {
 	return r.findLastEntry(func(e klog.Entry) bool {
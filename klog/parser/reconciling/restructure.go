@@ -0,0 +1,160 @@
+package reconciling
+
+import (
+	"errors"
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/parser"
+	"github.com/jotaen/klog/klog/parser/txt"
+	"sort"
+	"strings"
+)
+
+// NewSorter returns a function that reorders the records of a file by date.
+// The text of every record is retained byte by byte; only the blank lines
+// between the records are normalised. Records with the same date keep their
+// relative order. If the records are already sorted, the file stays as is.
+func NewSorter(startWithOldest bool) Format {
+	return func(rs []klog.Record, bs []txt.Block) (*FormatResult, error) {
+		original := join(bs)
+		sortedBlocks, isSorted := sortBlocks(rs, bs, startWithOldest)
+		if isSorted {
+			return &FormatResult{original, original}, nil
+		}
+		sorted, err := joinRecordTexts(sortedBlocks, elect(*defaultStyle(), rs, bs).lineEnding.Get(), len(rs))
+		if err != nil {
+			return nil, err
+		}
+		return &FormatResult{original, sorted}, nil
+	}
+}
+
+// Split distributes the records of a file into groups, as determined by the
+// key function (e.g., one group per month). It returns the keys in the order
+// of their first appearance, and the text for every group. The text of every
+// record is retained byte by byte.
+func Split(rs []klog.Record, bs []txt.Block, key func(klog.Date) string) ([]string, map[string]string, error) {
+	lineEnding := elect(*defaultStyle(), rs, bs).lineEnding.Get()
+	var keys []string
+	groups := make(map[string][]txt.Block)
+	for i, r := range rs {
+		k := key(r.Date())
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], bs[i])
+	}
+	texts := make(map[string]string)
+	for _, k := range keys {
+		text, err := joinRecordTexts(groups[k], lineEnding, len(groups[k]))
+		if err != nil {
+			return nil, nil, err
+		}
+		texts[k] = text
+	}
+	return keys, texts, nil
+}
+
+// MergeInput are the records and blocks of one file, as input for Merge.
+type MergeInput struct {
+	Records []klog.Record
+	Blocks  []txt.Block
+}
+
+// Merge combines the records of multiple files into one, sorted by date
+// (oldest first). The text of every record is retained byte by byte.
+// It returns the merged text, and the dates for which there are records
+// in more than one of the files.
+func Merge(inputs []MergeInput) (string, []klog.Date, error) {
+	var allRecords []klog.Record
+	var allBlocks []txt.Block
+	datesByFile := make(map[string]int)
+	var collisions []klog.Date
+	for i, in := range inputs {
+		for _, r := range in.Records {
+			h := r.Date().ToStringWithFormat(klog.DefaultDateFormat())
+			previousFile, exists := datesByFile[h]
+			if exists && previousFile != i && previousFile != -1 {
+				collisions = append(collisions, r.Date())
+				datesByFile[h] = -1 // So that the date is only reported once.
+			} else if !exists {
+				datesByFile[h] = i
+			}
+		}
+		allRecords = append(allRecords, in.Records...)
+		allBlocks = append(allBlocks, in.Blocks...)
+	}
+	sortedBlocks, _ := sortBlocks(allRecords, allBlocks, true)
+	text, err := joinRecordTexts(sortedBlocks, elect(*defaultStyle(), allRecords, allBlocks).lineEnding.Get(), len(allRecords))
+	if err != nil {
+		return "", nil, err
+	}
+	return text, collisions, nil
+}
+
+// NewMerger returns a function that merges the records of other files into
+// a file, as per Merge. The records of the file itself are treated as the
+// first input. The dates of colliding records are passed to the `check`
+// function, which can abort the merge by returning an error.
+func NewMerger(others []MergeInput, check func([]klog.Date) error) Format {
+	return func(rs []klog.Record, bs []txt.Block) (*FormatResult, error) {
+		merged, collisions, err := Merge(append([]MergeInput{{rs, bs}}, others...))
+		if err != nil {
+			return nil, err
+		}
+		if cErr := check(collisions); cErr != nil {
+			return nil, cErr
+		}
+		return &FormatResult{join(bs), merged}, nil
+	}
+}
+
+// sortBlocks sorts the blocks by the dates of the respective records. Blocks
+// with the same date keep their relative order. It also returns whether the
+// blocks had been sorted already.
+func sortBlocks(rs []klog.Record, bs []txt.Block, startWithOldest bool) ([]txt.Block, bool) {
+	indices := make([]int, len(rs))
+	for i := range rs {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		di, dj := rs[indices[i]].Date(), rs[indices[j]].Date()
+		if startWithOldest {
+			return !di.IsAfterOrEqual(dj)
+		}
+		return !dj.IsAfterOrEqual(di)
+	})
+	isSorted := true
+	sortedBlocks := make([]txt.Block, len(bs))
+	for i, x := range indices {
+		sortedBlocks[i] = bs[x]
+		if i != x {
+			isSorted = false
+		}
+	}
+	return sortedBlocks, isSorted
+}
+
+// joinRecordTexts concatenates the significant lines of the blocks, and puts
+// one blank line in between. As a safeguard, it makes sure that the result is
+// parseable and contains the expected number of records.
+func joinRecordTexts(bs []txt.Block, lineEnding string, expectedRecordCount int) (string, error) {
+	var builder strings.Builder
+	for i, b := range bs {
+		if i > 0 {
+			builder.WriteString(lineEnding)
+		}
+		significantLines, _, _ := b.SignificantLines()
+		for _, l := range significantLines {
+			if l.LineEnding == "" {
+				l.LineEnding = lineEnding
+			}
+			builder.WriteString(l.Original())
+		}
+	}
+	text := builder.String()
+	newRecords, _, errs := parser.NewSerialParser().Parse(text)
+	if errs != nil || len(newRecords) != expectedRecordCount {
+		return "", errors.New("This operation wouldn’t result in valid records")
+	}
+	return text, nil
+}
@@ -0,0 +1,99 @@
+package reconciling
+
+import (
+	"fmt"
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSortsRecordsAndRetainsTheirText(t *testing.T) {
+	original := "" +
+		"2018-01-03\n" +
+		"Third   day\n" +
+		"\t8:00-9:00 Foo\n" +
+		"\n\n\n" +
+		"2018/01/01 (8h!)\n" +
+		"  1h   Bar\n" +
+		"    continued\n" +
+		"\n" +
+		"2018-01-02\n" +
+		"\n"
+	rs, bs, errs := parser.NewSerialParser().Parse(original)
+	require.Nil(t, errs)
+
+	ascending, err := NewSorter(true)(rs, bs)
+	require.Nil(t, err)
+	assert.Equal(t, original, ascending.Original)
+	assert.Equal(t, ""+
+		"2018/01/01 (8h!)\n"+
+		"  1h   Bar\n"+
+		"    continued\n"+
+		"\n"+
+		"2018-01-02\n"+
+		"\n"+
+		"2018-01-03\n"+
+		"Third   day\n"+
+		"\t8:00-9:00 Foo\n", ascending.Formatted)
+
+	descending, err := NewSorter(false)(rs, bs)
+	require.Nil(t, err)
+	assert.Equal(t, ""+
+		"2018-01-03\n"+
+		"Third   day\n"+
+		"\t8:00-9:00 Foo\n"+
+		"\n"+
+		"2018-01-02\n"+
+		"\n"+
+		"2018/01/01 (8h!)\n"+
+		"  1h   Bar\n"+
+		"    continued\n", descending.Formatted)
+}
+
+func TestSortKeepsOrderOfRecordsWithSameDate(t *testing.T) {
+	rs, bs, _ := parser.NewSerialParser().Parse("2018-01-02\n\n2018-01-01\nA\n\n2018-01-01\nB\n")
+	result, err := NewSorter(true)(rs, bs)
+	require.Nil(t, err)
+	assert.Equal(t, "2018-01-01\nA\n\n2018-01-01\nB\n\n2018-01-02\n", result.Formatted)
+}
+
+func TestSortLeavesSortedFileUntouched(t *testing.T) {
+	original := "\n2018-01-01\n\n\n2018-01-02\n    1h\n\n"
+	rs, bs, _ := parser.NewSerialParser().Parse(original)
+	result, err := NewSorter(true)(rs, bs)
+	require.Nil(t, err)
+	assert.False(t, result.HasChanged())
+}
+
+func TestSplitsRecordsIntoGroups(t *testing.T) {
+	rs, bs, _ := parser.NewSerialParser().Parse("2019-12-31\n\n2020-01-01\n  1h\n\n2019-01-05\nFoo\n\n2020-03-01\n")
+	keys, texts, err := Split(rs, bs, func(d klog.Date) string {
+		return fmt.Sprintf("%d", d.Year())
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"2019", "2020"}, keys)
+	assert.Equal(t, "2019-12-31\n\n2019-01-05\nFoo\n", texts["2019"])
+	assert.Equal(t, "2020-01-01\n  1h\n\n2020-03-01\n", texts["2020"])
+}
+
+func TestMergesRecordsAndDetectsCollisions(t *testing.T) {
+	rs1, bs1, _ := parser.NewSerialParser().Parse("2020-01-01\n\t1h\n\n2020-01-03\n")
+	rs2, bs2, _ := parser.NewSerialParser().Parse("2020/01/02\nFoo\n\n2020/01/03\n\t2h\n")
+	rs3, bs3, _ := parser.NewSerialParser().Parse("2020-01-03\n")
+
+	text, collisions, err := Merge([]MergeInput{{rs1, bs1}, {rs2, bs2}, {rs3, bs3}})
+	require.Nil(t, err)
+	assert.Equal(t, "2020-01-01\n\t1h\n\n2020/01/02\nFoo\n\n2020-01-03\n\n2020/01/03\n\t2h\n\n2020-01-03\n", text)
+	require.Len(t, collisions, 1)
+	assert.True(t, klog.Ɀ_Date_(2020, 1, 3).IsEqualTo(collisions[0]))
+}
+
+func TestMergeDoesNotReportDuplicatesWithinTheSameFile(t *testing.T) {
+	rs1, bs1, _ := parser.NewSerialParser().Parse("2020-01-01\n\n2020-01-01\n")
+	rs2, bs2, _ := parser.NewSerialParser().Parse("2020-01-02\n")
+	_, collisions, err := Merge([]MergeInput{{rs1, bs1}, {rs2, bs2}})
+	require.Nil(t, err)
+	assert.Len(t, collisions, 0)
+}
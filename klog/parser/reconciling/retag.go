@@ -0,0 +1,114 @@
+package reconciling
+
+import (
+	"errors"
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/parser"
+	"github.com/jotaen/klog/klog/parser/txt"
+	"strings"
+)
+
+// NewRetagger returns a function that replaces a tag in the summaries of a
+// file. `isEligible` decides for every entry whether its summary is retagged,
+// and, with a `nil` entry, whether the record summary is retagged. Everything
+// else in the file stays as it is.
+//
+// If `from` has no value, it matches all occurrences of the tag regardless of
+// their value, and it also matches its descendants (e.g., `#a` matches
+// `#a/b`, which becomes `#c/b` when replacing by `#c`). The original value is
+// retained, unless `to` has a value. If `from` has a value, it only matches
+// occurrences with that exact value, which are entirely replaced by `to`.
+func NewRetagger(from klog.Tag, to klog.Tag, isEligible func(klog.Record, *klog.Entry) bool) Format {
+	return func(rs []klog.Record, bs []txt.Block) (*FormatResult, error) {
+		original := join(bs)
+		var builder strings.Builder
+		for i, b := range bs {
+			eligibleLines := eligibleLinesOfRecord(rs[i], isEligible)
+			_, headCount, _ := b.SignificantLines()
+			for j, l := range b.Lines() {
+				// Tags can only appear in summaries, so there is no need
+				// to distinguish between the headline and the summary lines.
+				k := j - headCount
+				if k >= 0 && k < len(eligibleLines) && eligibleLines[k] {
+					l.Text = retagText(l.Text, from, to)
+				}
+				builder.WriteString(l.Original())
+			}
+		}
+		retagged := builder.String()
+
+		// As a safeguard, make sure the result is still parseable.
+		newRecords, _, errs := parser.NewSerialParser().Parse(retagged)
+		if errs != nil || len(newRecords) != len(rs) {
+			return nil, errors.New("Retagging wouldn’t result in valid records")
+		}
+		return &FormatResult{original, retagged}, nil
+	}
+}
+
+// eligibleLinesOfRecord tells for each significant line of the record whether
+// it is eligible. That is the headline, followed by the record summary lines,
+// followed by the lines of all entries.
+func eligibleLinesOfRecord(r klog.Record, isEligible func(klog.Record, *klog.Entry) bool) []bool {
+	isSummaryEligible := isEligible(r, nil)
+	lines := []bool{isSummaryEligible}
+	for range r.Summary() {
+		lines = append(lines, isSummaryEligible)
+	}
+	for _, e := range r.Entries() {
+		isEntryEligible := isEligible(r, &e)
+		for range e.Summary() {
+			lines = append(lines, isEntryEligible)
+		}
+	}
+	return lines
+}
+
+func retagText(text string, from klog.Tag, to klog.Tag) string {
+	var result strings.Builder
+	previousEnd := 0
+	for _, m := range klog.HashTagPattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := m[0], m[1]
+		rawName := text[m[2]:m[3]]
+		rawValue := ""
+		if m[4] != -1 {
+			rawValue = text[m[4]:m[5]]
+		}
+		replacement, isMatch := retagOccurrence(rawName, rawValue, from, to)
+		if !isMatch {
+			continue
+		}
+		result.WriteString(text[previousEnd:start])
+		result.WriteString(replacement)
+		previousEnd = end
+	}
+	result.WriteString(text[previousEnd:])
+	return result.String()
+}
+
+// retagOccurrence returns the replacement for a tag in the text, where
+// `rawName` is the tag name as it appears in the text, and `rawValue` the
+// value including the `=` and the quotes, if any.
+func retagOccurrence(rawName string, rawValue string, from klog.Tag, to klog.Tag) (string, bool) {
+	if from.Value() != "" {
+		t, err := klog.NewTagFromString("#" + rawName + rawValue)
+		if err != nil || t != from {
+			return "", false
+		}
+		return to.ToString(), true
+	}
+	segments := strings.Split(rawName, klog.HierarchyDelimiter)
+	fromSegmentCount := len(strings.Split(from.Name(), klog.HierarchyDelimiter))
+	if len(segments) < fromSegmentCount ||
+		strings.ToLower(strings.Join(segments[:fromSegmentCount], klog.HierarchyDelimiter)) != from.Name() {
+		return "", false
+	}
+	newName := to.Name()
+	for _, s := range segments[fromSegmentCount:] {
+		newName += klog.HierarchyDelimiter + s
+	}
+	if to.Value() != "" {
+		return klog.NewTagOrPanic(newName, to.Value()).ToString(), true
+	}
+	return "#" + newName + rawValue, true
+}
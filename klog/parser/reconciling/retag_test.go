@@ -0,0 +1,79 @@
+package reconciling
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func retag(t *testing.T, text string, from string, to string) string {
+	rs, bs, errs := parser.NewSerialParser().Parse(text)
+	require.Nil(t, errs)
+	fromTag, _ := klog.NewTagFromString(from)
+	toTag, _ := klog.NewTagFromString(to)
+	result, err := NewRetagger(fromTag, toTag, func(klog.Record, *klog.Entry) bool { return true })(rs, bs)
+	require.Nil(t, err)
+	assert.Equal(t, text, result.Original)
+	return result.Formatted
+}
+
+func TestRetagsRecordAndEntrySummaries(t *testing.T) {
+	original := "" +
+		"2020-01-01\r\n" +
+		"Working on #foo,   #FOO and #foobar\r\n" +
+		"\t8:00-9:00 #foo\r\n" +
+		"\t\tStill #Foo.\r\n" +
+		"\t1h #bar #foo\r\n" +
+		"\r\n" +
+		"2020-01-02\r\n" +
+		"    1h #baz"
+	assert.Equal(t, ""+
+		"2020-01-01\r\n"+
+		"Working on #new,   #new and #foobar\r\n"+
+		"\t8:00-9:00 #new\r\n"+
+		"\t\tStill #new.\r\n"+
+		"\t1h #bar #new\r\n"+
+		"\r\n"+
+		"2020-01-02\r\n"+
+		"    1h #baz", retag(t, original, "#foo", "#new"))
+}
+
+func TestRetagRetainsValuesAndDescendants(t *testing.T) {
+	original := "2020-01-01\n    1h #foo=1 #foo='a b' #foo/Sub=x #foo=\n"
+	assert.Equal(t,
+		"2020-01-01\n    1h #new=1 #new='a b' #new/Sub=x #new=\n",
+		retag(t, original, "#foo", "#new"))
+	assert.Equal(t,
+		"2020-01-01\n    1h #new=2 #new=2 #new/sub=2 #new=2\n",
+		retag(t, original, "#foo", "#new=2"))
+}
+
+func TestRetagWithValueOnlyReplacesMatchingValues(t *testing.T) {
+	original := "2020-01-01\n    1h #foo=1 #foo=\"1\" #foo=2 #foo\n"
+	assert.Equal(t,
+		"2020-01-01\n    1h #new=\"x y\", #foo=2 #foo\n",
+		retag(t, "2020-01-01\n    1h #foo=1, #foo=2 #foo\n", "#foo=1", "#new='x y'"))
+	assert.Equal(t,
+		"2020-01-01\n    1h #bar #bar #foo=2 #foo\n",
+		retag(t, original, "#foo=1", "#bar"))
+}
+
+func TestRetagOnlyAppliesToEligibleRecords(t *testing.T) {
+	rs, bs, _ := parser.NewSerialParser().Parse("2020-01-01\n#foo\n\n2020-01-02\n#foo\n")
+	result, err := NewRetagger(klog.NewTagOrPanic("foo", ""), klog.NewTagOrPanic("bar", ""), func(r klog.Record, _ *klog.Entry) bool {
+		return r.Date().IsEqualTo(klog.Ɀ_Date_(2020, 1, 2))
+	})(rs, bs)
+	require.Nil(t, err)
+	assert.Equal(t, "2020-01-01\n#foo\n\n2020-01-02\n#bar\n", result.Formatted)
+}
+
+func TestRetagOnlyAppliesToEligibleEntries(t *testing.T) {
+	rs, bs, _ := parser.NewSerialParser().Parse("2020-01-01\n#foo\n    1h #foo #x\n        More #foo\n    2h #foo\n")
+	result, err := NewRetagger(klog.NewTagOrPanic("foo", ""), klog.NewTagOrPanic("bar", ""), func(_ klog.Record, e *klog.Entry) bool {
+		return e != nil && e.Summary().Tags().Contains(klog.NewTagOrPanic("x", ""))
+	})(rs, bs)
+	require.Nil(t, err)
+	assert.Equal(t, "2020-01-01\n#foo\n    1h #bar #x\n        More #bar\n    2h #foo\n", result.Formatted)
+}
@@ -92,10 +92,11 @@ func defaultStyle() *style {
 
 type election[T comparable] struct {
 	votes map[T]int
+	order []T
 }
 
 func newElection[T comparable]() election[T] {
-	return election[T]{make(map[T]int)}
+	return election[T]{make(map[T]int), nil}
 }
 
 // vote casts a vote for the style, but only if it’s explicit.
@@ -103,16 +104,20 @@ func (e *election[T]) vote(style styleProp[T]) {
 	if !style.isExplicit {
 		return
 	}
+	if _, ok := e.votes[style.value]; !ok {
+		e.order = append(e.order, style.value)
+	}
 	e.votes[style.value] += 1
 }
 
-// tallyUp returns the style that’s most voted for.
+// tallyUp returns the style that’s most voted for. In case of a tie, the
+// default value wins, otherwise the one that was voted for first.
 func (e *election[T]) tallyUp(defaultValue T) T {
-	max := 0
+	max := e.votes[defaultValue]
 	result := defaultValue
-	for value, count := range e.votes {
-		if count > max {
-			max = count
+	for _, value := range e.order {
+		if e.votes[value] > max {
+			max = e.votes[value]
 			result = value
 		}
 	}
@@ -98,6 +98,22 @@ func TestElectStyleDoesNotOverrideSetPreferences(t *testing.T) {
 	}, result)
 }
 
+func TestElectStyleResolvesTiesDeterministically(t *testing.T) {
+	rs, bs := parseOrPanic(
+		"2001/05/19\n  1:00am-2:00pm\n\n",
+		"2001-05-19\n\t1:00 - 2:00\n\n",
+	)
+	for i := 0; i < 20; i++ {
+		result := elect(*defaultStyle(), rs, bs)
+		// Ties are resolved in favour of the default style ...
+		assert.True(t, result.dateUseDashes.Get())
+		assert.True(t, result.timeUse24HourClock.Get())
+		assert.True(t, result.rangesUseSpacesAroundDash.Get())
+		// ... or, if that’s not part of the tie, the first encountered style.
+		assert.Equal(t, "  ", result.indentation.Get())
+	}
+}
+
 func parseOrPanic(recordsAsText ...string) ([]klog.Record, []txt.Block) {
 	rs, bs, err := parser.NewSerialParser().Parse(strings.Join(recordsAsText, ""))
 	if err != nil {
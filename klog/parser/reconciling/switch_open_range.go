@@ -0,0 +1,19 @@
+package reconciling
+
+import (
+	"github.com/jotaen/klog/klog"
+)
+
+// SwitchOpenRange closes the open time range, and starts a new one right away.
+// The end time of the closed range and the start time of the new one are identical.
+func (r *Reconciler) SwitchOpenRange(time klog.Time, format ReformatDirective[klog.TimeFormat], additionalSummary klog.EntrySummary, newSummary klog.EntrySummary) (*Result, error) {
+	_, cErr := r.CloseOpenRange(time, format, additionalSummary)
+	if cErr != nil {
+		return nil, cErr
+	}
+	rErr := r.resync()
+	if rErr != nil {
+		return nil, rErr
+	}
+	return r.StartOpenRange(time, format, newSummary)
+}
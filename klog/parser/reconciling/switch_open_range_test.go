@@ -0,0 +1,57 @@
+package reconciling
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestReconcilerSwitchesOpenRange(t *testing.T) {
+	original := `
+2010-04-27
+    9:00-10:00
+    10:00 - ? Foo
+        Bar
+
+2010-04-28
+    1h
+`
+	rs, bs, _ := parser.NewSerialParser().Parse(original)
+	reconciler := NewReconcilerAtRecord(klog.Ɀ_Date_(2010, 4, 27))(rs, bs)
+	require.NotNil(t, reconciler)
+	result, err := reconciler.SwitchOpenRange(
+		klog.Ɀ_Time_(11, 15),
+		NoReformat[klog.TimeFormat](),
+		klog.Ɀ_EntrySummary_("Baz", "Qux"),
+		klog.Ɀ_EntrySummary_("Next"),
+	)
+	require.Nil(t, err)
+	assert.Equal(t, `
+2010-04-27
+    9:00-10:00
+    10:00 - 11:15 Foo
+        Bar Baz
+        Qux
+    11:15 - ? Next
+
+2010-04-28
+    1h
+`, result.AllSerialised)
+	require.Len(t, result.Record.Entries(), 3)
+	assert.Equal(t, klog.NewDuration(1, 15), result.Record.Entries()[1].Duration())
+}
+
+func TestReconcilerSwitchFailsWithoutOpenRange(t *testing.T) {
+	original := `
+2010-04-27
+    9:00-10:00
+`
+	rs, bs, _ := parser.NewSerialParser().Parse(original)
+	reconciler := NewReconcilerAtRecord(klog.Ɀ_Date_(2010, 4, 27))(rs, bs)
+	require.NotNil(t, reconciler)
+	result, err := reconciler.SwitchOpenRange(klog.Ɀ_Time_(11, 15), NoReformat[klog.TimeFormat](), nil, nil)
+	require.Error(t, err)
+	assert.Nil(t, result)
+}
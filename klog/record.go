@@ -11,7 +11,7 @@ import (
 
 // SPEC_VERSION contains the version number of the file format
 // specification which this implementation is based on.
-const SPEC_VERSION = "1.4"
+const SPEC_VERSION = "1.5"
 
 // Record is a self-contained data container that holds the time tracking
 // information associated with a certain date.
@@ -21,6 +21,10 @@ type Record interface {
 	ShouldTotal() ShouldTotal
 	SetShouldTotal(Duration)
 
+	// HasShouldTotal checks whether a should-total was set explicitly.
+	// If not, ShouldTotal returns a zero duration.
+	HasShouldTotal() bool
+
 	Summary() RecordSummary
 	SetSummary(RecordSummary)
 
@@ -43,6 +47,23 @@ type Record interface {
 	// no open time range present, or if start and end time cannot be converted
 	// into a valid time range.
 	EndOpenRange(Time) error
+
+	// Source returns the location of the record in the file that it was read
+	// from, or `nil` if it is unknown.
+	Source() *Source
+	SetSource(Source)
+}
+
+// Source is the location of a record in a file.
+type Source struct {
+	// Path is the path of the file. It is empty if the record wasn’t read
+	// from a file, e.g. from stdin.
+	Path string
+
+	// FirstLine and LastLine are the line numbers of the record’s text,
+	// starting at 1.
+	FirstLine int
+	LastLine  int
 }
 
 func NewRecord(date Date) Record {
@@ -56,6 +77,7 @@ type record struct {
 	shouldTotal ShouldTotal
 	summary     RecordSummary
 	entries     []Entry
+	source      *Source
 }
 
 func (r *record) Date() Date {
@@ -69,6 +91,10 @@ func (r *record) ShouldTotal() ShouldTotal {
 	return r.shouldTotal
 }
 
+func (r *record) HasShouldTotal() bool {
+	return r.shouldTotal != nil
+}
+
 func (r *record) SetShouldTotal(t Duration) {
 	r.shouldTotal = NewShouldTotal(0, t.InMinutes())
 }
@@ -129,3 +155,11 @@ func (r *record) EndOpenRange(end Time) error {
 	}
 	return errors.New("NO_OPEN_RANGE")
 }
+
+func (r *record) Source() *Source {
+	return r.source
+}
+
+func (r *record) SetSource(s Source) {
+	r.source = &s
+}
@@ -0,0 +1,122 @@
+package billing
+
+import (
+	"fmt"
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/service"
+	"sort"
+)
+
+// LineItem is a billable position of an invoice.
+type LineItem struct {
+	Tag klog.Tag
+
+	// Total is the (rounded) billable time.
+	Total klog.Duration
+
+	Rate Rate
+
+	// Amount is the price of the line item in cents. It is rounded to the
+	// nearest cent.
+	Amount int
+}
+
+// Hours returns the billable time as decimal number.
+func (l LineItem) Hours() float64 {
+	return float64(l.Total.InMinutes()) / 60
+}
+
+// Sum is the total amount of all line items with the same currency.
+type Sum struct {
+	Currency string
+
+	// Amount is the sum in cents.
+	Amount int
+}
+
+// Invoice is the result of billing a set of records.
+type Invoice struct {
+	LineItems []LineItem
+	Sums      []Sum
+}
+
+// NewInvoice bills all entries according to the rates. Every entry is billed
+// with the rate that matches its tags (see Rates.Match); entries without
+// matching rate are disregarded. Tag aliases are folded with the registry, both
+// in the entries and in the rates. If a rounding is specified, the duration of
+// each entry is rounded up to the next multiple of the rounding value.
+func NewInvoice(registry service.TagRegistry, rates Rates, rounding service.Rounding, rs ...klog.Record) Invoice {
+	rates = rates.fold(registry)
+	totals := make(map[klog.Tag]klog.Duration)
+	for _, r := range rs {
+		for _, e := range r.Entries() {
+			tag, _, hasRate := rates.Match(registry.FoldAll(klog.Merge(r.Summary().Tags(), e.Summary().Tags())))
+			if !hasRate {
+				continue
+			}
+			if totals[tag] == nil {
+				totals[tag] = klog.NewDuration(0, 0)
+			}
+			totals[tag] = totals[tag].Plus(roundUp(e.Duration(), rounding))
+		}
+	}
+
+	invoice := Invoice{}
+	sums := make(map[string]int)
+	for tag, total := range totals {
+		rate := rates.rates[tag]
+		item := LineItem{
+			Tag:   tag,
+			Total: total,
+			Rate:  rate,
+		}
+		item.Amount = amountInCents(total, rate)
+		invoice.LineItems = append(invoice.LineItems, item)
+		sums[rate.Currency] += item.Amount
+	}
+	sort.Slice(invoice.LineItems, func(i, j int) bool {
+		return invoice.LineItems[i].Tag.ToString() < invoice.LineItems[j].Tag.ToString()
+	})
+	for currency, amount := range sums {
+		invoice.Sums = append(invoice.Sums, Sum{Currency: currency, Amount: amount})
+	}
+	sort.Slice(invoice.Sums, func(i, j int) bool {
+		return invoice.Sums[i].Currency < invoice.Sums[j].Currency
+	})
+	return invoice
+}
+
+func roundUp(d klog.Duration, rounding service.Rounding) klog.Duration {
+	if rounding == nil {
+		return d
+	}
+	mins := d.InMinutes()
+	step := rounding.ToInt()
+	sign := 1
+	if mins < 0 {
+		sign = -1
+		mins = -mins
+	}
+	rounded := (mins + step - 1) / step * step
+	return klog.NewDuration(0, sign*rounded)
+}
+
+// amountInCents returns the price of a duration at the rate, rounded to the
+// nearest cent (half away from zero).
+func amountInCents(d klog.Duration, rate Rate) int {
+	x := d.InMinutes() * rate.Amount
+	if x < 0 {
+		return -((-x + 30) / 60)
+	}
+	return (x + 30) / 60
+}
+
+// FormatCents formats an amount of cents as decimal number, e.g. `150.50`.
+func FormatCents(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
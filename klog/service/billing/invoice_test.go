@@ -0,0 +1,103 @@
+package billing
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCreatesInvoice(t *testing.T) {
+	rates, _ := NewRatesFromString(`
+#acme = 100 EUR
+#acme/backend = 120 EUR
+#globex = 90 USD
+`)
+	r1 := klog.NewRecord(klog.Ɀ_Date_(2020, 1, 1))
+	r1.SetSummary(klog.Ɀ_RecordSummary_("#acme"))
+	r1.AddDuration(klog.NewDuration(1, 30), klog.Ɀ_EntrySummary_("Meeting"))
+	r1.AddDuration(klog.NewDuration(2, 0), klog.Ɀ_EntrySummary_("#acme/backend"))
+	r2 := klog.NewRecord(klog.Ɀ_Date_(2020, 1, 2))
+	r2.AddDuration(klog.NewDuration(0, 20), klog.Ɀ_EntrySummary_("#globex"))
+	r2.AddDuration(klog.NewDuration(5, 0), klog.Ɀ_EntrySummary_("Not billable"))
+
+	invoice := NewInvoice(service.NewEmptyTagRegistry(), rates, nil, r1, r2)
+
+	require.Len(t, invoice.LineItems, 3)
+	assert.Equal(t, LineItem{klog.NewTagOrPanic("acme", ""), klog.NewDuration(1, 30), Rate{10000, "EUR"}, 15000}, invoice.LineItems[0])
+	assert.Equal(t, LineItem{klog.NewTagOrPanic("acme/backend", ""), klog.NewDuration(2, 0), Rate{12000, "EUR"}, 24000}, invoice.LineItems[1])
+	assert.Equal(t, LineItem{klog.NewTagOrPanic("globex", ""), klog.NewDuration(0, 20), Rate{9000, "USD"}, 3000}, invoice.LineItems[2])
+	assert.Equal(t, []Sum{{"EUR", 39000}, {"USD", 3000}}, invoice.Sums)
+}
+
+func TestCreatesInvoiceWithRounding(t *testing.T) {
+	rates, _ := NewRatesFromString(`#acme = 100 EUR`)
+	r := klog.NewRecord(klog.Ɀ_Date_(2020, 1, 1))
+	r.AddDuration(klog.NewDuration(0, 1), klog.Ɀ_EntrySummary_("#acme"))
+	r.AddDuration(klog.NewDuration(0, 15), klog.Ɀ_EntrySummary_("#acme"))
+	r.AddDuration(klog.NewDuration(0, 16), klog.Ɀ_EntrySummary_("#acme"))
+	r.AddDuration(klog.NewDuration(0, -10), klog.Ɀ_EntrySummary_("#acme"))
+	rounding, _ := service.NewRounding(15)
+
+	invoice := NewInvoice(service.NewEmptyTagRegistry(), rates, rounding, r)
+
+	require.Len(t, invoice.LineItems, 1)
+	assert.Equal(t, klog.NewDuration(0, 45), invoice.LineItems[0].Total)
+	assert.Equal(t, 0.75, invoice.LineItems[0].Hours())
+	assert.Equal(t, 7500, invoice.LineItems[0].Amount)
+}
+
+func TestCreatesEmptyInvoice(t *testing.T) {
+	rates, _ := NewRatesFromString(`#acme = 100 EUR`)
+	r := klog.NewRecord(klog.Ɀ_Date_(2020, 1, 1))
+	r.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#foo"))
+
+	invoice := NewInvoice(service.NewEmptyTagRegistry(), rates, nil, r)
+	assert.Nil(t, invoice.LineItems)
+	assert.Nil(t, invoice.Sums)
+}
+
+func TestCreatesInvoiceWithTagAliases(t *testing.T) {
+	registry, _ := service.NewTagRegistryFromString("#meeting = #mtg\n#acme = #ac\n")
+	rates, _ := NewRatesFromString(`
+#meeting = 50 EUR
+#ac/backend = 120 EUR
+`)
+	r := klog.NewRecord(klog.Ɀ_Date_(2020, 1, 1))
+	r.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#mtg"))
+	r.AddDuration(klog.NewDuration(2, 0), klog.Ɀ_EntrySummary_("#meeting"))
+	r.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#acme/backend"))
+
+	invoice := NewInvoice(registry, rates, nil, r)
+
+	require.Len(t, invoice.LineItems, 2)
+	assert.Equal(t, klog.NewTagOrPanic("acme/backend", ""), invoice.LineItems[0].Tag)
+	assert.Equal(t, 12000, invoice.LineItems[0].Amount)
+	assert.Equal(t, klog.NewTagOrPanic("meeting", ""), invoice.LineItems[1].Tag)
+	assert.Equal(t, klog.NewDuration(3, 0), invoice.LineItems[1].Total)
+	assert.Equal(t, 15000, invoice.LineItems[1].Amount)
+}
+
+func TestSumsAmountsInCents(t *testing.T) {
+	rates, _ := NewRatesFromString("#a = 0.10 EUR\n#b = 0.20 EUR\n#c = 100 EUR\n")
+	r := klog.NewRecord(klog.Ɀ_Date_(2020, 1, 1))
+	r.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#a"))
+	r.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#b"))
+	r.AddDuration(klog.NewDuration(0, 10), klog.Ɀ_EntrySummary_("#c"))
+
+	invoice := NewInvoice(service.NewEmptyTagRegistry(), rates, nil, r)
+
+	require.Len(t, invoice.LineItems, 3)
+	assert.Equal(t, 10, invoice.LineItems[0].Amount)
+	assert.Equal(t, 20, invoice.LineItems[1].Amount)
+	assert.Equal(t, 1667, invoice.LineItems[2].Amount)
+	assert.Equal(t, []Sum{{"EUR", 1697}}, invoice.Sums)
+}
+
+func TestFormatsCents(t *testing.T) {
+	assert.Equal(t, "0.00", FormatCents(0))
+	assert.Equal(t, "0.05", FormatCents(5))
+	assert.Equal(t, "150.50", FormatCents(15050))
+	assert.Equal(t, "-12.34", FormatCents(-1234))
+}
@@ -0,0 +1,128 @@
+/*
+Package billing contains the logic for calculating invoices from records,
+based on hourly rates per tag.
+*/
+package billing
+
+import (
+	"errors"
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/service"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Rate is an hourly rate.
+type Rate struct {
+	// Amount is the rate in cents.
+	Amount int
+
+	// Currency is an arbitrary currency identifier, e.g. `EUR`. It might be empty.
+	Currency string
+}
+
+// Rates is a registry of hourly rates per tag.
+type Rates struct {
+	rates map[klog.Tag]Rate
+}
+
+// NewEmptyRates creates a registry without any rates.
+func NewEmptyRates() Rates {
+	return Rates{make(map[klog.Tag]Rate)}
+}
+
+var rateValuePattern = regexp.MustCompile(`^(\d+)(?:\.(\d{1,2}))?(?:\s+(\S+))?$`)
+
+// NewRatesFromString parses the contents of a rates file. Every line contains
+// a tag and the respective rate, optionally followed by a currency:
+//
+//	#acme = 120 EUR
+//	#acme=internal = 0 EUR
+//
+// The tag and the rate are separated by the last `=` in the line, which can
+// be surrounded by any whitespace. The leading `#` of the tag is optional.
+// The rate can have up to two decimal places. Blank lines, and lines that
+// start with `;` or `//`, are ignored.
+func NewRatesFromString(text string) (Rates, error) {
+	rates := NewEmptyRates()
+	for i, l := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" || strings.HasPrefix(l, ";") || strings.HasPrefix(l, "//") {
+			continue
+		}
+		lineNr := strconv.Itoa(i + 1)
+		delimiterI := strings.LastIndex(l, "=")
+		if delimiterI == -1 {
+			return Rates{}, errors.New("Malformed syntax in line " + lineNr)
+		}
+		tagText, valueText := l[:delimiterI], l[delimiterI+1:]
+		tag, tErr := klog.NewTagFromString(strings.TrimSpace(tagText))
+		if tErr != nil {
+			return Rates{}, errors.New("Invalid tag in line " + lineNr)
+		}
+		match := rateValuePattern.FindStringSubmatch(strings.TrimSpace(valueText))
+		if match == nil {
+			return Rates{}, errors.New("Invalid rate in line " + lineNr)
+		}
+		if _, exists := rates.rates[tag]; exists {
+			return Rates{}, errors.New("Duplicate tag in line " + lineNr)
+		}
+		units, _ := strconv.Atoi(match[1])
+		cents, _ := strconv.Atoi((match[2] + "00")[:2])
+		rates.rates[tag] = Rate{Amount: units*100 + cents, Currency: match[3]}
+	}
+	return rates, nil
+}
+
+// IsEmpty checks whether there are any rates.
+func (rs Rates) IsEmpty() bool {
+	return len(rs.rates) == 0
+}
+
+// Match finds the rate that applies to the given tags. If multiple rates
+// apply, the most specific one wins: a tag with value is more specific
+// than the same tag without value, and a nested tag is more specific than
+// its parent. In case of a tie, the alphabetically first tag is used.
+// It returns `false` if none applies.
+func (rs Rates) Match(ts klog.TagSet) (klog.Tag, Rate, bool) {
+	var best *klog.Tag
+	for t := range ts {
+		if _, hasRate := rs.rates[t]; !hasRate {
+			continue
+		}
+		if best == nil || isMoreSpecific(t, *best) {
+			candidate := t
+			best = &candidate
+		}
+	}
+	if best == nil {
+		return klog.Tag{}, Rate{}, false
+	}
+	return *best, rs.rates[*best], true
+}
+
+// fold returns the rates with all tags in their canonical form. If there is a
+// rate for both an alias and its canonical tag, the latter one prevails.
+func (rs Rates) fold(registry service.TagRegistry) Rates {
+	result := NewEmptyRates()
+	for t, rate := range rs.rates {
+		folded := registry.Fold(t)
+		if _, exists := result.rates[folded]; exists && folded != t {
+			continue
+		}
+		result.rates[folded] = rate
+	}
+	return result
+}
+
+func isMoreSpecific(t1 klog.Tag, t2 klog.Tag) bool {
+	depth1, depth2 := len(t1.Ancestors()), len(t2.Ancestors())
+	if depth1 != depth2 {
+		return depth1 > depth2
+	}
+	if (t1.Value() == "") != (t2.Value() == "") {
+		return t1.Value() != ""
+	}
+	return t1.ToString() < t2.ToString()
+}
@@ -0,0 +1,85 @@
+package billing
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParsesRates(t *testing.T) {
+	rates, err := NewRatesFromString(`
+; Clients
+#acme = 120 EUR
+acme=internal = 0 EUR
+#globex/consulting = 150.5   USD
+// Without currency
+#misc = 10
+#other=20.25
+#other/a  =  30 EUR
+`)
+	require.Nil(t, err)
+	assert.Equal(t, map[klog.Tag]Rate{
+		klog.NewTagOrPanic("acme", ""):              {12000, "EUR"},
+		klog.NewTagOrPanic("acme", "internal"):      {0, "EUR"},
+		klog.NewTagOrPanic("globex/consulting", ""): {15050, "USD"},
+		klog.NewTagOrPanic("misc", ""):              {1000, ""},
+		klog.NewTagOrPanic("other", ""):             {2025, ""},
+		klog.NewTagOrPanic("other/a", ""):           {3000, "EUR"},
+	}, rates.rates)
+}
+
+func TestParsesEmptyRates(t *testing.T) {
+	rates, err := NewRatesFromString("\n; Nothing here yet\n")
+	require.Nil(t, err)
+	assert.True(t, rates.IsEmpty())
+}
+
+func TestRejectsInvalidRates(t *testing.T) {
+	for _, text := range []string{
+		"#acme 120 EUR",
+		"#acme = 120.125 EUR",
+		"#acme = 120. EUR",
+		"#acme =",
+		"#ac me = 120 EUR",
+		"#acme = EUR",
+		"#acme = -120 EUR",
+		"#acme = 120 EUR USD",
+		"#acme = 120,5 EUR",
+		"#acme = 120 EUR\n#ACME = 100 EUR",
+	} {
+		_, err := NewRatesFromString(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestMatchesMostSpecificRate(t *testing.T) {
+	rates, _ := NewRatesFromString(`
+#acme = 100
+#acme=internal = 0
+#acme/backend = 120
+#zzz = 50
+#aaa = 70
+`)
+	for _, x := range []struct {
+		summary  string
+		expected string
+	}{
+		{"#acme", "#acme"},
+		{"#acme=foo", "#acme"},
+		{"#acme=internal", "#acme=internal"},
+		{"#acme/backend", "#acme/backend"},
+		{"#acme/backend/db", "#acme/backend"},
+		{"#acme/backend #acme=internal", "#acme/backend"},
+		{"#zzz #aaa", "#aaa"},
+		{"#acme #zzz", "#acme"},
+	} {
+		tags := klog.Ɀ_EntrySummary_(x.summary).Tags()
+		tag, _, ok := rates.Match(tags)
+		require.True(t, ok, x.summary)
+		assert.Equal(t, x.expected, tag.ToString(), x.summary)
+	}
+
+	_, _, ok := rates.Match(klog.Ɀ_EntrySummary_("#foo").Tags())
+	assert.False(t, ok)
+}
@@ -0,0 +1,100 @@
+/*
+Package calendar contains the logic for processing absence calendars, such as
+public holidays, vacation, or sick days. The calendars are read from iCalendar
+(`.ics`) files.
+*/
+package calendar
+
+import (
+	"github.com/jotaen/klog/klog"
+	"strings"
+	gotime "time"
+)
+
+// Calendar is a collection of absences.
+type Calendar struct {
+	events []event
+}
+
+// Absence is an occurrence of a calendar event at a particular date.
+type Absence struct {
+	// Summary is the title of the calendar event, e.g. `Christmas`.
+	Summary string
+
+	// Duration is the length of the absence at that date. It is `nil` if the
+	// absence lasts the whole day.
+	Duration klog.Duration
+}
+
+// NewEmptyCalendar creates a calendar without any events.
+func NewEmptyCalendar() Calendar {
+	return Calendar{}
+}
+
+// Merge combines the events of multiple calendars into one.
+func Merge(cs ...Calendar) Calendar {
+	result := Calendar{}
+	for _, c := range cs {
+		result.events = append(result.events, c.events...)
+	}
+	return result
+}
+
+// IsEmpty checks whether the calendar contains any events.
+func (c Calendar) IsEmpty() bool {
+	return len(c.events) == 0
+}
+
+// AbsencesAt returns all absences at the given date.
+func (c Calendar) AbsencesAt(d klog.Date) []Absence {
+	var result []Absence
+	day := toGoDate(d)
+	for _, e := range c.events {
+		absence, occurs := e.occurrenceAt(day)
+		if occurs {
+			result = append(result, absence)
+		}
+	}
+	return result
+}
+
+// Label returns the summaries of all absences at the given date, or an empty
+// string if there are none.
+func (c Calendar) Label(d klog.Date) string {
+	var summaries []string
+	for _, a := range c.AbsencesAt(d) {
+		summaries = append(summaries, a.Summary)
+	}
+	return strings.Join(summaries, ", ")
+}
+
+// Apply reduces the should-totals of the records that are dated at an absence.
+// For whole-day absences, the should-total becomes zero. Otherwise, it is
+// reduced by the duration of the absence, but it never becomes negative.
+func (c Calendar) Apply(rs ...klog.Record) {
+	for _, r := range rs {
+		if r.ShouldTotal().InMinutes() == 0 {
+			continue
+		}
+		should := klog.Duration(r.ShouldTotal())
+		for _, a := range c.AbsencesAt(r.Date()) {
+			if a.Duration == nil {
+				should = klog.NewDuration(0, 0)
+				break
+			}
+			should = should.Minus(a.Duration)
+		}
+		if should.InMinutes() < 0 {
+			should = klog.NewDuration(0, 0)
+		}
+		r.SetShouldTotal(should)
+	}
+}
+
+func toGoDate(d klog.Date) gotime.Time {
+	return gotime.Date(d.Year(), gotime.Month(d.Month()), d.Day(), 0, 0, 0, 0, gotime.UTC)
+}
+
+func klogDuration(d gotime.Duration) klog.Duration {
+	return klog.NewDuration(0, int(d.Minutes()))
+}
@@ -0,0 +1,154 @@
+package calendar
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const sampleCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Holidays//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:1\r\n" +
+	"DTSTART;VALUE=DATE:20201225\r\n" +
+	"DTEND;VALUE=DATE:20201227\r\n" +
+	"SUMMARY:Christmas\r\n" +
+	"RRULE:FREQ=YEARLY\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:2\r\n" +
+	"DTSTART;VALUE=DATE:20210104\r\n" +
+	"DTEND;VALUE=DATE:20210109\r\n" +
+	"SUMMARY:Vacation\\, skiing\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:3\r\n" +
+	"DTSTART:20210112T130000\r\n" +
+	"DURATION:PT4H30M\r\n" +
+	"SUMMARY:Doctor’s\r\n" +
+	"  appointment\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:4\r\n" +
+	"DTSTART;VALUE=DATE:20210115\r\n" +
+	"SUMMARY:Cancelled\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParsesCalendar(t *testing.T) {
+	c, err := Parse(sampleCalendar)
+	require.Nil(t, err)
+
+	for _, x := range []struct {
+		date     klog.Date
+		expected []Absence
+	}{
+		{klog.Ɀ_Date_(2020, 12, 24), nil},
+		{klog.Ɀ_Date_(2020, 12, 25), []Absence{{Summary: "Christmas"}}},
+		{klog.Ɀ_Date_(2020, 12, 26), []Absence{{Summary: "Christmas"}}},
+		{klog.Ɀ_Date_(2020, 12, 27), nil},
+		{klog.Ɀ_Date_(2023, 12, 25), []Absence{{Summary: "Christmas"}}},
+		{klog.Ɀ_Date_(2019, 12, 25), nil},
+		{klog.Ɀ_Date_(2021, 1, 3), nil},
+		{klog.Ɀ_Date_(2021, 1, 4), []Absence{{Summary: "Vacation, skiing"}}},
+		{klog.Ɀ_Date_(2021, 1, 8), []Absence{{Summary: "Vacation, skiing"}}},
+		{klog.Ɀ_Date_(2021, 1, 9), nil},
+		{klog.Ɀ_Date_(2021, 1, 12), []Absence{{Summary: "Doctor’s appointment", Duration: klog.NewDuration(4, 30)}}},
+		{klog.Ɀ_Date_(2022, 1, 12), nil},
+		{klog.Ɀ_Date_(2021, 1, 15), nil},
+	} {
+		assert.Equal(t, x.expected, c.AbsencesAt(x.date), x.date.ToString())
+	}
+}
+
+func TestParsesRecurrenceRules(t *testing.T) {
+	c, err := Parse("BEGIN:VCALENDAR\n" +
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20200101\nSUMMARY:A\nRRULE:FREQ=YEARLY;COUNT=2\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20200102\nSUMMARY:B\nRRULE:FREQ=YEARLY;INTERVAL=2;UNTIL=20240102\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20200103\nSUMMARY:C\nRRULE:FREQ=WEEKLY\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20201231\nDTEND;VALUE=DATE:20210102\nSUMMARY:D\nRRULE:FREQ=YEARLY\nEND:VEVENT\n" +
+		"END:VCALENDAR\n")
+	require.Nil(t, err)
+
+	assert.Equal(t, "A", c.Label(klog.Ɀ_Date_(2020, 1, 1)))
+	assert.Equal(t, "A, D", c.Label(klog.Ɀ_Date_(2021, 1, 1)))
+	assert.Equal(t, "D", c.Label(klog.Ɀ_Date_(2022, 1, 1)))
+
+	assert.Equal(t, "B", c.Label(klog.Ɀ_Date_(2020, 1, 2)))
+	assert.Equal(t, "", c.Label(klog.Ɀ_Date_(2021, 1, 2)))
+	assert.Equal(t, "B", c.Label(klog.Ɀ_Date_(2022, 1, 2)))
+	assert.Equal(t, "B", c.Label(klog.Ɀ_Date_(2024, 1, 2)))
+	assert.Equal(t, "", c.Label(klog.Ɀ_Date_(2026, 1, 2)))
+
+	assert.Equal(t, "C", c.Label(klog.Ɀ_Date_(2020, 1, 3)))
+	assert.Equal(t, "", c.Label(klog.Ɀ_Date_(2020, 1, 10)))
+
+	assert.Equal(t, "", c.Label(klog.Ɀ_Date_(2019, 12, 31)))
+	assert.Equal(t, "D", c.Label(klog.Ɀ_Date_(2021, 12, 31)))
+	assert.Equal(t, "", c.Label(klog.Ɀ_Date_(2021, 1, 2)))
+}
+
+func TestParsesYearlyRecurrenceRulesWithRedundantParts(t *testing.T) {
+	c, err := Parse("BEGIN:VCALENDAR\n" +
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20201225\nSUMMARY:A\nRRULE:FREQ=YEARLY;BYMONTH=12;BYMONTHDAY=25\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nDTSTART:20200501T080000\nDURATION:PT2H\nSUMMARY:B\nRRULE:FREQ=YEARLY;WKST=MO;BYMONTH=5;BYHOUR=8;BYMINUTE=0\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20200601\nSUMMARY:C\nRRULE:FREQ=YEARLY;BYMONTH=7\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20201126\nSUMMARY:D\nRRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=4TH\nEND:VEVENT\n" +
+		"END:VCALENDAR\n")
+	require.Nil(t, err)
+
+	assert.Equal(t, "A", c.Label(klog.Ɀ_Date_(2020, 12, 25)))
+	assert.Equal(t, "A", c.Label(klog.Ɀ_Date_(2023, 12, 25)))
+
+	assert.Equal(t, "B", c.Label(klog.Ɀ_Date_(2020, 5, 1)))
+	assert.Equal(t, "B", c.Label(klog.Ɀ_Date_(2021, 5, 1)))
+
+	// Rules that would yield other dates are disregarded.
+	assert.Equal(t, "C", c.Label(klog.Ɀ_Date_(2020, 6, 1)))
+	assert.Equal(t, "", c.Label(klog.Ɀ_Date_(2021, 6, 1)))
+	assert.Equal(t, "", c.Label(klog.Ɀ_Date_(2021, 7, 1)))
+	assert.Equal(t, "D", c.Label(klog.Ɀ_Date_(2020, 11, 26)))
+	assert.Equal(t, "", c.Label(klog.Ɀ_Date_(2021, 11, 26)))
+}
+
+func TestRejectsMalformedCalendars(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"BEGIN:VEVENT\nEND:VEVENT\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:No start\nEND:VEVENT\nEND:VCALENDAR",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:2020\nEND:VEVENT\nEND:VCALENDAR",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20200101T100000\nDURATION:4H\nEND:VEVENT\nEND:VCALENDAR",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20200101\nRRULE:FREQ=YEARLY;COUNT=x\nEND:VEVENT\nEND:VCALENDAR",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20200101\nEND:VCALENDAR",
+		"BEGIN:VCALENDAR\nEND:VEVENT\nEND:VCALENDAR",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20200101\nfoo\nEND:VEVENT\nEND:VCALENDAR",
+	} {
+		_, err := Parse(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestAppliesAbsencesToShouldTotals(t *testing.T) {
+	c, _ := Parse(sampleCalendar)
+
+	holiday := klog.NewRecord(klog.Ɀ_Date_(2020, 12, 25))
+	holiday.SetShouldTotal(klog.NewDuration(8, 0))
+	partial := klog.NewRecord(klog.Ɀ_Date_(2021, 1, 12))
+	partial.SetShouldTotal(klog.NewDuration(8, 0))
+	shortDay := klog.NewRecord(klog.Ɀ_Date_(2021, 1, 12))
+	shortDay.SetShouldTotal(klog.NewDuration(3, 0))
+	regular := klog.NewRecord(klog.Ɀ_Date_(2021, 1, 13))
+	regular.SetShouldTotal(klog.NewDuration(8, 0))
+	noShould := klog.NewRecord(klog.Ɀ_Date_(2020, 12, 25))
+
+	c.Apply(holiday, partial, shortDay, regular, noShould)
+
+	assert.Equal(t, 0, holiday.ShouldTotal().InMinutes())
+	assert.Equal(t, klog.NewDuration(3, 30).InMinutes(), partial.ShouldTotal().InMinutes())
+	assert.Equal(t, 0, shortDay.ShouldTotal().InMinutes())
+	assert.Equal(t, klog.NewDuration(8, 0).InMinutes(), regular.ShouldTotal().InMinutes())
+	assert.False(t, noShould.HasShouldTotal())
+}
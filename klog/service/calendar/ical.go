@@ -0,0 +1,313 @@
+package calendar
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	gotime "time"
+)
+
+// event is a calendar event, as parsed from an iCalendar file.
+type event struct {
+	summary string
+
+	// start and end (exclusive) are wall-clock times. For all-day events,
+	// they are at midnight.
+	start    gotime.Time
+	end      gotime.Time
+	isAllDay bool
+
+	// recurrence is `nil` for non-recurring events.
+	recurrence *yearlyRecurrence
+}
+
+// yearlyRecurrence is the only kind of recurrence rule that is supported,
+// which is sufficient for most public holidays.
+type yearlyRecurrence struct {
+	interval int
+	count    int // `0` means unlimited.
+	until    *gotime.Time
+}
+
+func (e event) occurrenceAt(day gotime.Time) (Absence, bool) {
+	for _, shift := range e.yearShiftsFor(day) {
+		start := e.start.AddDate(shift, 0, 0)
+		end := e.end.AddDate(shift, 0, 0)
+		dayEnd := day.AddDate(0, 0, 1)
+		if !start.Before(dayEnd) || !end.After(day) {
+			continue
+		}
+		if e.isAllDay {
+			return Absence{Summary: e.summary}, true
+		}
+		overlapStart := start
+		if day.After(overlapStart) {
+			overlapStart = day
+		}
+		overlapEnd := end
+		if dayEnd.Before(overlapEnd) {
+			overlapEnd = dayEnd
+		}
+		overlap := overlapEnd.Sub(overlapStart)
+		if overlap >= 24*gotime.Hour {
+			return Absence{Summary: e.summary}, true
+		}
+		return Absence{Summary: e.summary, Duration: klogDuration(overlap)}, true
+	}
+	return Absence{}, false
+}
+
+// yearShiftsFor returns the number of years by which the event needs to be
+// shifted, so that it potentially occurs at the given day.
+func (e event) yearShiftsFor(day gotime.Time) []int {
+	if e.recurrence == nil {
+		return []int{0}
+	}
+	var result []int
+	// Multi-day events might start in the previous year.
+	for _, shift := range []int{day.Year() - e.start.Year() - 1, day.Year() - e.start.Year()} {
+		if shift < 0 || shift%e.recurrence.interval != 0 {
+			continue
+		}
+		occurrence := shift / e.recurrence.interval
+		if e.recurrence.count > 0 && occurrence >= e.recurrence.count {
+			continue
+		}
+		if e.recurrence.until != nil && e.start.AddDate(shift, 0, 0).After(*e.recurrence.until) {
+			continue
+		}
+		result = append(result, shift)
+	}
+	return result
+}
+
+// Parse reads the events from the text of an iCalendar file.
+// It only processes `VEVENT` components, and it disregards time zones, i.e.
+// all times are interpreted as wall-clock times (except for UTC times, which
+// are converted to the local time). Recurrence rules are only supported for
+// yearly repetition; events with other rules only count for their first date.
+func Parse(text string) (Calendar, error) {
+	lines := unfold(text)
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return Calendar{}, errors.New("MALFORMED_CALENDAR")
+	}
+	calendar := Calendar{}
+	var properties map[string]property
+	for _, l := range lines {
+		if strings.EqualFold(l, "BEGIN:VEVENT") {
+			properties = make(map[string]property)
+			continue
+		}
+		if strings.EqualFold(l, "END:VEVENT") {
+			if properties == nil {
+				return Calendar{}, errors.New("MALFORMED_CALENDAR")
+			}
+			e, isCancelled, err := newEvent(properties)
+			if err != nil {
+				return Calendar{}, err
+			}
+			if !isCancelled {
+				calendar.events = append(calendar.events, e)
+			}
+			properties = nil
+			continue
+		}
+		if properties == nil {
+			// Everything outside of events is irrelevant.
+			continue
+		}
+		p, err := parseProperty(l)
+		if err != nil {
+			return Calendar{}, err
+		}
+		if _, exists := properties[p.name]; !exists {
+			properties[p.name] = p
+		}
+	}
+	if properties != nil {
+		return Calendar{}, errors.New("MALFORMED_CALENDAR")
+	}
+	return calendar, nil
+}
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// unfold splits the text into lines and joins continuation lines,
+// which start with a space or tab character.
+func unfold(text string) []string {
+	var result []string
+	for _, l := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) && len(result) > 0 {
+			result[len(result)-1] += l[1:]
+			continue
+		}
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		result = append(result, l)
+	}
+	return result
+}
+
+func parseProperty(line string) (property, error) {
+	nameAndParams, value, hasColon := strings.Cut(line, ":")
+	if !hasColon {
+		return property{}, errors.New("MALFORMED_CALENDAR")
+	}
+	parts := strings.Split(nameAndParams, ";")
+	p := property{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string),
+		value:  value,
+	}
+	for _, param := range parts[1:] {
+		k, v, _ := strings.Cut(param, "=")
+		p.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return p, nil
+}
+
+func newEvent(properties map[string]property) (event, bool, error) {
+	if strings.EqualFold(properties["STATUS"].value, "CANCELLED") {
+		return event{}, true, nil
+	}
+	dtStart, hasStart := properties["DTSTART"]
+	if !hasStart {
+		return event{}, false, errors.New("MALFORMED_CALENDAR_EVENT")
+	}
+	start, isAllDay, sErr := parseDateOrDateTime(dtStart)
+	if sErr != nil {
+		return event{}, false, sErr
+	}
+	e := event{
+		summary:  unescapeText(properties["SUMMARY"].value),
+		start:    start,
+		isAllDay: isAllDay,
+	}
+	if dtEnd, hasEnd := properties["DTEND"]; hasEnd {
+		end, _, eErr := parseDateOrDateTime(dtEnd)
+		if eErr != nil {
+			return event{}, false, eErr
+		}
+		e.end = end
+	} else if duration, hasDuration := properties["DURATION"]; hasDuration {
+		end, dErr := addIcsDuration(start, duration.value)
+		if dErr != nil {
+			return event{}, false, dErr
+		}
+		e.end = end
+	} else if isAllDay {
+		e.end = start.AddDate(0, 0, 1)
+	} else {
+		e.end = start
+	}
+	if rule, hasRule := properties["RRULE"]; hasRule {
+		recurrence, rErr := parseRecurrence(rule.value, start)
+		if rErr != nil {
+			return event{}, false, rErr
+		}
+		e.recurrence = recurrence
+	}
+	return e, false, nil
+}
+
+func parseDateOrDateTime(p property) (gotime.Time, bool, error) {
+	if strings.EqualFold(p.params["VALUE"], "DATE") || len(p.value) == 8 {
+		t, err := gotime.Parse("20060102", p.value)
+		if err != nil {
+			return gotime.Time{}, false, errors.New("MALFORMED_CALENDAR_DATE")
+		}
+		return t, true, nil
+	}
+	isUtc := strings.HasSuffix(p.value, "Z")
+	t, err := gotime.Parse("20060102T150405", strings.TrimSuffix(p.value, "Z"))
+	if err != nil {
+		return gotime.Time{}, false, errors.New("MALFORMED_CALENDAR_DATE")
+	}
+	if isUtc {
+		local := t.In(gotime.Local)
+		t = gotime.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), 0, gotime.UTC)
+	}
+	return t, false, nil
+}
+
+var icsDurationPattern = regexp.MustCompile(`^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+func addIcsDuration(t gotime.Time, value string) (gotime.Time, error) {
+	match := icsDurationPattern.FindStringSubmatch(value)
+	if match == nil || value == "P" || value == "PT" {
+		return gotime.Time{}, errors.New("MALFORMED_CALENDAR_DURATION")
+	}
+	n := func(i int) int {
+		x, _ := strconv.Atoi(match[i])
+		return x
+	}
+	t = t.AddDate(0, 0, 7*n(1)+n(2))
+	return t.Add(gotime.Duration(n(3))*gotime.Hour + gotime.Duration(n(4))*gotime.Minute + gotime.Duration(n(5))*gotime.Second), nil
+}
+
+func parseRecurrence(rule string, start gotime.Time) (*yearlyRecurrence, error) {
+	recurrence := &yearlyRecurrence{interval: 1}
+	isAt := func(value string, expected int) bool {
+		x, err := strconv.Atoi(value)
+		return err == nil && x == expected
+	}
+	for _, part := range strings.Split(rule, ";") {
+		k, v, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(k) {
+		case "WKST":
+			// The start of the week only matters in combination with `BYWEEKNO`.
+			continue
+		case "BYMONTH", "BYMONTHDAY", "BYHOUR", "BYMINUTE", "BYSECOND":
+			// Calendar apps tend to spell out the date of DTSTART, which is
+			// redundant for yearly rules. Other values would yield different dates.
+			expected := map[string]int{
+				"BYMONTH":    int(start.Month()),
+				"BYMONTHDAY": start.Day(),
+				"BYHOUR":     start.Hour(),
+				"BYMINUTE":   start.Minute(),
+				"BYSECOND":   start.Second(),
+			}[strings.ToUpper(k)]
+			if !isAt(v, expected) {
+				return nil, nil
+			}
+		case "FREQ":
+			if !strings.EqualFold(v, "YEARLY") {
+				return nil, nil
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(v)
+			if err != nil || interval < 1 {
+				return nil, errors.New("MALFORMED_CALENDAR_RULE")
+			}
+			recurrence.interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(v)
+			if err != nil || count < 1 {
+				return nil, errors.New("MALFORMED_CALENDAR_RULE")
+			}
+			recurrence.count = count
+		case "UNTIL":
+			until, _, err := parseDateOrDateTime(property{value: v, params: map[string]string{}})
+			if err != nil {
+				return nil, err
+			}
+			recurrence.until = &until
+		default:
+			// Rules such as `BYDAY` would yield different dates every year.
+			return nil, nil
+		}
+	}
+	return recurrence, nil
+}
+
+var textEscapes = strings.NewReplacer(`\\`, `\`, `\,`, `,`, `\;`, `;`, `\n`, " ", `\N`, " ")
+
+func unescapeText(text string) string {
+	return strings.TrimSpace(textEscapes.Replace(text))
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"strings"
+)
+
+// The IDs of the checks, as they are used in warnings and in the configuration.
+const (
+	RULE_UNCLOSED_OPEN_RANGE = "unclosed-open-range"
+	RULE_FUTURE_ENTRY        = "future-entry"
+	RULE_OVERLAPPING_RANGES  = "overlapping-ranges"
+	RULE_MORE_THAN_24H       = "more-than-24h"
+	RULE_UNKNOWN_TAG         = "unknown-tag"
+	RULE_MAX_DAILY_WORK      = "max-daily-work"
+	RULE_REQUIRED_BREAKS     = "required-breaks"
+	RULE_MIN_REST            = "min-rest"
+	RULE_MAX_WEEKLY_WORK     = "max-weekly-work"
+
+	// The following checks are about multiple records, possibly across files.
+	RULE_DUPLICATE_RECORD       = "duplicate-record"
+	RULE_MISSING_WORKDAY        = "missing-workday"
+	RULE_OVERLAP_AT_MIDNIGHT    = "overlap-at-midnight"
+	RULE_CONCURRENT_OPEN_RANGES = "concurrent-open-ranges"
+)
+
+// Severity specifies how a rule is treated.
+type Severity int
+
+const (
+	SEVERITY_OFF Severity = iota
+	SEVERITY_WARNING
+	SEVERITY_ERROR
+)
+
+var severityNames = []string{"off", "warning", "error"}
+
+// ToString returns the name of the severity, e.g. `warning`.
+func (s Severity) ToString() string {
+	return severityNames[s]
+}
+
+// defaultSeverities contains all rules, along with the severity that applies
+// if there is no other configuration for them.
+var defaultSeverities = map[string]Severity{
+	RULE_UNCLOSED_OPEN_RANGE: SEVERITY_WARNING,
+	RULE_FUTURE_ENTRY:        SEVERITY_WARNING,
+	RULE_OVERLAPPING_RANGES:  SEVERITY_WARNING,
+	RULE_MORE_THAN_24H:       SEVERITY_WARNING,
+	RULE_UNKNOWN_TAG:         SEVERITY_WARNING,
+	RULE_MAX_DAILY_WORK:      SEVERITY_WARNING,
+	RULE_REQUIRED_BREAKS:     SEVERITY_WARNING,
+	RULE_MIN_REST:            SEVERITY_WARNING,
+	RULE_MAX_WEEKLY_WORK:     SEVERITY_WARNING,
+
+	RULE_DUPLICATE_RECORD:       SEVERITY_WARNING,
+	RULE_MISSING_WORKDAY:        SEVERITY_OFF,
+	RULE_OVERLAP_AT_MIDNIGHT:    SEVERITY_WARNING,
+	RULE_CONCURRENT_OPEN_RANGES: SEVERITY_WARNING,
+}
+
+// CheckRules specifies the severities of the rules that deviate from the default.
+type CheckRules struct {
+	severities map[string]Severity
+
+	// order is the order in which the rules were specified.
+	order []string
+}
+
+// NewCheckRulesFromString parses a comma-separated list of rules and their
+// respective severities. Example: `unknown-tag: error, future-entry: off`.
+func NewCheckRulesFromString(value string) (CheckRules, error) {
+	rules := CheckRules{severities: make(map[string]Severity)}
+	if strings.TrimSpace(value) == "" {
+		return rules, errors.New("EMPTY_RULES")
+	}
+	for _, item := range strings.Split(value, ",") {
+		parts := strings.Split(item, ":")
+		if len(parts) != 2 {
+			return rules, errors.New("MALFORMED_RULES")
+		}
+		rule := strings.ToLower(strings.TrimSpace(parts[0]))
+		if _, isKnown := defaultSeverities[rule]; !isKnown {
+			return rules, errors.New("UNKNOWN_RULE")
+		}
+		if _, isDuplicate := rules.severities[rule]; isDuplicate {
+			return rules, errors.New("DUPLICATE_RULE")
+		}
+		severity := severityIndex(strings.TrimSpace(parts[1]))
+		if severity == -1 {
+			return rules, errors.New("INVALID_SEVERITY")
+		}
+		rules.severities[rule] = Severity(severity)
+		rules.order = append(rules.order, rule)
+	}
+	return rules, nil
+}
+
+// Severity returns the severity of a rule.
+func (c CheckRules) Severity(rule string) Severity {
+	if s, ok := c.severities[rule]; ok {
+		return s
+	}
+	return defaultSeverities[rule]
+}
+
+// ToString serialises the rules, in the same format as they were specified.
+func (c CheckRules) ToString() string {
+	var items []string
+	for _, rule := range c.order {
+		items = append(items, rule+": "+c.severities[rule].ToString())
+	}
+	return strings.Join(items, ", ")
+}
+
+func severityIndex(name string) int {
+	for i, n := range severityNames {
+		if strings.ToLower(name) == n {
+			return i
+		}
+	}
+	return -1
+}
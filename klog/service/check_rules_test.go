@@ -0,0 +1,38 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParsesCheckRules(t *testing.T) {
+	rules, err := NewCheckRulesFromString("unknown-tag: error, Future-Entry: OFF")
+	require.Nil(t, err)
+	assert.Equal(t, SEVERITY_ERROR, rules.Severity(RULE_UNKNOWN_TAG))
+	assert.Equal(t, SEVERITY_OFF, rules.Severity(RULE_FUTURE_ENTRY))
+	assert.Equal(t, SEVERITY_WARNING, rules.Severity(RULE_OVERLAPPING_RANGES))
+	assert.Equal(t, "unknown-tag: error, future-entry: off", rules.ToString())
+}
+
+func TestEmptyCheckRulesApplyDefaults(t *testing.T) {
+	rules := CheckRules{}
+	for rule := range defaultSeverities {
+		assert.Equal(t, defaultSeverities[rule], rules.Severity(rule))
+	}
+}
+
+func TestRejectsInvalidCheckRules(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"unknown-tag",
+		"unknown-tag error",
+		"foo: error",
+		"unknown-tag: fatal",
+		"unknown-tag: error, unknown-tag: off",
+		"unknown-tag: error,",
+	} {
+		_, err := NewCheckRulesFromString(text)
+		require.Error(t, err, text)
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"github.com/jotaen/klog/klog"
+)
+
+// CheckAcrossFiles checks the records of one or more files for potential issues
+// that involve multiple records, possibly across files. For every issue
+// encountered, it invokes the `onWarn` callback. As opposed to CheckForWarnings,
+// these checks make assumptions on how the records are organised: namely, that
+// every file is about the same person, and that there is a record for every
+// workday, as determined by `isWorkday`.
+func CheckAcrossFiles(onWarn func(Warning), isWorkday func(klog.Date) bool, files [][]klog.Record) {
+	checkers := []crossFileChecker{
+		&duplicateRecordsChecker{},
+		&missingWorkdaysChecker{isWorkday: isWorkday},
+		&overlapAtMidnightChecker{},
+		&concurrentOpenRangesChecker{},
+	}
+	for _, c := range checkers {
+		c.Warn(files, func(d klog.Date, r klog.Record) {
+			onWarn(Warning{
+				date:   d,
+				record: r,
+				origin: c,
+			})
+		})
+	}
+}
+
+type crossFileChecker interface {
+	rule
+
+	// Warn invokes the callback for every issue, with the date and the record
+	// that the issue refers to.
+	Warn([][]klog.Record, func(klog.Date, klog.Record))
+}
+
+// dateKey returns a key for a date, for grouping records by date.
+func dateKey(d klog.Date) string {
+	return d.ToStringWithFormat(klog.DefaultDateFormat())
+}
+
+type duplicateRecordsChecker struct{}
+
+// Warn returns warnings for records at a date for which there is a record in
+// a preceding file already.
+func (c *duplicateRecordsChecker) Warn(files [][]klog.Record, onWarn func(klog.Date, klog.Record)) {
+	firstFileOfDate := make(map[string]int)
+	for i, rs := range files {
+		for _, r := range rs {
+			firstFile, exists := firstFileOfDate[dateKey(r.Date())]
+			if !exists {
+				firstFileOfDate[dateKey(r.Date())] = i
+				continue
+			}
+			if firstFile != i {
+				onWarn(r.Date(), r)
+			}
+		}
+	}
+}
+
+func (c *duplicateRecordsChecker) Message() string {
+	return "Record at the same date in another file"
+}
+
+func (c *duplicateRecordsChecker) Rule() string {
+	return RULE_DUPLICATE_RECORD
+}
+
+type missingWorkdaysChecker struct {
+	isWorkday func(klog.Date) bool
+}
+
+// Warn returns warnings for all workdays without record, between the
+// first and the last record.
+func (c *missingWorkdaysChecker) Warn(files [][]klog.Record, onWarn func(klog.Date, klog.Record)) {
+	var all []klog.Record
+	for _, rs := range files {
+		all = append(all, rs...)
+	}
+	if len(all) == 0 {
+		return
+	}
+	sorted := Sort(all, true)
+	existing := make(map[string]bool)
+	for _, r := range sorted {
+		existing[dateKey(r.Date())] = true
+	}
+	last := sorted[len(sorted)-1].Date()
+	for d := sorted[0].Date(); last.IsAfterOrEqual(d); d = d.PlusDays(1) {
+		if !existing[dateKey(d)] && c.isWorkday(d) {
+			onWarn(d, nil)
+		}
+	}
+}
+
+func (c *missingWorkdaysChecker) Message() string {
+	return "No record at a workday"
+}
+
+func (c *missingWorkdaysChecker) Rule() string {
+	return RULE_MISSING_WORKDAY
+}
+
+type overlapAtMidnightChecker struct{}
+
+// Warn returns warnings if the time ranges of a record overlap with the ones
+// of the record at the previous day, e.g. `22:00 - 1:00>` and `0:30 - 8:00`
+// on the next day.
+func (c *overlapAtMidnightChecker) Warn(files [][]klog.Record, onWarn func(klog.Date, klog.Record)) {
+	latestEndByDate := make(map[string]int)
+	var all []klog.Record
+	for _, rs := range files {
+		for _, r := range rs {
+			all = append(all, r)
+			for _, tr := range closedRanges(r) {
+				end := tr.End().MidnightOffset().InMinutes()
+				if previous, ok := latestEndByDate[dateKey(r.Date())]; !ok || end > previous {
+					latestEndByDate[dateKey(r.Date())] = end
+				}
+			}
+		}
+	}
+	for _, r := range Sort(all, true) {
+		previousEnd, ok := latestEndByDate[dateKey(r.Date().PlusDays(-1))]
+		if !ok {
+			continue
+		}
+		ranges := closedRanges(r)
+		if len(ranges) == 0 {
+			continue
+		}
+		if ranges[0].Start().MidnightOffset().InMinutes()+24*60 < previousEnd {
+			onWarn(r.Date(), r)
+		}
+	}
+}
+
+func (c *overlapAtMidnightChecker) Message() string {
+	return "Time ranges overlap with the previous day"
+}
+
+func (c *overlapAtMidnightChecker) Rule() string {
+	return RULE_OVERLAP_AT_MIDNIGHT
+}
+
+type concurrentOpenRangesChecker struct{}
+
+// Warn returns warnings for all open ranges, if there are open ranges in
+// more than one file.
+func (c *concurrentOpenRangesChecker) Warn(files [][]klog.Record, onWarn func(klog.Date, klog.Record)) {
+	var openRecords []klog.Record
+	filesWithOpenRange := 0
+	for _, rs := range files {
+		hasOpenRange := false
+		for _, r := range rs {
+			if r.OpenRange() != nil {
+				openRecords = append(openRecords, r)
+				hasOpenRange = true
+			}
+		}
+		if hasOpenRange {
+			filesWithOpenRange++
+		}
+	}
+	if filesWithOpenRange < 2 {
+		return
+	}
+	for _, r := range openRecords {
+		onWarn(r.Date(), r)
+	}
+}
+
+func (c *concurrentOpenRangesChecker) Message() string {
+	return "Open range in more than one file"
+}
+
+func (c *concurrentOpenRangesChecker) Rule() string {
+	return RULE_CONCURRENT_OPEN_RANGES
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/parser"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func checkAcrossFiles(texts ...string) []Warning {
+	var files [][]klog.Record
+	for _, text := range texts {
+		rs, _, errs := parser.NewSerialParser().Parse(text)
+		if errs != nil {
+			panic("Invalid records")
+		}
+		files = append(files, rs)
+	}
+	var ws []Warning
+	CheckAcrossFiles(func(w Warning) {
+		ws = append(ws, w)
+	}, func(d klog.Date) bool {
+		return d.Weekday() <= 5
+	}, files)
+	return ws
+}
+
+func warningsOfRule(rule string, ws []Warning) []Warning {
+	var result []Warning
+	for _, w := range ws {
+		if w.Rule() == rule {
+			result = append(result, w)
+		}
+	}
+	return result
+}
+
+func TestNoWarningsAcrossFilesForConsistentRecords(t *testing.T) {
+	ws := checkAcrossFiles(`
+2020-01-02
+	8:00-16:00
+`, `
+2020-01-03
+	8:00-16:00
+
+2020-01-06
+	9:00-?
+`)
+	assert.Len(t, ws, 0)
+}
+
+func TestWarnForDuplicateRecordsInDifferentFiles(t *testing.T) {
+	ws := checkAcrossFiles(`
+2020-01-02
+	1h
+
+2020-01-02
+	2h
+`, `
+2020-01-03
+	1h
+
+2020-01-02
+	3h
+`, `
+2020-01-02
+	4h
+`)
+	ws = warningsOfRule(RULE_DUPLICATE_RECORD, ws)
+	assert.Len(t, ws, 2)
+	assert.Equal(t, klog.NewDuration(3, 0), ws[0].Record().Entries()[0].Duration())
+	assert.Equal(t, klog.NewDuration(4, 0), ws[1].Record().Entries()[0].Duration())
+}
+
+func TestWarnForMissingWorkdays(t *testing.T) {
+	// 2020-01-01 is a Wednesday.
+	ws := checkAcrossFiles(`
+2020-01-01
+	1h
+
+2020-01-10
+	1h
+`, `
+2020-01-03
+	1h
+`)
+	ws = warningsOfRule(RULE_MISSING_WORKDAY, ws)
+	assert.Len(t, ws, 5)
+	for i, d := range []klog.Date{
+		klog.Ɀ_Date_(2020, 1, 2),
+		klog.Ɀ_Date_(2020, 1, 6),
+		klog.Ɀ_Date_(2020, 1, 7),
+		klog.Ɀ_Date_(2020, 1, 8),
+		klog.Ɀ_Date_(2020, 1, 9),
+	} {
+		assert.True(t, d.IsEqualTo(ws[i].Date()), i)
+		assert.Nil(t, ws[i].Record())
+	}
+}
+
+func TestWarnForOverlapAtMidnight(t *testing.T) {
+	ws := checkAcrossFiles(`
+2020-01-01
+	22:00-1:00>
+
+2020-01-03
+	20:00-23:00
+`, `
+2020-01-02
+	0:30-8:00
+
+2020-01-04
+	<22:30-8:00
+`)
+	ws = warningsOfRule(RULE_OVERLAP_AT_MIDNIGHT, ws)
+	assert.Len(t, ws, 2)
+	assert.True(t, klog.Ɀ_Date_(2020, 1, 2).IsEqualTo(ws[0].Date()))
+	assert.True(t, klog.Ɀ_Date_(2020, 1, 4).IsEqualTo(ws[1].Date()))
+}
+
+func TestWarnForOpenRangesInMultipleFiles(t *testing.T) {
+	ws := checkAcrossFiles(`
+2020-01-02
+	8:00-?
+`, `
+2020-01-02
+	9:00-?
+`)
+	ws = warningsOfRule(RULE_CONCURRENT_OPEN_RANGES, ws)
+	assert.Len(t, ws, 2)
+}
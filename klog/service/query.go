@@ -2,15 +2,30 @@ package service
 
 import (
 	"github.com/jotaen/klog/klog"
+	"regexp"
 	gosort "sort"
+	"strings"
 )
 
 // FilterQry represents the filter clauses of a query.
 type FilterQry struct {
-	Tags          []klog.Tag
+	// Tags must all be present (i.e., they are AND-ed).
+	Tags []klog.Tag
+
+	// TagQuery is a boolean expression that must match, in addition to `Tags`.
+	TagQuery TagQuery
+
+	// SummaryPatterns must all match the summary text, either the one of the
+	// record or the one of the entry.
+	SummaryPatterns []*regexp.Regexp
+
 	BeforeOrEqual klog.Date
 	AfterOrEqual  klog.Date
 	AtDate        klog.Date
+
+	// TagRegistry is used for folding tag aliases, both in the records and
+	// in the tag clauses.
+	TagRegistry TagRegistry
 }
 
 // Filter returns all records the matches the query.
@@ -27,8 +42,16 @@ func Filter(rs []klog.Record, o FilterQry) []klog.Record {
 		if o.AfterOrEqual != nil && !r.Date().IsAfterOrEqual(o.AfterOrEqual) {
 			continue
 		}
-		if len(o.Tags) > 0 {
-			reducedR, hasMatched := reduceRecordToMatchingTags(o.Tags, r)
+		if len(o.Tags) > 0 || o.TagQuery != nil {
+			qry := o.TagRegistry.foldQuery(AllOfTagQueries(NewTagQueryFromTags(o.Tags...), o.TagQuery))
+			reducedR, hasMatched := reduceRecordToMatchingTags(qry, o.TagRegistry, r)
+			if !hasMatched {
+				continue
+			}
+			r = reducedR
+		}
+		if len(o.SummaryPatterns) > 0 {
+			reducedR, hasMatched := reduceRecordToMatchingSummaries(o.SummaryPatterns, r)
 			if !hasMatched {
 				continue
 			}
@@ -52,14 +75,54 @@ func Sort(rs []klog.Record, startWithOldest bool) []klog.Record {
 	return sorted
 }
 
-func reduceRecordToMatchingTags(queriedTags []klog.Tag, r klog.Record) (klog.Record, bool) {
-	if isSubsetOf(queriedTags, r.Summary().Tags()) {
+// reduceRecordToMatchingTags reduces the record to those entries that match the
+// query. The record is returned as is, if both its summary and all its entries
+// match. (With negations, the summary alone might match, but an entry not.)
+func reduceRecordToMatchingTags(qry TagQuery, registry TagRegistry, r klog.Record) (klog.Record, bool) {
+	if qry.Matches(registry.FoldAll(r.Summary().Tags())) && allEntriesMatch(qry, registry, r) {
+		return r, true
+	}
+	var matchingEntries []klog.Entry
+	for _, e := range r.Entries() {
+		allTags := registry.FoldAll(klog.Merge(r.Summary().Tags(), e.Summary().Tags()))
+		if qry.Matches(allTags) {
+			matchingEntries = append(matchingEntries, e)
+		}
+	}
+	if len(matchingEntries) == 0 {
+		return nil, false
+	}
+	r.SetEntries(matchingEntries)
+	return r, true
+}
+
+func allEntriesMatch(qry TagQuery, registry TagRegistry, r klog.Record) bool {
+	for _, e := range r.Entries() {
+		if !qry.Matches(registry.FoldAll(klog.Merge(r.Summary().Tags(), e.Summary().Tags()))) {
+			return false
+		}
+	}
+	return true
+}
+
+// reduceRecordToMatchingSummaries reduces the record to those entries whose summary
+// matches all patterns. A pattern that matches the record summary applies to all
+// entries of the record.
+func reduceRecordToMatchingSummaries(patterns []*regexp.Regexp, r klog.Record) (klog.Record, bool) {
+	recordSummary := strings.Join(r.Summary().Lines(), "\n")
+	var remainingPatterns []*regexp.Regexp
+	for _, p := range patterns {
+		if !p.MatchString(recordSummary) {
+			remainingPatterns = append(remainingPatterns, p)
+		}
+	}
+	if len(remainingPatterns) == 0 {
 		return r, true
 	}
 	var matchingEntries []klog.Entry
 	for _, e := range r.Entries() {
-		allTags := klog.Merge(r.Summary().Tags(), e.Summary().Tags())
-		if isSubsetOf(queriedTags, allTags) {
+		entrySummary := strings.Join(e.Summary().Lines(), "\n")
+		if matchesAll(remainingPatterns, entrySummary) {
 			matchingEntries = append(matchingEntries, e)
 		}
 	}
@@ -70,9 +133,9 @@ func reduceRecordToMatchingTags(queriedTags []klog.Tag, r klog.Record) (klog.Rec
 	return r, true
 }
 
-func isSubsetOf(queriedTags []klog.Tag, allTags klog.TagSet) bool {
-	for _, t := range queriedTags {
-		if !allTags.Contains(t) {
+func matchesAll(patterns []*regexp.Regexp, text string) bool {
+	for _, p := range patterns {
+		if !p.MatchString(text) {
 			return false
 		}
 	}
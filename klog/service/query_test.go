@@ -4,6 +4,7 @@ import (
 	"github.com/jotaen/klog/klog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"regexp"
 	"testing"
 )
 
@@ -127,3 +128,67 @@ func TestQueryWithSorting(t *testing.T) {
 		assert.Equal(t, []klog.Record{ss[4], ss[3], ss[2], ss[1], ss[0]}, descending)
 	}
 }
+
+func TestQueryWithTagQueryDisjunction(t *testing.T) {
+	q, _ := NewTagQueryFromString("#bar=1 or #bar=2")
+	rs := Filter(sampleRecordsForQuerying(), FilterQry{TagQuery: q})
+	require.Len(t, rs, 1)
+	assert.Equal(t, 3, rs[0].Date().Day())
+	assert.Equal(t, klog.NewDuration(8, 0), Total(rs...))
+}
+
+func TestQueryWithTagQueryNegationReducesEntries(t *testing.T) {
+	q, _ := NewTagQueryFromString("#foo and not #bar")
+	rs := Filter(sampleRecordsForQuerying(), FilterQry{TagQuery: q})
+	require.Len(t, rs, 3)
+	assert.Equal(t, 30, rs[0].Date().Day())
+	assert.Equal(t, 1, rs[1].Date().Day())
+	assert.Len(t, rs[1].Entries(), 2)
+	assert.Equal(t, 2, rs[2].Date().Day())
+	assert.Equal(t, klog.NewDuration(7, -30+15), Total(rs...))
+}
+
+func TestQueryWithTagQueryWildcard(t *testing.T) {
+	q, _ := NewTagQueryFromString("#foo=*")
+	rs := Filter(sampleRecordsForQuerying(), FilterQry{TagQuery: q})
+	// Only `#foo=a` has a value.
+	require.Len(t, rs, 1)
+	assert.Equal(t, 3, rs[0].Date().Day())
+}
+
+func TestQueryWithTagsAndTagQueryCombined(t *testing.T) {
+	q, _ := NewTagQueryFromString("not #bar=2")
+	rs := Filter(sampleRecordsForQuerying(), FilterQry{Tags: []klog.Tag{klog.NewTagOrPanic("bar", "")}, TagQuery: q})
+	require.Len(t, rs, 3)
+	assert.Equal(t, klog.NewDuration(5+6+4, 0), Total(rs...))
+}
+
+func TestQueryWithSummaryPatternOnEntries(t *testing.T) {
+	rs := Filter(sampleRecordsForQuerying(), FilterQry{SummaryPatterns: []*regexp.Regexp{regexp.MustCompile("test")}})
+	require.Len(t, rs, 1)
+	assert.Equal(t, 3, rs[0].Date().Day())
+	require.Len(t, rs[0].Entries(), 1)
+	assert.Equal(t, klog.NewDuration(4, 0), Total(rs...))
+}
+
+func TestQueryWithSummaryPatternOnOverallSummary(t *testing.T) {
+	rs := Filter(sampleRecordsForQuerying(), FilterQry{SummaryPatterns: []*regexp.Regexp{regexp.MustCompile("(?i)hello")}})
+	require.Len(t, rs, 1)
+	assert.Equal(t, 30, rs[0].Date().Day())
+}
+
+func TestQueryWithMultipleSummaryPatterns(t *testing.T) {
+	rs := Filter(sampleRecordsForQuerying(), FilterQry{SummaryPatterns: []*regexp.Regexp{
+		regexp.MustCompile(`#foo`),
+		regexp.MustCompile(`#bar(=\d)?`),
+	}})
+	require.Len(t, rs, 2)
+	assert.Equal(t, 1, rs[0].Date().Day())
+	assert.Equal(t, 3, rs[1].Date().Day())
+	assert.Equal(t, klog.NewDuration(6+4+4, 0), Total(rs...))
+}
+
+func TestQueryWithNonMatchingSummaryPattern(t *testing.T) {
+	rs := Filter(sampleRecordsForQuerying(), FilterQry{SummaryPatterns: []*regexp.Regexp{regexp.MustCompile("xyz")}})
+	require.Len(t, rs, 0)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"errors"
+	"github.com/jotaen/klog/klog"
+	"strings"
+)
+
+// ShouldTotalSchedule specifies the should-totals per day of the week. It is
+// used as fallback for records that don’t have a should-total of their own.
+type ShouldTotalSchedule struct {
+	// perWeekday is indexed by weekday, starting from Monday = 0.
+	// A `nil` value means that there is no should-total for that weekday.
+	perWeekday [7]klog.ShouldTotal
+}
+
+var weekdayNames = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// NewShouldTotalScheduleFromString parses a comma-separated list of weekdays or
+// weekday ranges and their respective should-totals.
+// Example: `mon-thu: 8h, fri: 6h, sat-sun: 0h`.
+// Weekdays that are not specified don’t have a should-total.
+func NewShouldTotalScheduleFromString(value string) (ShouldTotalSchedule, error) {
+	schedule := ShouldTotalSchedule{}
+	if strings.TrimSpace(value) == "" {
+		return schedule, errors.New("EMPTY_SCHEDULE")
+	}
+	for _, item := range strings.Split(value, ",") {
+		parts := strings.Split(item, ":")
+		if len(parts) != 2 {
+			return schedule, errors.New("MALFORMED_SCHEDULE")
+		}
+		from, to, rErr := parseWeekdayRange(strings.TrimSpace(parts[0]))
+		if rErr != nil {
+			return schedule, rErr
+		}
+		durationValue := strings.TrimSuffix(strings.TrimSpace(parts[1]), "!")
+		d, dErr := klog.NewDurationFromString(durationValue)
+		if dErr != nil {
+			return schedule, dErr
+		}
+		for i := from; i <= to; i++ {
+			if schedule.perWeekday[i] != nil {
+				return schedule, errors.New("DUPLICATE_WEEKDAY_IN_SCHEDULE")
+			}
+			schedule.perWeekday[i] = klog.NewShouldTotal(0, d.InMinutes())
+		}
+	}
+	return schedule, nil
+}
+
+func parseWeekdayRange(value string) (int, int, error) {
+	bounds := strings.Split(value, "-")
+	if len(bounds) > 2 {
+		return -1, -1, errors.New("MALFORMED_WEEKDAY_RANGE")
+	}
+	var indices []int
+	for _, b := range bounds {
+		i := weekdayIndex(strings.TrimSpace(b))
+		if i == -1 {
+			return -1, -1, errors.New("INVALID_WEEKDAY")
+		}
+		indices = append(indices, i)
+	}
+	from, to := indices[0], indices[len(indices)-1]
+	if from > to {
+		return -1, -1, errors.New("MALFORMED_WEEKDAY_RANGE")
+	}
+	return from, to, nil
+}
+
+func weekdayIndex(name string) int {
+	for i, n := range weekdayNames {
+		if strings.ToLower(name) == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// ShouldTotalAt returns the should-total for the weekday of the given date.
+// It returns `nil` if the schedule doesn’t specify one for that weekday.
+func (s ShouldTotalSchedule) ShouldTotalAt(d klog.Date) klog.ShouldTotal {
+	return s.perWeekday[d.Weekday()-1]
+}
+
+// Apply sets the should-total according to the schedule for all records
+// that don’t have a should-total of their own.
+func (s ShouldTotalSchedule) Apply(rs ...klog.Record) {
+	for _, r := range rs {
+		if r.HasShouldTotal() {
+			continue
+		}
+		should := s.ShouldTotalAt(r.Date())
+		if should == nil {
+			continue
+		}
+		r.SetShouldTotal(should)
+	}
+}
+
+// ToString serialises the schedule in the canonical form, where consecutive
+// weekdays with the same should-total are combined into ranges.
+func (s ShouldTotalSchedule) ToString() string {
+	var items []string
+	for i := 0; i < len(s.perWeekday); i++ {
+		if s.perWeekday[i] == nil {
+			continue
+		}
+		j := i
+		for j+1 < len(s.perWeekday) && s.perWeekday[j+1] != nil && s.perWeekday[j+1].InMinutes() == s.perWeekday[i].InMinutes() {
+			j++
+		}
+		days := weekdayNames[i]
+		if j > i {
+			days += "-" + weekdayNames[j]
+		}
+		items = append(items, days+": "+s.perWeekday[i].ToString())
+		i = j
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParsesShouldTotalSchedule(t *testing.T) {
+	for _, x := range []struct {
+		text   string
+		expect string
+	}{
+		{"mon: 8h", "mon: 8h!"},
+		{"Mon-Thu: 8h, Fri: 6h!", "mon-thu: 8h!, fri: 6h!"},
+		{"mon-fri:7h30m,sat-sun:0m", "mon-fri: 7h30m!, sat-sun: 0m!"},
+		{"sun: 1h, mon: 2h, tue: 2h", "mon-tue: 2h!, sun: 1h!"},
+		{"mon-mon: 4h, wed: 4h", "mon: 4h!, wed: 4h!"},
+	} {
+		schedule, err := NewShouldTotalScheduleFromString(x.text)
+		require.Nil(t, err, x.text)
+		assert.Equal(t, x.expect, schedule.ToString())
+	}
+}
+
+func TestRejectsInvalidShouldTotalSchedule(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"8h",
+		"mon 8h",
+		"mon: asdf",
+		"foo: 8h",
+		"fri-mon: 8h",
+		"mon-tue-wed: 8h",
+		"mon: 8h, mon: 6h",
+		"mon-fri: 8h, fri: 6h",
+		"mon: 8h,",
+	} {
+		_, err := NewShouldTotalScheduleFromString(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestAppliesShouldTotalScheduleToRecordsWithoutShouldTotal(t *testing.T) {
+	schedule, _ := NewShouldTotalScheduleFromString("mon-thu: 8h, fri: 6h")
+
+	monday := klog.NewRecord(klog.Ɀ_Date_(2023, 5, 1))
+	friday := klog.NewRecord(klog.Ɀ_Date_(2023, 5, 5))
+	saturday := klog.NewRecord(klog.Ɀ_Date_(2023, 5, 6))
+	explicitTuesday := klog.NewRecord(klog.Ɀ_Date_(2023, 5, 2))
+	explicitTuesday.SetShouldTotal(klog.NewDuration(0, 0))
+
+	schedule.Apply(monday, friday, saturday, explicitTuesday)
+
+	assert.Equal(t, klog.NewShouldTotal(8, 0), monday.ShouldTotal())
+	assert.Equal(t, klog.NewShouldTotal(6, 0), friday.ShouldTotal())
+	assert.False(t, saturday.HasShouldTotal())
+	assert.Equal(t, klog.NewShouldTotal(0, 0), explicitTuesday.ShouldTotal())
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"errors"
+	"github.com/jotaen/klog/klog"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// TagQuery is a boolean expression over tags, which can be evaluated against
+// the tags of an entry. Example: `(#clienta or #clientb) and not #meeting`.
+//
+// The grammar (in order of increasing precedence) is:
+// - `or` for disjunction
+// - `and` for conjunction
+// - `not` for negation
+// - `(...)` for grouping
+// - `#tag`, `#tag=value`, or `#tag=val*` (with `*` as wildcard in the value)
+// The keywords are case-insensitive. A tag without the leading `#` is allowed
+// as well, as long as it doesn’t clash with one of the keywords. A wildcard
+// only matches tags that have a value, i.e. `#tag=*` doesn’t match `#tag`.
+type TagQuery interface {
+	// Matches checks whether the given tags satisfy the query.
+	Matches(klog.TagSet) bool
+
+	// ToString returns the canonical textual representation of the query.
+	ToString() string
+}
+
+// NewTagQueryFromString parses a query expression. It returns an error if the
+// expression is malformed.
+func NewTagQueryFromString(expression string) (TagQuery, error) {
+	tokens, tErr := tokeniseTagQuery(expression)
+	if tErr != nil {
+		return nil, tErr
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("EMPTY_TAG_QUERY")
+	}
+	p := &tagQueryParser{tokens: tokens}
+	q, pErr := p.parseOr()
+	if pErr != nil {
+		return nil, pErr
+	}
+	if p.pos != len(p.tokens) {
+		return nil, errors.New("MALFORMED_TAG_QUERY")
+	}
+	return q, nil
+}
+
+// NewTagQueryFromTags creates a query that matches if all the given tags
+// are present. For an empty list, the query always matches.
+func NewTagQueryFromTags(ts ...klog.Tag) TagQuery {
+	var q TagQuery = &tagQueryAll{}
+	for i, t := range ts {
+		leaf := newTagQueryLeaf(t.Name(), t.Value())
+		if i == 0 {
+			q = leaf
+			continue
+		}
+		q = &tagQueryAnd{q, leaf}
+	}
+	return q
+}
+
+// AllOfTagQueries combines multiple queries into one, so that all of them
+// must match. Nil entries are ignored. If there are no queries at all, it
+// returns nil.
+func AllOfTagQueries(qs ...TagQuery) TagQuery {
+	var result TagQuery
+	for _, q := range qs {
+		if q == nil {
+			continue
+		}
+		if result == nil {
+			result = q
+			continue
+		}
+		result = &tagQueryAnd{result, q}
+	}
+	return result
+}
+
+type tagQueryAll struct{}
+
+func (q *tagQueryAll) Matches(_ klog.TagSet) bool { return true }
+func (q *tagQueryAll) ToString() string           { return "" }
+
+type tagQueryLeaf struct {
+	name  string
+	value string
+
+	// pattern is the compiled value, if the value contains a wildcard.
+	pattern *regexp.Regexp
+}
+
+func newTagQueryLeaf(name string, value string) *tagQueryLeaf {
+	leaf := &tagQueryLeaf{name: name, value: value}
+	if strings.Contains(value, "*") {
+		leaf.pattern = regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(value), `\*`, ".*") + "$")
+	}
+	return leaf
+}
+
+// Matches checks whether the tag is in the set. A wildcard value only matches
+// tags that have a value, so `#tag=*` doesn’t match `#tag` (without value).
+func (q *tagQueryLeaf) Matches(ts klog.TagSet) bool {
+	if q.pattern == nil {
+		return ts.Contains(klog.NewTagOrPanic(q.name, q.value))
+	}
+	for t := range ts {
+		if t.Name() == q.name && t.Value() != "" && q.pattern.MatchString(t.Value()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *tagQueryLeaf) ToString() string {
+	if q.value == "" {
+		return "#" + q.name
+	}
+	if tagQueryUnquotedValuePattern.MatchString(q.value) {
+		return "#" + q.name + "=" + q.value
+	}
+	quotation := `"`
+	if strings.Contains(q.value, `"`) {
+		quotation = `'`
+	}
+	return "#" + q.name + "=" + quotation + q.value + quotation
+}
+
+type tagQueryAnd struct {
+	left  TagQuery
+	right TagQuery
+}
+
+func (q *tagQueryAnd) Matches(ts klog.TagSet) bool {
+	return q.left.Matches(ts) && q.right.Matches(ts)
+}
+
+func (q *tagQueryAnd) ToString() string {
+	return bracketTagQuery(q.left, 2) + " and " + bracketTagQuery(q.right, 2)
+}
+
+type tagQueryOr struct {
+	left  TagQuery
+	right TagQuery
+}
+
+func (q *tagQueryOr) Matches(ts klog.TagSet) bool {
+	return q.left.Matches(ts) || q.right.Matches(ts)
+}
+
+func (q *tagQueryOr) ToString() string {
+	return bracketTagQuery(q.left, 1) + " or " + bracketTagQuery(q.right, 1)
+}
+
+type tagQueryNot struct {
+	operand TagQuery
+}
+
+func (q *tagQueryNot) Matches(ts klog.TagSet) bool {
+	return !q.operand.Matches(ts)
+}
+
+func (q *tagQueryNot) ToString() string {
+	return "not " + bracketTagQuery(q.operand, 3)
+}
+
+// bracketTagQuery wraps the query in parentheses if that is required in
+// order to preserve the operator precedence within the parent expression.
+func bracketTagQuery(q TagQuery, parentPrecedence int) string {
+	if tagQueryPrecedence(q) < parentPrecedence {
+		return "(" + q.ToString() + ")"
+	}
+	return q.ToString()
+}
+
+func tagQueryPrecedence(q TagQuery) int {
+	switch q.(type) {
+	case *tagQueryOr:
+		return 1
+	case *tagQueryAnd:
+		return 2
+	case *tagQueryNot:
+		return 3
+	}
+	return 4
+}
+
+var tagQueryUnquotedValuePattern = regexp.MustCompile(`^[\p{L}\d_*-]+$`)
+var tagQueryNamePattern = regexp.MustCompile(`^[\p{L}\d_-]+(/[\p{L}\d_-]+)*$`)
+
+type tagQueryTokenKind int
+
+const (
+	tokenTag tagQueryTokenKind = iota
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpenParen
+	tokenCloseParen
+)
+
+type tagQueryToken struct {
+	kind tagQueryTokenKind
+	leaf *tagQueryLeaf
+}
+
+func isTagQueryNameChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '/'
+}
+
+func tokeniseTagQuery(expression string) ([]tagQueryToken, error) {
+	var tokens []tagQueryToken
+	chars := []rune(expression)
+	for i := 0; i < len(chars); {
+		c := chars[i]
+		if unicode.IsSpace(c) {
+			i++
+			continue
+		}
+		if c == '(' {
+			tokens = append(tokens, tagQueryToken{kind: tokenOpenParen})
+			i++
+			continue
+		}
+		if c == ')' {
+			tokens = append(tokens, tagQueryToken{kind: tokenCloseParen})
+			i++
+			continue
+		}
+
+		// Read the tag name (or keyword).
+		hasHash := c == '#'
+		if hasHash {
+			i++
+		}
+		start := i
+		for i < len(chars) && isTagQueryNameChar(chars[i]) {
+			i++
+		}
+		name := string(chars[start:i])
+		if name == "" || !tagQueryNamePattern.MatchString(name) {
+			return nil, errors.New("MALFORMED_TAG_QUERY")
+		}
+		if !hasHash {
+			switch strings.ToLower(name) {
+			case "and":
+				tokens = append(tokens, tagQueryToken{kind: tokenAnd})
+				continue
+			case "or":
+				tokens = append(tokens, tagQueryToken{kind: tokenOr})
+				continue
+			case "not":
+				tokens = append(tokens, tagQueryToken{kind: tokenNot})
+				continue
+			}
+		}
+
+		// Read the tag value, if present.
+		value := ""
+		if i < len(chars) && chars[i] == '=' {
+			i++
+			if i < len(chars) && (chars[i] == '"' || chars[i] == '\'') {
+				quotation := chars[i]
+				i++
+				valueStart := i
+				for i < len(chars) && chars[i] != quotation {
+					i++
+				}
+				if i == len(chars) {
+					return nil, errors.New("MALFORMED_TAG_QUERY")
+				}
+				value = string(chars[valueStart:i])
+				i++ // Closing quotation mark
+			} else {
+				valueStart := i
+				for i < len(chars) && (isTagQueryNameChar(chars[i]) || chars[i] == '*') {
+					i++
+				}
+				value = string(chars[valueStart:i])
+			}
+			if value == "" {
+				// An empty value is most likely a mistake, as it would
+				// be indistinguishable from a tag without value.
+				return nil, errors.New("MALFORMED_TAG_QUERY")
+			}
+		}
+		tokens = append(tokens, tagQueryToken{
+			kind: tokenTag,
+			leaf: newTagQueryLeaf(strings.ToLower(name), value),
+		})
+	}
+	return tokens, nil
+}
+
+type tagQueryParser struct {
+	tokens []tagQueryToken
+	pos    int
+}
+
+func (p *tagQueryParser) peek() (tagQueryTokenKind, bool) {
+	if p.pos >= len(p.tokens) {
+		return 0, false
+	}
+	return p.tokens[p.pos].kind, true
+}
+
+func (p *tagQueryParser) parseOr() (TagQuery, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		kind, ok := p.peek()
+		if !ok || kind != tokenOr {
+			return left, nil
+		}
+		p.pos++
+		right, rErr := p.parseAnd()
+		if rErr != nil {
+			return nil, rErr
+		}
+		left = &tagQueryOr{left, right}
+	}
+}
+
+func (p *tagQueryParser) parseAnd() (TagQuery, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		kind, ok := p.peek()
+		if !ok || kind != tokenAnd {
+			return left, nil
+		}
+		p.pos++
+		right, rErr := p.parseNot()
+		if rErr != nil {
+			return nil, rErr
+		}
+		left = &tagQueryAnd{left, right}
+	}
+}
+
+func (p *tagQueryParser) parseNot() (TagQuery, error) {
+	kind, ok := p.peek()
+	if ok && kind == tokenNot {
+		p.pos++
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &tagQueryNot{operand}, nil
+	}
+	return p.parseOperand()
+}
+
+func (p *tagQueryParser) parseOperand() (TagQuery, error) {
+	kind, ok := p.peek()
+	if !ok {
+		return nil, errors.New("MALFORMED_TAG_QUERY")
+	}
+	switch kind {
+	case tokenTag:
+		leaf := p.tokens[p.pos].leaf
+		p.pos++
+		return leaf, nil
+	case tokenOpenParen:
+		p.pos++
+		q, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing, hasMore := p.peek()
+		if !hasMore || closing != tokenCloseParen {
+			return nil, errors.New("MALFORMED_TAG_QUERY")
+		}
+		p.pos++
+		return q, nil
+	}
+	return nil, errors.New("MALFORMED_TAG_QUERY")
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func tagSetOf(tags ...string) klog.TagSet {
+	ts := klog.NewEmptyTagSet()
+	for _, t := range tags {
+		tag, err := klog.NewTagFromString(t)
+		if err != nil {
+			panic(err)
+		}
+		ts.Put(tag)
+	}
+	return ts
+}
+
+func TestParsesValidTagQueries(t *testing.T) {
+	for _, x := range []struct {
+		text   string
+		expect string
+	}{
+		{`#foo`, `#foo`},
+		{`foo`, `#foo`},
+		{`#FOO`, `#foo`},
+		{`#foo=1`, `#foo=1`},
+		{`#foo="a b"`, `#foo="a b"`},
+		{`#foo='a"b'`, `#foo='a"b'`},
+		{`#foo=PROJ-*`, `#foo=PROJ-*`},
+		{`#foo and #bar`, `#foo and #bar`},
+		{`#foo AND #bar`, `#foo and #bar`},
+		{`#foo or #bar and #baz`, `#foo or #bar and #baz`},
+		{`(#foo or #bar) and #baz`, `(#foo or #bar) and #baz`},
+		{`not #foo`, `not #foo`},
+		{`not not #foo`, `not not #foo`},
+		{`not (#foo or #bar)`, `not (#foo or #bar)`},
+		{`#and or #not`, `#and or #not`},
+		{`  ( ( #foo ) )  `, `#foo`},
+	} {
+		q, err := NewTagQueryFromString(x.text)
+		require.Nil(t, err, x.text)
+		assert.Equal(t, x.expect, q.ToString())
+	}
+}
+
+func TestRejectsMalformedTagQueries(t *testing.T) {
+	for _, text := range []string{
+		``,
+		`   `,
+		`#`,
+		`and`,
+		`#foo and`,
+		`#foo or or #bar`,
+		`not`,
+		`(#foo`,
+		`#foo)`,
+		`()`,
+		`#foo #bar`,
+		`#foo="bar`,
+		`#foo=a=b`,
+		`#foo=`,
+		`#foo= and #bar`,
+		`#foo=""`,
+		`(#foo=)`,
+		`#foo&#bar`,
+	} {
+		q, err := NewTagQueryFromString(text)
+		require.Error(t, err, text)
+		assert.Nil(t, q)
+	}
+}
+
+func TestEvaluatesTagQueries(t *testing.T) {
+	ts := tagSetOf("#foo", "#bar=1", "#ticket=PROJ-123")
+	for _, x := range []struct {
+		text   string
+		expect bool
+	}{
+		{`#foo`, true},
+		{`#baz`, false},
+		{`#bar`, true},
+		{`#bar=1`, true},
+		{`#bar=2`, false},
+		{`#foo and #bar`, true},
+		{`#foo and #baz`, false},
+		{`#foo or #baz`, true},
+		{`#baz or #qux`, false},
+		{`not #foo`, false},
+		{`not #baz`, true},
+		{`#foo and not #baz`, true},
+		{`#baz or #foo and not #bar`, false},
+		{`(#baz or #foo) and not #qux`, true},
+		{`#ticket=PROJ-*`, true},
+		{`#ticket=*-123`, true},
+		{`#ticket=*`, true},
+		{`#ticket=OTHER-*`, false},
+		{`#ticket=proj-*`, false},
+		{`#bar=*`, true},
+		{`#baz=*`, false},
+		// A wildcard requires a value.
+		{`#foo=*`, false},
+	} {
+		q, err := NewTagQueryFromString(x.text)
+		require.Nil(t, err, x.text)
+		assert.Equal(t, x.expect, q.Matches(ts), x.text)
+	}
+}
+
+func TestCombinesTagQueries(t *testing.T) {
+	foo, _ := NewTagQueryFromString("#foo")
+	notBar, _ := NewTagQueryFromString("not #bar")
+	assert.Nil(t, AllOfTagQueries())
+	assert.Nil(t, AllOfTagQueries(nil, nil))
+	assert.Equal(t, "#foo", AllOfTagQueries(nil, foo).ToString())
+	assert.Equal(t, "#foo and not #bar", AllOfTagQueries(foo, nil, notBar).ToString())
+
+	fromTags := NewTagQueryFromTags(klog.NewTagOrPanic("foo", ""), klog.NewTagOrPanic("bar", "1"))
+	assert.Equal(t, "#foo and #bar=1", fromTags.ToString())
+	assert.True(t, NewTagQueryFromTags().Matches(tagSetOf()))
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"errors"
+	"github.com/jotaen/klog/klog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TagRegistry is a list of known tags, along with their aliases and metadata.
+// Aliases are folded into their canonical tag when evaluating records, so that
+// different spellings of a tag are treated as one. The zero value is an empty
+// registry.
+type TagRegistry struct {
+	tags    []TagInfo         // The canonical tags, in order of declaration.
+	aliases map[string]string // Alias name to canonical name.
+}
+
+// TagInfo is the metadata of a tag in the registry. All fields except for
+// the tag itself are optional.
+type TagInfo struct {
+	Tag klog.Tag
+
+	// Name is a human-readable description of the tag.
+	Name string
+
+	// Category is an arbitrary grouping, e.g. the client.
+	Category string
+
+	// Colour is a terminal colour code between 0 and 255.
+	Colour string
+
+	// IsBillable denotes whether the time is billable.
+	IsBillable bool
+}
+
+// NewEmptyTagRegistry creates a registry without any tags.
+func NewEmptyTagRegistry() TagRegistry {
+	return TagRegistry{nil, make(map[string]string)}
+}
+
+// NewTagRegistryFromString parses the contents of a tags file. Every line
+// contains a tag, optionally followed by a list of aliases for it. The
+// subsequent indented lines can specify metadata for the tag:
+//
+//	#meeting = #meetings #mtg
+//	#acme
+//	    name = ACME Corporation
+//	    category = Clients
+//	    colour = 214
+//	    billable = yes
+//
+// The leading `#` of the tags is optional, and so is the whitespace around
+// the `=`. Tags cannot have values. Blank
+// lines, and lines that start with `;` or `//`, are ignored.
+func NewTagRegistryFromString(text string) (TagRegistry, error) {
+	registry := NewEmptyTagRegistry()
+	declared := make(map[string]bool)
+	for i, l := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		isIndented := strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")
+		l = strings.TrimSpace(l)
+		if l == "" || strings.HasPrefix(l, ";") || strings.HasPrefix(l, "//") {
+			continue
+		}
+		lineNr := strconv.Itoa(i + 1)
+		if isIndented {
+			if len(registry.tags) == 0 {
+				return TagRegistry{}, errors.New("Property without tag in line " + lineNr)
+			}
+			pErr := setTagProperty(&registry.tags[len(registry.tags)-1], l)
+			if pErr != nil {
+				return TagRegistry{}, errors.New(pErr.Error() + " in line " + lineNr)
+			}
+			continue
+		}
+		tagText, aliasesText, hasAliases := strings.Cut(l, "=")
+		if hasAliases && strings.TrimSpace(aliasesText) == "" {
+			return TagRegistry{}, errors.New("Missing aliases in line " + lineNr)
+		}
+		tagTexts := append([]string{tagText}, strings.Fields(aliasesText)...)
+		var names []string
+		for _, t := range tagTexts {
+			tag, tErr := klog.NewTagFromString(strings.TrimSpace(t))
+			if tErr != nil {
+				return TagRegistry{}, errors.New("Invalid tag in line " + lineNr)
+			}
+			if tag.Value() != "" {
+				return TagRegistry{}, errors.New("Tag values are not allowed in line " + lineNr)
+			}
+			if declared[tag.Name()] {
+				return TagRegistry{}, errors.New("Duplicate tag in line " + lineNr)
+			}
+			declared[tag.Name()] = true
+			names = append(names, tag.Name())
+		}
+		registry.tags = append(registry.tags, TagInfo{Tag: klog.NewTagOrPanic(names[0], "")})
+		for _, alias := range names[1:] {
+			registry.aliases[alias] = names[0]
+		}
+	}
+	return registry, nil
+}
+
+func setTagProperty(info *TagInfo, line string) error {
+	key, value, hasDelimiter := strings.Cut(line, "=")
+	if !hasDelimiter {
+		return errors.New("Malformed property")
+	}
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(strings.TrimSpace(key)) {
+	case "name":
+		info.Name = value
+	case "category":
+		info.Category = value
+	case "colour", "color":
+		c, err := strconv.Atoi(value)
+		if err != nil || c < 0 || c > 255 {
+			return errors.New("Invalid colour (must be between 0 and 255)")
+		}
+		info.Colour = strconv.Itoa(c)
+	case "billable":
+		switch strings.ToLower(value) {
+		case "yes", "true":
+			info.IsBillable = true
+		case "no", "false":
+			info.IsBillable = false
+		default:
+			return errors.New("Invalid billable value (must be yes or no)")
+		}
+	default:
+		return errors.New("Unknown property")
+	}
+	return nil
+}
+
+// IsEmpty checks whether there are any tags.
+func (tr TagRegistry) IsEmpty() bool {
+	return len(tr.tags) == 0
+}
+
+// Tags returns the canonical tags, in the order of their declaration.
+func (tr TagRegistry) Tags() []klog.Tag {
+	var result []klog.Tag
+	for _, info := range tr.tags {
+		result = append(result, info.Tag)
+	}
+	return result
+}
+
+// Categories returns all categories, in the order of their first appearance.
+func (tr TagRegistry) Categories() []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, info := range tr.tags {
+		if info.Category != "" && !seen[info.Category] {
+			seen[info.Category] = true
+			result = append(result, info.Category)
+		}
+	}
+	return result
+}
+
+// Info returns the metadata of a tag, or `false` if the tag (respectively
+// its canonical form) isn’t declared itself. The tag value is disregarded.
+func (tr TagRegistry) Info(t klog.Tag) (TagInfo, bool) {
+	name := tr.foldName(t.Name())
+	for _, info := range tr.tags {
+		if info.Tag.Name() == name {
+			return info, true
+		}
+	}
+	return TagInfo{}, false
+}
+
+// NearestInfo is like Info, but if the tag isn’t declared itself, it returns
+// the metadata of its closest declared ancestor.
+func (tr TagRegistry) NearestInfo(t klog.Tag) (TagInfo, bool) {
+	folded := tr.Fold(t)
+	if info, ok := tr.Info(folded); ok {
+		return info, true
+	}
+	ancestors := folded.Ancestors()
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if info, ok := tr.Info(ancestors[i]); ok {
+			return info, true
+		}
+	}
+	return TagInfo{}, false
+}
+
+// Fold returns the canonical form of a tag. That includes hierarchical tags,
+// e.g. if `#mtg` is an alias for `#meeting`, then `#mtg/daily=1` becomes
+// `#meeting/daily=1`. Other tags are returned as is.
+func (tr TagRegistry) Fold(t klog.Tag) klog.Tag {
+	name := tr.foldName(t.Name())
+	if name == t.Name() {
+		return t
+	}
+	return klog.NewTagOrPanic(name, t.Value())
+}
+
+// FoldAll returns a new set that contains the canonical form of all tags.
+func (tr TagRegistry) FoldAll(ts klog.TagSet) klog.TagSet {
+	if len(tr.aliases) == 0 {
+		return ts
+	}
+	result := klog.NewEmptyTagSet()
+	for t := range ts {
+		result.Put(tr.Fold(t))
+	}
+	return result
+}
+
+// IsKnown checks whether a tag is declared in the registry, either itself, as
+// alias, or by means of one of its ancestors (e.g., `#acme/backend` is known
+// if `#acme` is declared). In an empty registry, all tags are known.
+func (tr TagRegistry) IsKnown(t klog.Tag) bool {
+	if tr.IsEmpty() {
+		return true
+	}
+	_, isDeclared := tr.NearestInfo(t)
+	return isDeclared
+}
+
+// UnknownTags returns all tags (without values) that appear in the summaries
+// of the records, but that are not known to the registry. They are sorted
+// alphabetically.
+func (tr TagRegistry) UnknownTags(rs ...klog.Record) []klog.Tag {
+	unknown := make(map[klog.Tag]bool)
+	for _, r := range rs {
+		for _, t := range writtenTags(r) {
+			if !tr.IsKnown(t) {
+				unknown[klog.NewTagOrPanic(t.Name(), "")] = true
+			}
+		}
+	}
+	var result []klog.Tag
+	for t := range unknown {
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name() < result[j].Name()
+	})
+	return result
+}
+
+// writtenTags returns the tags as they appear in the summaries of a record.
+// As opposed to the tag sets of the summaries, that doesn’t implicitly
+// include the ancestors of hierarchical tags.
+func writtenTags(r klog.Record) []klog.Tag {
+	lines := append([]string(nil), r.Summary().Lines()...)
+	for _, e := range r.Entries() {
+		lines = append(lines, e.Summary().Lines()...)
+	}
+	var result []klog.Tag
+	for _, l := range lines {
+		for _, m := range klog.HashTagPattern.FindAllString(l, -1) {
+			t, err := klog.NewTagFromString(m)
+			if err == nil {
+				result = append(result, t)
+			}
+		}
+	}
+	return result
+}
+
+func (tr TagRegistry) foldName(name string) string {
+	if len(tr.aliases) == 0 {
+		return name
+	}
+	segments := strings.Split(name, klog.HierarchyDelimiter)
+	for i := len(segments); i > 0; i-- {
+		prefix := strings.Join(segments[:i], klog.HierarchyDelimiter)
+		if canonical, isAlias := tr.aliases[prefix]; isAlias {
+			return strings.Join(append([]string{canonical}, segments[i:]...), klog.HierarchyDelimiter)
+		}
+	}
+	return name
+}
+
+// foldQuery returns a query where all tags are in their canonical form.
+func (tr TagRegistry) foldQuery(q TagQuery) TagQuery {
+	if len(tr.aliases) == 0 {
+		return q
+	}
+	switch x := q.(type) {
+	case *tagQueryLeaf:
+		return &tagQueryLeaf{name: tr.foldName(x.name), value: x.value, pattern: x.pattern}
+	case *tagQueryAnd:
+		return &tagQueryAnd{tr.foldQuery(x.left), tr.foldQuery(x.right)}
+	case *tagQueryOr:
+		return &tagQueryOr{tr.foldQuery(x.left), tr.foldQuery(x.right)}
+	case *tagQueryNot:
+		return &tagQueryNot{tr.foldQuery(x.operand)}
+	}
+	return q
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParsesTagRegistry(t *testing.T) {
+	registry, err := NewTagRegistryFromString(`
+; Work
+#meeting = #meetings  #MTG
+acme
+// Nested
+#acme/backend = be
+`)
+	require.Nil(t, err)
+	assert.Equal(t, []klog.Tag{
+		klog.NewTagOrPanic("meeting", ""),
+		klog.NewTagOrPanic("acme", ""),
+		klog.NewTagOrPanic("acme/backend", ""),
+	}, registry.Tags())
+	assert.Equal(t, map[string]string{
+		"meetings": "meeting",
+		"mtg":      "meeting",
+		"be":       "acme/backend",
+	}, registry.aliases)
+}
+
+func TestParsesTagRegistryWithArbitrarySpacingAroundDelimiter(t *testing.T) {
+	for _, text := range []string{"#meeting=#mtg", "#meeting  =  #mtg", "#meeting\t=#mtg"} {
+		registry, err := NewTagRegistryFromString(text)
+		require.Nil(t, err, text)
+		assert.Equal(t, []klog.Tag{klog.NewTagOrPanic("meeting", "")}, registry.Tags())
+		assert.Equal(t, map[string]string{"mtg": "meeting"}, registry.aliases)
+	}
+}
+
+func TestParsesEmptyTagRegistry(t *testing.T) {
+	registry, err := NewTagRegistryFromString("\n; Nothing here yet\n")
+	require.Nil(t, err)
+	assert.True(t, registry.IsEmpty())
+}
+
+func TestRejectsInvalidTagRegistry(t *testing.T) {
+	for _, text := range []string{
+		"#ac me",
+		"#meeting = #mtg #",
+		"#meeting = ",
+		"#meeting = #mtg=1",
+		"#meeting\n#meeting",
+		"#meeting = #mtg\n#mtg",
+		"#meeting = #meeting",
+		"  name = Foo",
+		"#acme\n  name Foo",
+		"#acme\n  colour = 256",
+		"#acme\n  colour = red",
+		"#acme\n  billable = maybe",
+		"#acme\n  foo = bar",
+	} {
+		_, err := NewTagRegistryFromString(text)
+		require.Error(t, err, text)
+	}
+}
+
+func TestFoldsAliases(t *testing.T) {
+	registry, _ := NewTagRegistryFromString("#meeting = #mtg\n#acme/backend = #be\n")
+	for _, x := range []struct {
+		tag      klog.Tag
+		expected klog.Tag
+	}{
+		{klog.NewTagOrPanic("mtg", ""), klog.NewTagOrPanic("meeting", "")},
+		{klog.NewTagOrPanic("mtg", "1"), klog.NewTagOrPanic("meeting", "1")},
+		{klog.NewTagOrPanic("mtg/daily", ""), klog.NewTagOrPanic("meeting/daily", "")},
+		{klog.NewTagOrPanic("be/api", "x"), klog.NewTagOrPanic("acme/backend/api", "x")},
+		{klog.NewTagOrPanic("meeting", ""), klog.NewTagOrPanic("meeting", "")},
+		{klog.NewTagOrPanic("mtgs", ""), klog.NewTagOrPanic("mtgs", "")},
+	} {
+		assert.Equal(t, x.expected, registry.Fold(x.tag))
+	}
+	assert.Equal(t, klog.NewTagOrPanic("mtg", ""), TagRegistry{}.Fold(klog.NewTagOrPanic("mtg", "")))
+}
+
+func TestChecksWhetherTagIsKnown(t *testing.T) {
+	registry, _ := NewTagRegistryFromString("#meeting = #mtg\n#acme\n")
+	assert.True(t, registry.IsKnown(klog.NewTagOrPanic("meeting", "")))
+	assert.True(t, registry.IsKnown(klog.NewTagOrPanic("mtg", "1")))
+	assert.True(t, registry.IsKnown(klog.NewTagOrPanic("acme/backend", "")))
+	assert.False(t, registry.IsKnown(klog.NewTagOrPanic("acm", "")))
+	assert.False(t, registry.IsKnown(klog.NewTagOrPanic("other", "")))
+	assert.True(t, NewEmptyTagRegistry().IsKnown(klog.NewTagOrPanic("other", "")))
+}
+
+func TestFilterAndAggregateApplyAliases(t *testing.T) {
+	registry, _ := NewTagRegistryFromString("#meeting = #meetings #mtg\n")
+	r := klog.NewRecord(klog.Ɀ_Date_(2020, 1, 1))
+	r.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#meeting"))
+	r.AddDuration(klog.NewDuration(2, 0), klog.Ɀ_EntrySummary_("#mtg"))
+	r.AddDuration(klog.NewDuration(4, 0), klog.Ɀ_EntrySummary_("#meetings #mtg"))
+	r.AddDuration(klog.NewDuration(8, 0), klog.Ɀ_EntrySummary_("#other"))
+
+	totals := AggregateTotalsByTags(registry, r)
+	require.Len(t, totals, 2)
+	assert.Equal(t, klog.NewTagOrPanic("meeting", ""), totals[0].Tag)
+	assert.Equal(t, klog.NewDuration(7, 0), totals[0].Total)
+	assert.Equal(t, 3, totals[0].Count)
+
+	// Note: filtering modifies the record.
+	query, _ := NewTagQueryFromString("#mtg")
+	filtered := Filter([]klog.Record{r}, FilterQry{TagQuery: query, TagRegistry: registry})
+	require.Len(t, filtered, 1)
+	assert.Equal(t, klog.NewDuration(7, 0), Total(filtered...))
+}
+
+func TestParsesTagMetadata(t *testing.T) {
+	registry, err := NewTagRegistryFromString(`
+#acme = #acme-inc
+    name = ACME Corporation
+    Category = Clients
+    colour = 214
+	billable = yes
+
+#acme/backend
+    name = Backend = API
+#meeting
+    color = 27
+    category = Internal
+#globex
+    category = Clients
+    billable = no
+`)
+	require.Nil(t, err)
+	assert.Equal(t, []TagInfo{
+		{klog.NewTagOrPanic("acme", ""), "ACME Corporation", "Clients", "214", true},
+		{klog.NewTagOrPanic("acme/backend", ""), "Backend = API", "", "", false},
+		{klog.NewTagOrPanic("meeting", ""), "", "Internal", "27", false},
+		{klog.NewTagOrPanic("globex", ""), "", "Clients", "", false},
+	}, registry.tags)
+	assert.Equal(t, []string{"Clients", "Internal"}, registry.Categories())
+}
+
+func TestLooksUpTagMetadata(t *testing.T) {
+	registry, _ := NewTagRegistryFromString("#acme = #acme-inc\n  name = ACME\n#acme/backend/api\n  name = API\n")
+
+	info, ok := registry.Info(klog.NewTagOrPanic("acme-inc", "1"))
+	require.True(t, ok)
+	assert.Equal(t, "ACME", info.Name)
+	_, ok = registry.Info(klog.NewTagOrPanic("acme/backend", ""))
+	assert.False(t, ok)
+
+	info, ok = registry.NearestInfo(klog.NewTagOrPanic("acme-inc/backend", ""))
+	require.True(t, ok)
+	assert.Equal(t, "ACME", info.Name)
+	info, ok = registry.NearestInfo(klog.NewTagOrPanic("acme/backend/api/v2", ""))
+	require.True(t, ok)
+	assert.Equal(t, "API", info.Name)
+	_, ok = registry.NearestInfo(klog.NewTagOrPanic("other", ""))
+	assert.False(t, ok)
+}
+
+func TestFindsUnknownTags(t *testing.T) {
+	registry, _ := NewTagRegistryFromString("#acme\n#meeting = #mtg\n")
+	r1 := klog.NewRecord(klog.Ɀ_Date_(2020, 1, 1))
+	r1.SetSummary(klog.Ɀ_RecordSummary_("#acme/backend #zzz=1"))
+	r1.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#mtg #Foo/bar"))
+	r2 := klog.NewRecord(klog.Ɀ_Date_(2020, 1, 2))
+	r2.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#zzz #foo/bar #abc"))
+
+	assert.Equal(t, []klog.Tag{
+		klog.NewTagOrPanic("abc", ""),
+		klog.NewTagOrPanic("foo/bar", ""),
+		klog.NewTagOrPanic("zzz", ""),
+	}, registry.UnknownTags(r1, r2))
+}
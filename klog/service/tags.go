@@ -2,7 +2,11 @@ package service
 
 import (
 	"github.com/jotaen/klog/klog"
+	"math"
+	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 )
 
 type TagStats struct {
@@ -15,23 +19,74 @@ type TagStats struct {
 	// I.e., this is *not* how often a tag appears in the record text.
 	Count int
 
-	keyForSort string
+	// Numeric contains statistics about the tag values, in case all values
+	// of the tag are numbers (e.g. `#km=12`). Otherwise, it is `nil`.
+	Numeric *NumericStats
+
+	keyForSort    string
+	hasNonNumeric bool
+}
+
+// NumericStats summarises the numeric values of a tag.
+type NumericStats struct {
+	Sum float64
+	Min float64
+	Max float64
+
+	// Count is the number of values that the stats are based on.
+	Count int
+}
+
+// Avg returns the arithmetic mean of the values.
+func (n NumericStats) Avg() float64 {
+	if n.Count == 0 {
+		return 0
+	}
+	return n.Sum / float64(n.Count)
+}
+
+func (n *NumericStats) add(x float64) {
+	if n.Count == 0 {
+		n.Min = x
+		n.Max = x
+	} else {
+		n.Min = math.Min(n.Min, x)
+		n.Max = math.Max(n.Max, x)
+	}
+	n.Sum += x
+	n.Count++
 }
 
 // AggregateTotalsByTags returns a list of tags (sorted by tag, alphanumerically)
 // that contains statistics about the tags appearing in the data.
-func AggregateTotalsByTags(rs ...klog.Record) []*TagStats {
+// Hierarchical tags are rolled up, i.e. the stats of a parent tag include all
+// entries of its descendants. The list is ordered like a tree, so that a parent
+// tag (and its values) always precede its descendants.
+// If all values of a tag are numbers, the stats also contain a numeric summary
+// of them, both for the tag as a whole and for each value. The values of tags
+// in the record summary count once per record, even if it has no entries.
+// Tag aliases are folded into their canonical tag, as per the registry.
+func AggregateTotalsByTags(registry TagRegistry, rs ...klog.Record) []*TagStats {
 	result := make(totalByTag)
 	for _, r := range rs {
+		recordTags := registry.FoldAll(r.Summary().Tags())
 		for _, e := range r.Entries() {
-			alreadyCounted := make(map[klog.Tag]bool)
-			allTags := klog.Merge(r.Summary().Tags(), e.Summary().Tags())
-			for tag := range allTags {
-				if alreadyCounted[tag] {
-					continue
-				}
+			entryTags := registry.FoldAll(e.Summary().Tags())
+			for tag := range klog.Merge(recordTags, entryTags) {
 				result.put(tag, e.Duration())
 			}
+			for tag := range entryTags {
+				if tag.Value() != "" {
+					result.putValue(tag)
+				}
+			}
+		}
+		// The values of the record tags only count once per record, no
+		// matter how many entries there are.
+		for tag := range recordTags {
+			if tag.Value() != "" {
+				result.putValue(tag)
+			}
 		}
 	}
 	return result.toSortedList()
@@ -41,6 +96,34 @@ func AggregateTotalsByTags(rs ...klog.Record) []*TagStats {
 type totalByTag map[string]map[string]*TagStats
 
 func (tbt totalByTag) put(t klog.Tag, d klog.Duration) {
+	stats := tbt.get(t)
+	stats.Total = stats.Total.Plus(d)
+	stats.Count++
+}
+
+// putValue feeds the tag’s value into the numeric stats, both of the tag itself
+// and of its value-less counterpart.
+func (tbt totalByTag) putValue(t klog.Tag) {
+	number, isNumber := parseNumericTagValue(t.Value())
+	for _, stats := range []*TagStats{
+		tbt.get(t),
+		tbt.get(klog.NewTagOrPanic(t.Name(), "")),
+	} {
+		if !isNumber {
+			stats.hasNonNumeric = true
+			stats.Numeric = nil
+		}
+		if stats.hasNonNumeric {
+			continue
+		}
+		if stats.Numeric == nil {
+			stats.Numeric = &NumericStats{}
+		}
+		stats.Numeric.add(number)
+	}
+}
+
+func (tbt totalByTag) get(t klog.Tag) *TagStats {
 	if tbt[t.Name()] == nil {
 		tbt[t.Name()] = make(map[string]*TagStats)
 	}
@@ -50,13 +133,11 @@ func (tbt totalByTag) put(t klog.Tag, d klog.Duration) {
 			Tag:        t,
 			Total:      klog.NewDuration(0, 0),
 			Count:      0,
-			keyForSort: t.Name() + "=" + t.Value(),
+			keyForSort: sortKeyForTag(t),
 		}
 	}
 
-	stats := tbt[t.Name()][t.Value()]
-	stats.Total = stats.Total.Plus(d)
-	stats.Count++
+	return tbt[t.Name()][t.Value()]
 }
 
 func (tbt totalByTag) toSortedList() []*TagStats {
@@ -71,3 +152,24 @@ func (tbt totalByTag) toSortedList() []*TagStats {
 	})
 	return result
 }
+
+// sortKeyForTag yields a key that sorts tags in tree order: first the tag name,
+// then its values, then its descendants.
+func sortKeyForTag(t klog.Tag) string {
+	return strings.ReplaceAll(t.Name(), klog.HierarchyDelimiter, "\x01") + "\x00" + t.Value()
+}
+
+var numericTagValuePattern = regexp.MustCompile(`^-?\d+([.,]\d+)?$`)
+
+// parseNumericTagValue interprets a tag value as number. Both `.` and `,` are
+// accepted as decimal separator.
+func parseNumericTagValue(value string) (float64, bool) {
+	if !numericTagValuePattern.MatchString(value) {
+		return 0, false
+	}
+	number, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	if err != nil {
+		return 0, false
+	}
+	return number, true
+}
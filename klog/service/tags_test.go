@@ -14,7 +14,7 @@ func TestAggregateTotalTimesByTag(t *testing.T) {
 	r.AddDuration(klog.NewDuration(3, 0), klog.Ɀ_EntrySummary_("#foo"))
 	r.AddDuration(klog.NewDuration(0, 30), klog.Ɀ_EntrySummary_("#test"))
 
-	totals := AggregateTotalsByTags(r)
+	totals := AggregateTotalsByTags(NewEmptyTagRegistry(), r)
 	require.Len(t, totals, 3)
 
 	i := 0
@@ -39,7 +39,7 @@ func TestAggregateTotalIgnoresRedundantTags(t *testing.T) {
 	r.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#foo=1 #foo"))
 	r.AddDuration(klog.NewDuration(3, 0), klog.Ɀ_EntrySummary_("#foo=2 #foo=1 #foo"))
 
-	totals := AggregateTotalsByTags(r)
+	totals := AggregateTotalsByTags(NewEmptyTagRegistry(), r)
 	require.Len(t, totals, 3)
 
 	i := 0
@@ -68,7 +68,7 @@ func TestAggregateTotalTimesByTagSortsAlphabetically(t *testing.T) {
 	r2.AddDuration(klog.NewDuration(0, 30), klog.Ɀ_EntrySummary_("#ccc=1"))
 	r2.AddDuration(klog.NewDuration(0, 30), klog.Ɀ_EntrySummary_("#ccc=2"))
 
-	totals := AggregateTotalsByTags(r1, r2)
+	totals := AggregateTotalsByTags(NewEmptyTagRegistry(), r1, r2)
 	require.Len(t, totals, 6)
 
 	i := 0
@@ -84,3 +84,119 @@ func TestAggregateTotalTimesByTagSortsAlphabetically(t *testing.T) {
 	i += 1
 	assert.Equal(t, klog.NewTagOrPanic("ddd", ""), totals[i].Tag)
 }
+
+func TestAggregateTotalsOfHierarchicalTagsAsTree(t *testing.T) {
+	r := klog.NewRecord(klog.Ɀ_Date_(2020, 1, 1))
+	r.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#acme/backend"))
+	r.AddDuration(klog.NewDuration(2, 0), klog.Ɀ_EntrySummary_("#acme/frontend #acme-x"))
+	r.AddDuration(klog.NewDuration(4, 0), klog.Ɀ_EntrySummary_("#acme=internal"))
+	r.AddDuration(klog.NewDuration(8, 0), klog.Ɀ_EntrySummary_("#acme/backend/api #acme/backend"))
+
+	totals := AggregateTotalsByTags(NewEmptyTagRegistry(), r)
+	var tags []string
+	for _, t := range totals {
+		tags = append(tags, t.Tag.ToString())
+	}
+	assert.Equal(t, []string{
+		"#acme", "#acme=internal", "#acme/backend", "#acme/backend/api", "#acme/frontend", "#acme-x",
+	}, tags)
+
+	assert.Equal(t, klog.NewDuration(15, 0), totals[0].Total)
+	assert.Equal(t, 4, totals[0].Count)
+	assert.Equal(t, klog.NewDuration(9, 0), totals[2].Total)
+	assert.Equal(t, 2, totals[2].Count)
+	assert.Equal(t, klog.NewDuration(8, 0), totals[3].Total)
+}
+
+func TestAggregateNumericTagValues(t *testing.T) {
+	r := klog.NewRecord(klog.Ɀ_Date_(2020, 1, 1))
+	r.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#km=12 #ticket=481"))
+	r.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#km=\"3.5\" #ticket=PRJ-1"))
+	r.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#km=-2 #km=12"))
+	r.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#km"))
+
+	totals := AggregateTotalsByTags(NewEmptyTagRegistry(), r)
+	require.Len(t, totals, 7)
+
+	km := totals[0]
+	assert.Equal(t, klog.NewTagOrPanic("km", ""), km.Tag)
+	require.NotNil(t, km.Numeric)
+	assert.Equal(t, 25.5, km.Numeric.Sum)
+	assert.Equal(t, -2.0, km.Numeric.Min)
+	assert.Equal(t, 12.0, km.Numeric.Max)
+	assert.Equal(t, 4, km.Numeric.Count)
+	assert.Equal(t, 6.375, km.Numeric.Avg())
+
+	km12 := totals[2]
+	assert.Equal(t, klog.NewTagOrPanic("km", "12"), km12.Tag)
+	require.NotNil(t, km12.Numeric)
+	assert.Equal(t, 24.0, km12.Numeric.Sum)
+	assert.Equal(t, 2, km12.Numeric.Count)
+
+	ticket := totals[4]
+	assert.Equal(t, klog.NewTagOrPanic("ticket", ""), ticket.Tag)
+	assert.Nil(t, ticket.Numeric)
+	assert.NotNil(t, totals[5].Numeric)
+	assert.Nil(t, totals[6].Numeric)
+}
+
+func TestAggregateNumericValuesOfRecordTagsOncePerRecord(t *testing.T) {
+	r1 := klog.NewRecord(klog.Ɀ_Date_(2020, 1, 1))
+	r1.SetSummary(klog.Ɀ_RecordSummary_("#km=12"))
+	r1.AddDuration(klog.NewDuration(1, 0), nil)
+	r1.AddDuration(klog.NewDuration(2, 0), nil)
+	r1.AddDuration(klog.NewDuration(3, 0), klog.Ɀ_EntrySummary_("#km=5"))
+	r2 := klog.NewRecord(klog.Ɀ_Date_(2020, 1, 2))
+	r2.SetSummary(klog.Ɀ_RecordSummary_("#km=20"))
+
+	totals := AggregateTotalsByTags(NewEmptyTagRegistry(), r1, r2)
+	require.Len(t, totals, 4)
+
+	km := totals[0]
+	assert.Equal(t, klog.NewTagOrPanic("km", ""), km.Tag)
+	assert.Equal(t, klog.NewDuration(6, 0), km.Total)
+	assert.Equal(t, 3, km.Count)
+	require.NotNil(t, km.Numeric)
+	assert.Equal(t, 37.0, km.Numeric.Sum)
+	assert.Equal(t, 3, km.Numeric.Count)
+
+	km12 := totals[1]
+	assert.Equal(t, klog.NewTagOrPanic("km", "12"), km12.Tag)
+	assert.Equal(t, 3, km12.Count)
+	assert.Equal(t, 12.0, km12.Numeric.Sum)
+	assert.Equal(t, 1, km12.Numeric.Count)
+
+	// The record without entries still counts with its value.
+	km20 := totals[2]
+	assert.Equal(t, klog.NewTagOrPanic("km", "20"), km20.Tag)
+	assert.Equal(t, klog.NewDuration(0, 0), km20.Total)
+	assert.Equal(t, 0, km20.Count)
+	require.NotNil(t, km20.Numeric)
+	assert.Equal(t, 20.0, km20.Numeric.Sum)
+	assert.Equal(t, 1, km20.Numeric.Count)
+
+	km5 := totals[3]
+	assert.Equal(t, klog.NewTagOrPanic("km", "5"), km5.Tag)
+	assert.Equal(t, 5.0, km5.Numeric.Sum)
+}
+
+func TestParseNumericTagValues(t *testing.T) {
+	for _, x := range []struct {
+		value    string
+		expected float64
+	}{
+		{"0", 0},
+		{"12", 12},
+		{"-3", -3},
+		{"1.25", 1.25},
+		{"1,5", 1.5},
+	} {
+		number, ok := parseNumericTagValue(x.value)
+		assert.True(t, ok, x.value)
+		assert.Equal(t, x.expected, number)
+	}
+	for _, value := range []string{"", "abc", "1e5", "Inf", "NaN", "1.", ".5", "1.2.3", "PRJ-1"} {
+		_, ok := parseNumericTagValue(value)
+		assert.False(t, ok, value)
+	}
+}
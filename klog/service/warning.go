@@ -3,13 +3,16 @@ package service
 import (
 	"github.com/jotaen/klog/klog"
 	"sort"
+	"strings"
 	gotime "time"
 )
 
 // Warning contains information for helping locate an issue.
 type Warning struct {
-	date   klog.Date
-	origin checker
+	date    klog.Date
+	record  klog.Record
+	origin  rule
+	details string
 }
 
 // Date is the date of the record that the warning refers to.
@@ -17,14 +20,40 @@ func (w Warning) Date() klog.Date {
 	return w.date
 }
 
+// Record is the record that the warning refers to. It is nil if the warning
+// is about the absence of a record.
+func (w Warning) Record() klog.Record {
+	return w.record
+}
+
 // Warning is a short description of the problem.
 func (w Warning) Warning() string {
+	if w.details != "" {
+		return w.origin.Message() + ": " + w.details
+	}
 	return w.origin.Message()
 }
 
+// Rule is the ID of the check that produced the warning, e.g. `future-entry`.
+func (w Warning) Rule() string {
+	return w.origin.Rule()
+}
+
+type rule interface {
+	Message() string
+	Rule() string
+}
+
 type checker interface {
+	rule
 	Warn(klog.Record) klog.Date
-	Message() string
+}
+
+// detailedChecker is a checker that can tell the specifics of the issue that
+// its most recent warning was about, e.g. the offending tags.
+type detailedChecker interface {
+	checker
+	details() string
 }
 
 // CheckForWarnings checks records for potential logical issues in the data. For every
@@ -32,7 +61,9 @@ type checker interface {
 // strict validation, but the main purpose is to help users spot accidental mistakes users
 // might have made. The checks are limited to record-level, because otherwise it would
 // need to make assumptions on how records are organised within or across files.
-func CheckForWarnings(onWarn func(Warning), reference gotime.Time, rs []klog.Record) {
+// Unknown tags are only checked for if the tag registry isn’t empty, and the
+// working-time compliance checks only if their respective limits are set.
+func CheckForWarnings(onWarn func(Warning), reference gotime.Time, registry TagRegistry, limits WorkTimeLimits, rs []klog.Record) {
 	now := NewDateTimeFromGo(reference)
 	sortedRs := Sort(rs, false)
 	checkers := []checker{
@@ -41,14 +72,23 @@ func CheckForWarnings(onWarn func(Warning), reference gotime.Time, rs []klog.Rec
 		&overlappingTimeRangesChecker{},
 		&moreThan24HoursChecker{},
 	}
+	if !registry.IsEmpty() {
+		checkers = append(checkers, &unknownTagsChecker{registry: registry})
+	}
+	checkers = append(checkers, limits.checkers(rs)...)
 	for _, r := range sortedRs {
 		for _, c := range checkers {
 			d := c.Warn(r)
 			if d != nil {
-				onWarn(Warning{
+				w := Warning{
 					date:   d,
+					record: r,
 					origin: c,
-				})
+				}
+				if dc, ok := c.(detailedChecker); ok {
+					w.details = dc.details()
+				}
+				onWarn(w)
 			}
 		}
 	}
@@ -83,6 +123,10 @@ func (c *unclosedOpenRangeChecker) Message() string {
 	return "Unclosed open range"
 }
 
+func (c *unclosedOpenRangeChecker) Rule() string {
+	return RULE_UNCLOSED_OPEN_RANGE
+}
+
 type futureEntriesChecker struct {
 	now         DateTime
 	gracePeriod klog.Duration
@@ -136,6 +180,10 @@ func (c *futureEntriesChecker) Message() string {
 	return "Entry in the future"
 }
 
+func (c *futureEntriesChecker) Rule() string {
+	return RULE_FUTURE_ENTRY
+}
+
 type overlappingTimeRangesChecker struct{}
 
 // Warn returns warnings if there are entries with overlapping time ranges.
@@ -187,6 +235,10 @@ func (c *overlappingTimeRangesChecker) Message() string {
 	return "Overlapping time ranges"
 }
 
+func (c *overlappingTimeRangesChecker) Rule() string {
+	return RULE_OVERLAPPING_RANGES
+}
+
 type moreThan24HoursChecker struct{}
 
 // Warn returns warnings if there are records with a total time of more than 24h.
@@ -200,3 +252,45 @@ func (c *moreThan24HoursChecker) Warn(record klog.Record) klog.Date {
 func (c *moreThan24HoursChecker) Message() string {
 	return "Total time exceeds 24 hours"
 }
+
+func (c *moreThan24HoursChecker) Rule() string {
+	return RULE_MORE_THAN_24H
+}
+
+type unknownTagsChecker struct {
+	registry TagRegistry
+
+	// unknownTags are the names of the unknown tags of the most recently
+	// checked record, in order of appearance.
+	unknownTags []string
+}
+
+// Warn returns warnings if there are tags in the record or entry summaries
+// that are not declared in the tag registry.
+func (c *unknownTagsChecker) Warn(record klog.Record) klog.Date {
+	c.unknownTags = nil
+	seen := make(map[string]bool)
+	for _, t := range writtenTags(record) {
+		name := klog.NewTagOrPanic(t.Name(), "").ToString()
+		if !c.registry.IsKnown(t) && !seen[name] {
+			seen[name] = true
+			c.unknownTags = append(c.unknownTags, name)
+		}
+	}
+	if len(c.unknownTags) > 0 {
+		return record.Date()
+	}
+	return nil
+}
+
+func (c *unknownTagsChecker) details() string {
+	return strings.Join(c.unknownTags, ", ")
+}
+
+func (c *unknownTagsChecker) Message() string {
+	return "Unknown tag"
+}
+
+func (c *unknownTagsChecker) Rule() string {
+	return RULE_UNKNOWN_TAG
+}
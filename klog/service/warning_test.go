@@ -18,10 +18,14 @@ func countWarningsOfKind(c checker, ws []Warning) int {
 }
 
 func checkForWarningsWithCollect(reference gotime.Time, rs []klog.Record) []Warning {
+	return checkForWarningsWithRegistry(reference, NewEmptyTagRegistry(), rs)
+}
+
+func checkForWarningsWithRegistry(reference gotime.Time, registry TagRegistry, rs []klog.Record) []Warning {
 	var ws []Warning
 	CheckForWarnings(func(w Warning) {
 		ws = append(ws, w)
-	}, reference, rs)
+	}, reference, registry, WorkTimeLimits{}, rs)
 	return ws
 }
 
@@ -246,3 +250,23 @@ func TestOverlappingTimeRanges(t *testing.T) {
 	ws := checkForWarningsWithCollect(timestamp, rs)
 	assert.Equal(t, len(rs), countWarningsOfKind(&overlappingTimeRangesChecker{}, ws))
 }
+
+func TestWarnForUnknownTags(t *testing.T) {
+	registry, _ := NewTagRegistryFromString("#acme\n#meeting = #mtg\n")
+	r1 := klog.NewRecord(klog.Ɀ_Date_(2000, 1, 1))
+	r1.SetSummary(klog.Ɀ_RecordSummary_("#acme/backend, #MTG=1"))
+	r1.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#meeting"))
+	r2 := klog.NewRecord(klog.Ɀ_Date_(2000, 1, 2))
+	r2.AddDuration(klog.NewDuration(1, 0), klog.Ɀ_EntrySummary_("#acme #other #Other=1 #misc"))
+	reference := gotime.Date(2000, 3, 5, 12, 00, 0, 0, gotime.Local)
+
+	ws := checkForWarningsWithRegistry(reference, registry, []klog.Record{r1, r2})
+	assert.Len(t, ws, 1)
+	assert.True(t, klog.Ɀ_Date_(2000, 1, 2).IsEqualTo(ws[0].Date()))
+	assert.Equal(t, "Unknown tag: #other, #misc", ws[0].Warning())
+	assert.Equal(t, RULE_UNKNOWN_TAG, ws[0].Rule())
+	assert.Equal(t, r2, ws[0].Record())
+
+	// Without registry, there is no check.
+	assert.Len(t, checkForWarningsWithCollect(reference, []klog.Record{r1, r2}), 0)
+}
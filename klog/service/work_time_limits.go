@@ -0,0 +1,303 @@
+package service
+
+import (
+	"errors"
+	"github.com/jotaen/klog/klog"
+	"sort"
+	"strings"
+)
+
+// WorkTimeLimits are the thresholds for the working-time compliance checks.
+// Each check is only performed if its respective threshold is set.
+type WorkTimeLimits struct {
+	// MaxDaily is the maximum work time per record.
+	MaxDaily klog.Duration
+
+	// MaxWeekly is the maximum work time per calendar week.
+	MaxWeekly klog.Duration
+
+	// MinRest is the minimum time between the last entry of a day and
+	// the first entry of the next day.
+	MinRest klog.Duration
+
+	// RequiredBreaks are the breaks that are required from a certain work time on.
+	RequiredBreaks BreakRequirements
+}
+
+// BreakRequirement specifies the minimum break time for a work time of more
+// than a certain duration.
+type BreakRequirement struct {
+	WorkTime klog.Duration
+	Break    klog.Duration
+}
+
+// BreakRequirements are break requirements, ordered by work time (ascending).
+type BreakRequirements []BreakRequirement
+
+// NewBreakRequirementsFromString parses a comma-separated list of work times
+// and the respective break times. Example: `6h: 30m, 9h: 45m`.
+func NewBreakRequirementsFromString(value string) (BreakRequirements, error) {
+	var requirements BreakRequirements
+	if strings.TrimSpace(value) == "" {
+		return nil, errors.New("EMPTY_BREAK_REQUIREMENTS")
+	}
+	for _, item := range strings.Split(value, ",") {
+		parts := strings.Split(item, ":")
+		if len(parts) != 2 {
+			return nil, errors.New("MALFORMED_BREAK_REQUIREMENTS")
+		}
+		workTime, wErr := klog.NewDurationFromString(strings.TrimSpace(parts[0]))
+		if wErr != nil {
+			return nil, wErr
+		}
+		breakTime, bErr := klog.NewDurationFromString(strings.TrimSpace(parts[1]))
+		if bErr != nil {
+			return nil, bErr
+		}
+		if workTime.InMinutes() <= 0 || breakTime.InMinutes() <= 0 {
+			return nil, errors.New("NON_POSITIVE_DURATION")
+		}
+		for _, r := range requirements {
+			if r.WorkTime.InMinutes() == workTime.InMinutes() {
+				return nil, errors.New("DUPLICATE_WORK_TIME")
+			}
+		}
+		requirements = append(requirements, BreakRequirement{workTime, breakTime})
+	}
+	sort.Slice(requirements, func(i, j int) bool {
+		return requirements[i].WorkTime.InMinutes() < requirements[j].WorkTime.InMinutes()
+	})
+	return requirements, nil
+}
+
+// ToString serialises the break requirements, e.g. `6h: 30m, 9h: 45m`.
+func (bs BreakRequirements) ToString() string {
+	var items []string
+	for _, b := range bs {
+		items = append(items, b.WorkTime.ToString()+": "+b.Break.ToString())
+	}
+	return strings.Join(items, ", ")
+}
+
+// requiredBreak returns the minimum break time for the given work time. It
+// returns nil if no break is required.
+func (bs BreakRequirements) requiredBreak(workTime klog.Duration) klog.Duration {
+	var result klog.Duration
+	for _, b := range bs {
+		if workTime.InMinutes() > b.WorkTime.InMinutes() {
+			result = b.Break
+		}
+	}
+	return result
+}
+
+// PerRecord returns the limits without the ones whose checks span multiple
+// records, i.e. the minimum rest period and the maximum weekly work time. These
+// checks are only meaningful for the complete set of records, so they must be
+// left out if the records might have been filtered.
+func (l WorkTimeLimits) PerRecord() WorkTimeLimits {
+	l.MinRest = nil
+	l.MaxWeekly = nil
+	return l
+}
+
+func (l WorkTimeLimits) checkers(rs []klog.Record) []checker {
+	var checkers []checker
+	if l.MaxDaily != nil {
+		checkers = append(checkers, &maxDailyWorkChecker{max: l.MaxDaily})
+	}
+	if len(l.RequiredBreaks) > 0 {
+		checkers = append(checkers, &requiredBreaksChecker{requirements: l.RequiredBreaks})
+	}
+	if l.MinRest != nil {
+		checkers = append(checkers, &minRestChecker{min: l.MinRest})
+	}
+	if l.MaxWeekly != nil {
+		checkers = append(checkers, newMaxWeeklyWorkChecker(l.MaxWeekly, rs))
+	}
+	return checkers
+}
+
+type maxDailyWorkChecker struct {
+	max klog.Duration
+}
+
+// Warn returns warnings if the total time of a record exceeds the maximum.
+func (c *maxDailyWorkChecker) Warn(record klog.Record) klog.Date {
+	if Total(record).InMinutes() > c.max.InMinutes() {
+		return record.Date()
+	}
+	return nil
+}
+
+func (c *maxDailyWorkChecker) Message() string {
+	return "Daily work time exceeds the maximum"
+}
+
+func (c *maxDailyWorkChecker) Rule() string {
+	return RULE_MAX_DAILY_WORK
+}
+
+type requiredBreaksChecker struct {
+	requirements BreakRequirements
+}
+
+// Warn returns warnings if the breaks of a record are shorter than required
+// for its total time. The breaks are the gaps between the time ranges, plus
+// all negative durations. Records without time ranges are not checked, since
+// their breaks can’t be determined.
+func (c *requiredBreaksChecker) Warn(record klog.Record) klog.Date {
+	ranges := closedRanges(record)
+	if len(ranges) == 0 {
+		return nil
+	}
+	required := c.requirements.requiredBreak(Total(record))
+	if required == nil {
+		return nil
+	}
+	breakTime := 0
+	for _, e := range record.Entries() {
+		klog.Unbox(&e,
+			func(klog.Range) any { return nil },
+			func(d klog.Duration) any {
+				if d.InMinutes() < 0 {
+					breakTime -= d.InMinutes()
+				}
+				return nil
+			},
+			func(klog.OpenRange) any { return nil },
+		)
+	}
+	latestEnd := ranges[0].End().MidnightOffset().InMinutes()
+	for _, r := range ranges[1:] {
+		start := r.Start().MidnightOffset().InMinutes()
+		if start > latestEnd {
+			breakTime += start - latestEnd
+		}
+		if end := r.End().MidnightOffset().InMinutes(); end > latestEnd {
+			latestEnd = end
+		}
+	}
+	if breakTime < required.InMinutes() {
+		return record.Date()
+	}
+	return nil
+}
+
+func (c *requiredBreaksChecker) Message() string {
+	return "Breaks are shorter than required"
+}
+
+func (c *requiredBreaksChecker) Rule() string {
+	return RULE_REQUIRED_BREAKS
+}
+
+type minRestChecker struct {
+	min klog.Duration
+
+	// Records are checked in descending order. currentDate is the date of
+	// the most recently checked record, and currentStart is the earliest start
+	// time of all records at that date (or -1 if there is none). nextDate and
+	// nextStart are the same for the preceding date, i.e. the next day.
+	currentDate  klog.Date
+	currentStart int
+	nextDate     klog.Date
+	nextStart    int
+}
+
+// Warn returns warnings if the time between the last time range of a record
+// and the first time range of the record(s) at the next day is shorter than
+// the minimum rest period. There might be multiple records at the same date,
+// e.g. from different files.
+func (c *minRestChecker) Warn(record klog.Record) klog.Date {
+	if c.currentDate == nil || !c.currentDate.IsEqualTo(record.Date()) {
+		c.nextDate, c.nextStart = c.currentDate, c.currentStart
+		c.currentDate, c.currentStart = record.Date(), -1
+	}
+	ranges := closedRanges(record)
+	if len(ranges) == 0 {
+		return nil
+	}
+	if start := ranges[0].Start().MidnightOffset().InMinutes(); c.currentStart == -1 || start < c.currentStart {
+		c.currentStart = start
+	}
+	if c.nextDate == nil || c.nextStart == -1 || !c.nextDate.IsEqualTo(record.Date().PlusDays(1)) {
+		return nil
+	}
+	latestEnd := ranges[0].End().MidnightOffset().InMinutes()
+	for _, r := range ranges {
+		if end := r.End().MidnightOffset().InMinutes(); end > latestEnd {
+			latestEnd = end
+		}
+	}
+	if c.nextStart+24*60-latestEnd < c.min.InMinutes() {
+		return record.Date()
+	}
+	return nil
+}
+
+func (c *minRestChecker) Message() string {
+	return "Rest period before the next day is too short"
+}
+
+func (c *minRestChecker) Rule() string {
+	return RULE_MIN_REST
+}
+
+type maxWeeklyWorkChecker struct {
+	// excessDates are the dates at which the maximum is exceeded, one per week.
+	excessDates map[string]bool
+}
+
+func newMaxWeeklyWorkChecker(max klog.Duration, rs []klog.Record) *maxWeeklyWorkChecker {
+	c := &maxWeeklyWorkChecker{excessDates: make(map[string]bool)}
+	totals := make(map[[2]int]int)
+	for _, r := range Sort(rs, true) {
+		year, week := r.Date().WeekNumber()
+		key := [2]int{year, week}
+		previousTotal := totals[key]
+		totals[key] += Total(r).InMinutes()
+		if previousTotal <= max.InMinutes() && totals[key] > max.InMinutes() {
+			c.excessDates[r.Date().ToStringWithFormat(klog.DefaultDateFormat())] = true
+		}
+	}
+	return c
+}
+
+// Warn returns a warning if the total time of a calendar week exceeds the
+// maximum. The warning refers to the record at which the maximum is exceeded.
+func (c *maxWeeklyWorkChecker) Warn(record klog.Record) klog.Date {
+	key := record.Date().ToStringWithFormat(klog.DefaultDateFormat())
+	if c.excessDates[key] {
+		delete(c.excessDates, key) // So that the date is only reported once.
+		return record.Date()
+	}
+	return nil
+}
+
+func (c *maxWeeklyWorkChecker) Message() string {
+	return "Weekly work time exceeds the maximum"
+}
+
+func (c *maxWeeklyWorkChecker) Rule() string {
+	return RULE_MAX_WEEKLY_WORK
+}
+
+// closedRanges returns the time ranges of a record, ordered by start time.
+func closedRanges(record klog.Record) []klog.Range {
+	var ranges []klog.Range
+	for _, e := range record.Entries() {
+		klog.Unbox(&e,
+			func(r klog.Range) any {
+				ranges = append(ranges, r)
+				return nil
+			},
+			func(klog.Duration) any { return nil },
+			func(klog.OpenRange) any { return nil },
+		)
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Start().MidnightOffset().InMinutes() < ranges[j].Start().MidnightOffset().InMinutes()
+	})
+	return ranges
+}
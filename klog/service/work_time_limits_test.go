@@ -0,0 +1,191 @@
+package service
+
+import (
+	"github.com/jotaen/klog/klog"
+	"github.com/jotaen/klog/klog/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	gotime "time"
+)
+
+func checkWorkTimeLimits(limits WorkTimeLimits, text string) []Warning {
+	rs, _, errs := parser.NewSerialParser().Parse(text)
+	if errs != nil {
+		panic("Invalid records")
+	}
+	var ws []Warning
+	CheckForWarnings(func(w Warning) {
+		ws = append(ws, w)
+	}, gotime.Date(2030, 1, 1, 0, 0, 0, 0, gotime.Local), NewEmptyTagRegistry(), limits, rs)
+	return ws
+}
+
+func TestParsesBreakRequirements(t *testing.T) {
+	bs, err := NewBreakRequirementsFromString("9h: 45m, 6h:30m")
+	require.Nil(t, err)
+	assert.Equal(t, "6h: 30m, 9h: 45m", bs.ToString())
+	assert.Nil(t, bs.requiredBreak(klog.NewDuration(6, 0)))
+	assert.Equal(t, klog.NewDuration(0, 30), bs.requiredBreak(klog.NewDuration(6, 1)))
+	assert.Equal(t, klog.NewDuration(0, 45), bs.requiredBreak(klog.NewDuration(10, 0)))
+}
+
+func TestRejectsInvalidBreakRequirements(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"6h",
+		"6h 30m",
+		"6h: asdf",
+		"6h: 30m, 6h: 45m",
+		"6h: -30m",
+		"6h: 30m,",
+	} {
+		_, err := NewBreakRequirementsFromString(text)
+		require.Error(t, err, text)
+	}
+}
+
+func TestNoComplianceChecksWithoutLimits(t *testing.T) {
+	ws := checkWorkTimeLimits(WorkTimeLimits{}, `
+2020-01-01
+	6:00-22:00
+
+2020-01-02
+	1:00-23:00
+`)
+	assert.Len(t, ws, 0)
+}
+
+func TestWarnForExceededDailyWorkTime(t *testing.T) {
+	ws := checkWorkTimeLimits(WorkTimeLimits{MaxDaily: klog.NewDuration(10, 0)}, `
+2020-01-01
+	8:00-18:00
+
+2020-01-02
+	8:00-18:01
+`)
+	require.Len(t, ws, 1)
+	assert.Equal(t, klog.Ɀ_Date_(2020, 1, 2), ws[0].Date())
+	assert.Equal(t, RULE_MAX_DAILY_WORK, ws[0].Rule())
+}
+
+func TestWarnForInsufficientBreaks(t *testing.T) {
+	ws := checkWorkTimeLimits(WorkTimeLimits{RequiredBreaks: BreakRequirements{
+		{klog.NewDuration(6, 0), klog.NewDuration(0, 30)},
+		{klog.NewDuration(9, 0), klog.NewDuration(0, 45)},
+	}}, `
+2020-01-01
+	8:00-14:00
+
+2020-01-02
+	8:00-12:00
+	12:30-15:00
+
+2020-01-03
+	8:00-12:00
+	12:20-15:00
+
+2020-01-04
+	8:00-12:00
+	12:30-18:00
+
+2020-01-05
+	8:00-12:00
+	-45m
+	12:00-18:00
+
+2020-01-06
+	10h
+`)
+	require.Len(t, ws, 2)
+	assert.Equal(t, klog.Ɀ_Date_(2020, 1, 4), ws[0].Date())
+	assert.Equal(t, klog.Ɀ_Date_(2020, 1, 3), ws[1].Date())
+	assert.Equal(t, RULE_REQUIRED_BREAKS, ws[0].Rule())
+}
+
+func TestWarnForInsufficientRestPeriod(t *testing.T) {
+	ws := checkWorkTimeLimits(WorkTimeLimits{MinRest: klog.NewDuration(11, 0)}, `
+2020-01-01
+	8:00-20:00
+
+2020-01-02
+	7:00-22:00
+
+2020-01-03
+	8:00-17:00
+
+2020-01-05
+	8:00-23:30
+
+2020-01-06
+	<23:00-1:00
+`)
+	require.Len(t, ws, 2)
+	assert.Equal(t, klog.Ɀ_Date_(2020, 1, 5), ws[0].Date())
+	assert.Equal(t, klog.Ɀ_Date_(2020, 1, 2), ws[1].Date())
+	assert.Equal(t, RULE_MIN_REST, ws[0].Rule())
+}
+
+func TestWarnForInsufficientRestPeriodWithRecordsAtSameDate(t *testing.T) {
+	ws := checkWorkTimeLimits(WorkTimeLimits{MinRest: klog.NewDuration(11, 0)}, `
+2020-01-01
+	8:00-12:00
+
+2020-01-01
+	13:00-22:00
+
+2020-01-02
+	10:00-17:00
+
+2020-01-02
+	7:00-9:00
+
+2020-01-02
+	2h
+
+2020-01-03
+	8:00-12:00
+`)
+	require.Len(t, ws, 1)
+	assert.Equal(t, klog.Ɀ_Date_(2020, 1, 1), ws[0].Date())
+	assert.Equal(t, klog.NewDuration(9, 0), Total(ws[0].Record()))
+}
+
+func TestOmitsChecksAcrossRecordsPerRecord(t *testing.T) {
+	limits := WorkTimeLimits{
+		MaxDaily:  klog.NewDuration(10, 0),
+		MaxWeekly: klog.NewDuration(20, 0),
+		MinRest:   klog.NewDuration(11, 0),
+	}.PerRecord()
+	assert.Equal(t, klog.NewDuration(10, 0), limits.MaxDaily)
+	assert.Nil(t, limits.MaxWeekly)
+	assert.Nil(t, limits.MinRest)
+}
+
+func TestWarnForExceededWeeklyWorkTime(t *testing.T) {
+	ws := checkWorkTimeLimits(WorkTimeLimits{MaxWeekly: klog.NewDuration(20, 0)}, `
+2020-01-06
+	8h
+
+2020-01-07
+	8h
+
+2020-01-08
+	4h
+
+2020-01-13
+	10h
+
+2020-01-14
+	10h
+
+2020-01-15
+	1h
+
+2020-01-16
+	1h
+`)
+	require.Len(t, ws, 1)
+	assert.Equal(t, klog.Ɀ_Date_(2020, 1, 15), ws[0].Date())
+	assert.Equal(t, RULE_MAX_WEEKLY_WORK, ws[0].Rule())
+}
@@ -195,3 +195,10 @@ func TestRecognisesAllTags(t *testing.T) {
 	entrySummary, _ := NewEntrySummary("Hello #world, I feel #great #TODAY")
 	assert.Equal(t, entrySummary.Tags().ToStrings(), []string{"#great", "#today", "#world"})
 }
+
+func TestRecognisesHierarchicalTags(t *testing.T) {
+	summary, _ := NewEntrySummary("Fix #acme/backend/api bug, see #Acme/ticket=42 (#foo/)")
+	assert.Equal(t, []string{
+		"#acme", "#acme/backend", "#acme/backend/api", "#acme/ticket", "#acme/ticket=42", "#foo",
+	}, summary.Tags().ToStrings())
+}
@@ -7,7 +7,7 @@ import (
 	"strings"
 )
 
-var HashTagPattern = regexp.MustCompile(`#([\p{L}\d_-]+)(=(("[^"]*")|('[^']*')|([\p{L}\d_-]*)))?`)
+var HashTagPattern = regexp.MustCompile(`#([\p{L}\d_-]+(?:/[\p{L}\d_-]+)*)(=(("[^"]*")|('[^']*')|([\p{L}\d_-]*)))?`)
 var unquotedValuePattern = regexp.MustCompile(`^[\p{L}\d_-]+$`)
 
 type Tag struct {
@@ -60,6 +60,21 @@ func (t Tag) Value() string {
 	return t.value
 }
 
+// HierarchyDelimiter separates the segments of hierarchical tag names,
+// e.g. `#acme/backend`.
+const HierarchyDelimiter = "/"
+
+// Ancestors returns the (value-less) parent tags of a hierarchical tag,
+// starting with the top-most one. E.g., for `#a/b/c` that is `#a` and `#a/b`.
+func (t Tag) Ancestors() []Tag {
+	var result []Tag
+	segments := strings.Split(t.name, HierarchyDelimiter)
+	for i := 1; i < len(segments); i++ {
+		result = append(result, NewTagOrPanic(strings.Join(segments[:i], HierarchyDelimiter), ""))
+	}
+	return result
+}
+
 func (t Tag) ToString() string {
 	result := "#" + t.name
 	if t.value != "" {
@@ -83,11 +98,18 @@ func NewEmptyTagSet() TagSet {
 	return make(map[Tag]bool)
 }
 
+// Put adds a tag to the set. That implicitly includes the value-less form of
+// the tag, as well as all its ancestors in case of a hierarchical tag.
 func (ts TagSet) Put(tag Tag) {
 	ts[tag] = true
 	ts[NewTagOrPanic(tag.Name(), "")] = true
+	for _, a := range tag.Ancestors() {
+		ts[a] = true
+	}
 }
 
+// Contains checks whether the tag is in the set. A parent tag (e.g. `#a`)
+// is contained if any of its descendants (e.g. `#a/b`) had been put.
 func (ts TagSet) Contains(tag Tag) bool {
 	return ts[tag]
 }
@@ -18,6 +18,8 @@ func TestCreatesNewTag(t *testing.T) {
 		{"#t1a2g3", "t1a2g3"},
 		{"#---", "---"},
 		{"#___", "___"},
+		{"#acme/backend", "acme/backend"},
+		{"#A/B/C", "a/b/c"},
 	} {
 		tag, err := NewTagFromString(x.tag)
 		require.Nil(t, err)
@@ -113,6 +115,10 @@ func TestRejectsInvalidTags(t *testing.T) {
 		`#tag="foo`,
 		`#tag="foo`,
 		`#tag="`,
+		"#tag/",
+		"#/tag",
+		"#tag//tag",
+		"#tag/=foo",
 	} {
 		_, err := NewTagFromString(name)
 		require.Error(t, err)
@@ -155,3 +161,28 @@ func TestSerialiseTag(t *testing.T) {
 	tagWithValueContainingSingleQuote := NewTagOrPanic("test", `5"`)
 	assert.Equal(t, `#test='5"'`, tagWithValueContainingSingleQuote.ToString())
 }
+
+func TestHierarchicalTagAncestors(t *testing.T) {
+	assert.Nil(t, NewTagOrPanic("acme", "").Ancestors())
+	assert.Equal(t, []Tag{
+		NewTagOrPanic("acme", ""),
+	}, NewTagOrPanic("acme/backend", "1").Ancestors())
+	assert.Equal(t, []Tag{
+		NewTagOrPanic("a", ""),
+		NewTagOrPanic("a/b", ""),
+	}, NewTagOrPanic("a/b/c", "").Ancestors())
+}
+
+func TestTagSetContainsAncestorsOfHierarchicalTags(t *testing.T) {
+	ts := NewEmptyTagSet()
+	ts.Put(NewTagOrPanic("acme/backend/api", "v2"))
+	assert.True(t, ts.Contains(NewTagOrPanic("acme/backend/api", "v2")))
+	assert.True(t, ts.Contains(NewTagOrPanic("acme/backend/api", "")))
+	assert.True(t, ts.Contains(NewTagOrPanic("acme/backend", "")))
+	assert.True(t, ts.Contains(NewTagOrPanic("acme", "")))
+
+	assert.False(t, ts.Contains(NewTagOrPanic("acme/backend", "v2")))
+	assert.False(t, ts.Contains(NewTagOrPanic("acme/frontend", "")))
+	assert.False(t, ts.Contains(NewTagOrPanic("backend", "")))
+	assert.False(t, ts.Contains(NewTagOrPanic("acme/backend/api/x", "")))
+}